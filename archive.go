@@ -0,0 +1,200 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// entryFileName is the file name parse writes and archives are searched for.
+const entryFileName = "entry.json"
+
+// isArchive reports whether path names a bundle loadEntriesFromArchive can
+// read, judged by its extension.
+func isArchive(path string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// stdinPath is the -entries path that reads entries from standard input.
+const stdinPath = "-"
+
+// loadedEntry is an entry read by loadEntries together with the JSON object
+// it was decoded from, against which its signature is checked.
+type loadedEntry struct {
+	model.BenchmarkEntry
+	raw json.RawMessage
+}
+
+// newLoadedEntry decodes the entry object raw.
+func newLoadedEntry(raw []byte) (loadedEntry, error) {
+	e := loadedEntry{raw: raw}
+	err := json.Unmarshal(raw, &e.BenchmarkEntry)
+	return e, err
+}
+
+// loadEntries loads the entries stored at path: every entry.json inside a
+// zip/tar bundle, or the entries of a plain JSON file holding either a
+// single entry or an array of them. A path of "-" reads the JSON from
+// stdin.
+func loadEntries(path string) ([]loadedEntry, error) {
+	if isArchive(path) {
+		return loadEntriesFromArchive(path)
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if path == stdinPath {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	entries, err := decodeEntries(data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// decodeEntries decodes a JSON array of entries or a single entry object.
+func decodeEntries(data []byte) ([]loadedEntry, error) {
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+		var raws []json.RawMessage
+		if err := json.Unmarshal(trimmed, &raws); err != nil {
+			return nil, err
+		}
+		if len(raws) == 0 {
+			return nil, errors.New("empty entry array")
+		}
+		entries := make([]loadedEntry, len(raws))
+		for i, raw := range raws {
+			var err error
+			if entries[i], err = newLoadedEntry(raw); err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i, err)
+			}
+		}
+		return entries, nil
+	}
+	entry, err := newLoadedEntry(data)
+	if err != nil {
+		return nil, err
+	}
+	return []loadedEntry{entry}, nil
+}
+
+// loadEntriesFromArchive loads every entry.json found at any depth inside
+// a .zip, .tar, .tar.gz or .tgz bundle (e.g. a downloaded GitHub artifact
+// of a matrix build), in archive order.
+func loadEntriesFromArchive(path string) ([]loadedEntry, error) {
+	if strings.HasSuffix(strings.ToLower(path), ".zip") {
+		return loadEntriesFromZip(path)
+	}
+	return loadEntriesFromTar(path)
+}
+
+func loadEntriesFromZip(archivePath string) ([]loadedEntry, error) {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", archivePath, err)
+	}
+	defer zr.Close()
+
+	var entries []loadedEntry
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || path.Base(f.Name) != entryFileName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("opening %s:%s: %w", archivePath, f.Name, err)
+		}
+		entry, err := readEntry(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s:%s: %w", archivePath, f.Name, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no %s found in %s", entryFileName, archivePath)
+	}
+	return entries, nil
+}
+
+func loadEntriesFromTar(archivePath string) ([]loadedEntry, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", archivePath, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	lower := strings.ToLower(archivePath)
+	if strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", archivePath, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var entries []loadedEntry
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", archivePath, err)
+		}
+		if hdr.Typeflag != tar.TypeReg || path.Base(hdr.Name) != entryFileName {
+			continue
+		}
+		entry, err := readEntry(tr)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s:%s: %w", archivePath, hdr.Name, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no %s found in %s", entryFileName, archivePath)
+	}
+	return entries, nil
+}
+
+// readEntry reads and decodes one entry object from r.
+func readEntry(r io.Reader) (loadedEntry, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return loadedEntry{}, err
+	}
+	return newLoadedEntry(raw)
+}
+
+func decodeEntry(r io.Reader) (model.BenchmarkEntry, error) {
+	var entry model.BenchmarkEntry
+	err := json.NewDecoder(r).Decode(&entry)
+	return entry, err
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// matrixFiles returns the members of a downloaded matrix-build artifact:
+// entry.json files at different depths plus unrelated files.
+func matrixFiles(t *testing.T) map[string][]byte {
+	t.Helper()
+	files := map[string][]byte{
+		"bench-linux-amd64/output.log": []byte("BenchmarkFoo-8 1 1 ns/op\n"),
+	}
+	for name, goos := range map[string]string{
+		"bench-linux-amd64/entry.json":                   "linux",
+		"artifacts/bench-darwin-arm64/result/entry.json": "darwin",
+		"entry.json": "windows",
+	} {
+		data, err := json.Marshal(model.BenchmarkEntry{
+			Commit: model.Commit{SHA: "abc"},
+			Params: model.RunParams{GOOS: goos},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = data
+	}
+	return files
+}
+
+func assertMatrixEntries(t *testing.T, entries []loadedEntry) {
+	t.Helper()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		seen[e.Params.GOOS] = true
+	}
+	for _, goos := range []string{"linux", "darwin", "windows"} {
+		if !seen[goos] {
+			t.Errorf("missing entry for %s", goos)
+		}
+	}
+}
+
+func TestLoadEntriesFromArchive_Zip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bench.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	if _, err := zw.Create("bench-linux-amd64/"); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range matrixFiles(t) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	entries, err := loadEntries(path)
+	if err != nil {
+		t.Fatalf("loadEntries() error: %v", err)
+	}
+	assertMatrixEntries(t, entries)
+}
+
+func TestLoadEntriesFromArchive_TarGz(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bench.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, data := range matrixFiles(t) {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	entries, err := loadEntriesFromArchive(path)
+	if err != nil {
+		t.Fatalf("loadEntriesFromArchive() error: %v", err)
+	}
+	assertMatrixEntries(t, entries)
+}
+
+func TestLoadEntriesFromArchive_NoEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	if _, err := zw.Create("readme.txt"); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+	f.Close()
+
+	if _, err := loadEntriesFromArchive(path); err == nil {
+		t.Error("expected an error for an archive without entry.json")
+	}
+}
+
+func TestLoadEntries_ObjectArrayAndStdin(t *testing.T) {
+	dir := t.TempDir()
+	single := filepath.Join(dir, "entry.json")
+	if err := os.WriteFile(single, []byte(`{"commit":{"sha":"abc"},"params":{"goos":"linux"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	array := []byte(` [{"commit":{"sha":"abc"},"params":{"goos":"linux"}},
+		{"commit":{"sha":"abc"},"params":{"goos":"darwin"}}]`)
+	multi := filepath.Join(dir, "entries.json")
+	if err := os.WriteFile(multi, array, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := loadEntries(single)
+	if err != nil || len(entries) != 1 || entries[0].Params.GOOS != "linux" {
+		t.Errorf("single object: got %+v, %v", entries, err)
+	}
+	entries, err = loadEntries(multi)
+	if err != nil || len(entries) != 2 || entries[1].Params.GOOS != "darwin" {
+		t.Errorf("array: got %+v, %v", entries, err)
+	}
+
+	stdin, err := os.Open(multi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	saved := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() { os.Stdin = saved })
+	entries, err = loadEntries(stdinPath)
+	if err != nil || len(entries) != 2 {
+		t.Errorf("stdin: got %+v, %v", entries, err)
+	}
+
+	if _, err := decodeEntries([]byte("[]")); err == nil {
+		t.Error("expected an error for an empty array")
+	}
+}
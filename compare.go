@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/compare"
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// compare subcommand
+// ---------------------------------------------------------------------------
+
+func runCompare(args []string) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+
+	var (
+		branch    string
+		dataDir   string
+		suite     string
+		baseSHA   string
+		headSHA   string
+		format    string
+		threshold float64
+	)
+
+	fs.StringVar(&branch, "branch", "main", "Git branch name")
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.StringVar(&baseSHA, "base", "", "Commit SHA, or a unique prefix of it, to compare against (required)")
+	fs.StringVar(&headSHA, "head", "", "Commit SHA, or a unique prefix of it, compared against -base (required); every run configuration both commits share is compared")
+	fs.StringVar(&format, "format", "table", "Output format: 'table' or 'json'")
+	fs.Float64Var(&threshold, "threshold", 0, "Exit non-zero if a benchmark got worse by more than this percent, in the direction of its unit (0 = never)")
+
+	fs.Parse(args)
+
+	if baseSHA == "" || headSHA == "" {
+		log.Fatal("Error: -base and -head are required")
+	}
+	if format != "table" && format != "json" {
+		log.Fatalf("Error: unknown -format %q (want table or json)", format)
+	}
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	pairs, err := store.CommitEntries(branch, baseSHA, headSHA)
+	if errors.Is(err, storage.ErrNoComparableParams) {
+		fmt.Fprintf(os.Stderr, "Warning: %v; comparing the newest entries of different run configurations\n", err)
+	} else if err != nil {
+		log.Fatalf("Error reading branch data: %v", err)
+	}
+
+	rows := []compareRow{}
+	for _, pair := range pairs {
+		rows = append(rows, compareRows(pair.Head.ConfigKey(), compare.BySeries(pair.Base, pair.Head), threshold)...)
+	}
+	regressions := 0
+	warned := make(map[string]bool)
+	for _, row := range rows {
+		if row.Regressed {
+			regressions++
+		}
+		if threshold > 0 && row.Delta != nil && !warned[row.Unit] && model.UnitDirection(row.Unit) == model.DirectionUnknown {
+			warned[row.Unit] = true
+			fmt.Fprintf(os.Stderr, "Warning: unit %q has no known direction; treating lower as better\n", row.Unit)
+		}
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rows); err != nil {
+			log.Fatalf("Error writing JSON: %v", err)
+		}
+	default:
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "BENCHMARK\tUNIT\tPLATFORM\t%s\t%s\tDELTA\t\n", shortSHA(pairs[0].Base.Commit.SHA), shortSHA(pairs[0].Head.Commit.SHA))
+		for _, row := range rows {
+			name := row.Name
+			if row.Package != "" {
+				name = row.Package + "." + name
+			}
+			delta := row.Status
+			if row.Status == compareShared {
+				delta = metricDelta(row.Metric)
+			}
+			if row.Regressed {
+				delta += " (regression)"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t\n", name, row.Unit, platformLabel(model.EntryKeyValue{Params: row.Params, Tags: row.Tags}),
+				metricValue(row.Base, row.HasBase), metricValue(row.Head, row.HasHead), delta)
+		}
+		tw.Flush()
+	}
+
+	if regressions > 0 {
+		log.Fatalf("Error: %d benchmark(s) regressed by more than -threshold %.2f%%", regressions, threshold)
+	}
+}
+
+// Status of a compareRow.
+const (
+	compareShared  = "shared"
+	compareAdded   = "added"
+	compareRemoved = "removed"
+)
+
+// compareRow is one series of one run configuration in the output of
+// compare.
+type compareRow struct {
+	compare.Series
+	Params    model.RunParams `json:"params"`
+	Tags      string          `json:"tags,omitempty"`
+	Delta     *float64        `json:"delta,omitempty"`
+	Status    string          `json:"status"`
+	Regressed bool            `json:"regressed,omitempty"`
+}
+
+// compareRows annotates the series of one run configuration with their
+// status and delta, marking those that got worse by more than threshold
+// percent (0 = none).
+func compareRows(config model.EntryKeyValue, series []compare.Series, threshold float64) []compareRow {
+	rows := make([]compareRow, 0, len(series))
+	for _, s := range series {
+		row := compareRow{Series: s, Params: config.Params, Tags: config.Tags, Status: compareShared}
+		switch {
+		case !s.HasBase:
+			row.Status = compareAdded
+		case !s.HasHead:
+			row.Status = compareRemoved
+		}
+		if pct, ok := s.Delta(); ok {
+			row.Delta = &pct
+		}
+		row.Regressed = threshold > 0 && s.Regressed(threshold)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// shortSHA abbreviates a commit SHA for column headers.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/compare"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// compare-branches subcommand
+// ---------------------------------------------------------------------------
+
+func runCompareBranches(args []string) {
+	fs := flag.NewFlagSet("compare-branches", flag.ExitOnError)
+
+	var (
+		dataDir string
+		suite   string
+		a       string
+		b       string
+	)
+
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.StringVar(&a, "a", "main", "Base branch")
+	fs.StringVar(&b, "b", "", "Branch compared against -a (required)")
+
+	fs.Parse(args)
+
+	if b == "" {
+		log.Fatal("Error: -b is required")
+	}
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	rows, err := store.CompareBranchesLatest(a, b)
+	if errors.Is(err, storage.ErrNoComparableParams) {
+		fmt.Fprintf(os.Stderr, "Warning: %v; comparing the newest entries of different run configurations\n", err)
+	} else if err != nil {
+		log.Fatalf("Error comparing branches: %v", err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "BENCHMARK\t%s ns/op\t%s ns/op\tDELTA\tB/OP\tALLOCS/OP\t\n", a, b)
+	for _, row := range rows {
+		name := row.Name
+		if row.Package != "" {
+			name = row.Package + "." + name
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t\n", name,
+			metricValue(row.Time.Base, row.Time.HasBase), metricValue(row.Time.Head, row.Time.HasHead),
+			metricDelta(row.Time), metricDelta(row.Bytes), metricDelta(row.Allocs))
+	}
+	tw.Flush()
+}
+
+// metricValue formats one side of a compare.Metric, or "-" if absent.
+func metricValue(v float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+// metricDelta formats the percent change of m, or "~" if it has none.
+func metricDelta(m compare.Metric) string {
+	pct, ok := m.Delta()
+	if !ok {
+		return "~"
+	}
+	return fmt.Sprintf("%+.2f%%", pct)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// delete-benchmark subcommand
+// ---------------------------------------------------------------------------
+
+func runDeleteBenchmark(args []string) {
+	fs := flag.NewFlagSet("delete-benchmark", flag.ExitOnError)
+
+	var (
+		branch     string
+		dataDir    string
+		suite      string
+		name       string
+		pruneEmpty bool
+	)
+
+	fs.StringVar(&branch, "branch", "main", "Git branch name")
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.StringVar(&name, "name", "", "Benchmark name or glob to delete (required)")
+	fs.BoolVar(&pruneEmpty, "prune-empty", false, "Also drop entries left without any results")
+
+	fs.Parse(args)
+
+	if name == "" {
+		log.Fatal("Error: -name is required")
+	}
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	removed, err := store.DeleteBenchmark(branch, name)
+	if err != nil {
+		log.Fatalf("Error deleting benchmark: %v", err)
+	}
+	fmt.Printf("Removed %d result(s) matching %q from branch %q\n", removed, name, branch)
+
+	if pruneEmpty {
+		pruned, err := store.PruneEmptyEntries(branch)
+		if err != nil {
+			log.Fatalf("Error pruning empty entries: %v", err)
+		}
+		fmt.Printf("Pruned %d empty entries\n", pruned)
+	}
+
+	if err := store.WriteManifest(); err != nil {
+		log.Fatalf("Error writing manifest: %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/export"
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/regression"
+	"github.com/royalcat/go-continuous-benchmarking/internal/render"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// export subcommand
+// ---------------------------------------------------------------------------
+
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+
+	var (
+		branch      string
+		dataDir     string
+		suite       string
+		format      string
+		output      string
+		policy      string
+		threshold   float64
+		sigma       float64
+		window      int
+		concurrency int
+		benchmark   string
+		width       int
+		height      int
+		nowFlag     string
+	)
+
+	fs.StringVar(&branch, "branch", "main", "Git branch name")
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.StringVar(&format, "format", "benchfmt", "Output format: benchfmt (one branch), snapshot (newest values of every branch as JSON), junit (regression check of the newest commit), atom (feed of commits with changes above -threshold), png (sparkline of -benchmark) or csv (one row per result, for spreadsheets)")
+	fs.StringVar(&policy, "policy", "percent", "Regression policy for -format=junit: "+strings.Join(regression.PolicyNames(), ", "))
+	fs.Float64Var(&threshold, "threshold", 10, "Regression policy threshold for -format=junit; percent change that makes a commit a feed entry for -format=atom")
+	fs.Float64Var(&sigma, "sigma", regression.DefaultSigma, "Standard deviations above the recent mean that count as a regression for -format=junit with -policy=sigma")
+	fs.IntVar(&window, "history-window", regression.DefaultWindow, "Number of most recent comparable points -policy=sigma derives the tolerance from, for -format=junit")
+	fs.IntVar(&concurrency, "read-concurrency", 4, "Maximum number of branch data files read in parallel for -format=snapshot")
+	fs.StringVar(&benchmark, "benchmark", "", "Benchmark name for -format=png; of several matching series (e.g. per CPU) the longest is drawn")
+	fs.IntVar(&width, "width", 120, "Image width in pixels for -format=png")
+	fs.IntVar(&height, "height", 30, "Image height in pixels for -format=png")
+	fs.StringVar(&nowFlag, "now", "", "Current time in RFC 3339 recorded as generatedAt by -format=snapshot, for reproducible output (defaults to the system clock)")
+	fs.StringVar(&output, "o", "", "Output file (writes stdout if empty)")
+	fs.StringVar(&output, "output", "", "Same as -o")
+
+	fs.Parse(args)
+
+	storeOpts := []storage.Option{storage.WithSuite(suite)}
+	if nowFlag != "" {
+		now, err := time.Parse(time.RFC3339, nowFlag)
+		if err != nil {
+			log.Fatalf("Error parsing -now %q: %v", nowFlag, err)
+		}
+		storeOpts = append(storeOpts, storage.WithClock(func() time.Time { return now }))
+	}
+	store, err := storage.New(dataDir, storeOpts...)
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			log.Fatalf("Error creating output file: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch format {
+	case "benchfmt":
+		var data model.BranchData
+		if data, err = store.ReadBranchData(branch); err == nil {
+			err = export.BenchFmt(w, data)
+		}
+	case "csv":
+		var data model.BranchData
+		if data, err = store.ReadBranchData(branch); err == nil {
+			err = export.CSV(w, data)
+		}
+	case "junit":
+		var p regression.Policy
+		if p, err = policyFromFlags(policy, threshold, sigma, window); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		var data model.BranchData
+		if data, err = store.ReadBranchData(branch); err == nil {
+			var results []regression.Result
+			if results, err = checkNewestCommit(store, p, data); err == nil {
+				err = export.JUnit(w, results)
+			}
+		}
+	case "atom":
+		var data model.BranchData
+		if data, err = store.ReadBranchData(branch); err == nil {
+			var feed []byte
+			if feed, err = export.AtomFeed(branch, export.ChangeEvents(branch, data, threshold)); err == nil {
+				_, err = w.Write(feed)
+			}
+		}
+	case "png":
+		if benchmark == "" {
+			log.Fatal("Error: -benchmark is required for -format=png")
+		}
+		var data model.BranchData
+		if data, err = store.ReadBranchData(branch); err == nil {
+			var img []byte
+			values, unit := seriesValues(data, benchmark)
+			if img, err = render.SparklinePNG(values, model.UnitDirection(unit), width, height); err == nil {
+				_, err = w.Write(img)
+			}
+		}
+	case "snapshot":
+		var snap export.DashboardSnapshot
+		if snap, err = export.Snapshot(store, concurrency); err == nil {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(snap)
+		}
+	default:
+		log.Fatalf("Error: unknown export format %q", format)
+	}
+	if err != nil {
+		log.Fatalf("Error exporting data: %v", err)
+	}
+}
+
+// seriesValues returns the values of the longest series of data named
+// name, oldest first, and their unit. It is empty if no series matches.
+func seriesValues(data model.BranchData, name string) ([]float64, string) {
+	var longest []model.HistoryPoint
+	for _, id := range data.SeriesIDs() {
+		if id.Key.Name != name {
+			continue
+		}
+		if points := data.History(id.Config, id.Key); len(points) > len(longest) {
+			longest = points
+		}
+	}
+	values := make([]float64, len(longest))
+	unit := ""
+	for i, p := range longest {
+		values[i], unit = p.Value, p.Unit
+	}
+	return values, unit
+}
+
+// checkNewestCommit runs policy on every entry of the most recently stored
+// commit in data (one per matrix configuration) against the rest of data,
+// with the same annotations, suppression windows and pins of store as the
+// store regression gate.
+func checkNewestCommit(store *storage.Storage, policy regression.Policy, data model.BranchData) ([]regression.Result, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	checks, err := loadRegressionChecks(store)
+	if err != nil {
+		return nil, err
+	}
+
+	newest := data[len(data)-1].Commit.SHA
+	var results []regression.Result
+	for _, entry := range data {
+		if entry.Commit.SHA != newest {
+			continue
+		}
+		results = append(results, checks.check(policy, data, entry)...)
+	}
+	return results, nil
+}
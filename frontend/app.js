@@ -16,6 +16,8 @@
   const POINT_HOVER_RADIUS = 6;
 
   // ---- DOM references ----
+  const suiteSelect = document.getElementById("suite-select");
+  const suiteGroup = document.getElementById("suite-group");
   const branchSelect = document.getElementById("branch-select");
   const cpuSelect = document.getElementById("cpu-select");
   const cpuModelSelect = document.getElementById("cpu-model-select");
@@ -26,6 +28,8 @@
   const goarchGroup = document.getElementById("goarch-group");
   const goversionSelect = document.getElementById("goversion-select");
   const goversionGroup = document.getElementById("goversion-group");
+  const datasetSelect = document.getElementById("dataset-select");
+  const datasetGroup = document.getElementById("dataset-group");
   const cgoCheckbox = document.getElementById("cgo-checkbox");
   const cgoGroup = document.getElementById("cgo-group");
   const filterInput = document.getElementById("filter-input");
@@ -39,9 +43,11 @@
   // ---- State ----
   let currentBranchData = null; // raw array of BenchmarkEntry
   let currentBranch = null;
+  let currentSuite = ""; // "" = the default suite's flat layout
   let currentPackage = null; // null = "All" or first tab
   let chartInstances = []; // keep references so we can destroy on re-render
   let goModulePath = ""; // Go module path from metadata, used to shorten package names
+  let branchAliases = {}; // real branch name -> display name, from metadata
 
   // ---- Helpers ----
 
@@ -59,6 +65,33 @@
     return resp.json();
   }
 
+  // fetchBranchJSON fetches a branch data file, falling back to the
+  // gzip-compressed form written by store -gzip and then to the
+  // newline-delimited form written by store -storage-encoding=ndjson. The .gz
+  // file is served as plain bytes, so it is decompressed here.
+  async function fetchBranchJSON(url) {
+    const resp = await fetch(url);
+    if (resp.ok) {
+      return resp.json();
+    }
+    if (resp.status !== 404) {
+      throw new Error("HTTP " + resp.status + " fetching " + url);
+    }
+    const gz = await fetch(url + ".gz");
+    if (gz.ok) {
+      const stream = gz.body.pipeThrough(new DecompressionStream("gzip"));
+      return new Response(stream).json();
+    }
+    const nd = await fetch(url.replace(/\.json$/, ".ndjson"));
+    if (!nd.ok) {
+      throw new Error("HTTP " + resp.status + " fetching " + url);
+    }
+    return (await nd.text())
+      .split("\n")
+      .filter((line) => line.trim() !== "")
+      .map((line) => JSON.parse(line));
+  }
+
   function showMessage(html) {
     mainEl.innerHTML = '<div class="state-message">' + html + "</div>";
   }
@@ -154,6 +187,19 @@
     return Array.from(values).sort();
   }
 
+  /**
+   * GOARCH of an entry with its microarchitecture level, if recorded,
+   * e.g. "amd64/v3". Runs built for different levels are told apart by it.
+   */
+  function entryGOARCH(entry) {
+    var params = entry.params || {};
+    var goarch = params.goarch || entry.goarch || "";
+    if (goarch && params.microArch) {
+      goarch += "/" + params.microArch;
+    }
+    return goarch;
+  }
+
   /**
    * Extract all unique GOARCH values from data entries.
    * Returns sorted array of strings.
@@ -161,7 +207,7 @@
   function extractGOARCHValues(entries) {
     const values = new Set();
     for (const entry of entries) {
-      var goarch = entry.params ? entry.params.goarch : entry.goarch;
+      var goarch = entryGOARCH(entry);
       if (goarch) {
         values.add(goarch);
       }
@@ -184,6 +230,18 @@
     return Array.from(values).sort();
   }
 
+  /**
+   * Extract all unique dataset hashes from data entries, with "" for
+   * entries recorded without one. Returns sorted array of strings.
+   */
+  function extractDatasets(entries) {
+    const values = new Set();
+    for (const entry of entries) {
+      values.add((entry.params && entry.params.datasetHash) || "");
+    }
+    return Array.from(values).sort();
+  }
+
   /**
    * Extract the set of CGO values present in data entries.
    * Returns a Set of booleans.
@@ -226,6 +284,7 @@
     filterGOOS,
     filterGOARCH,
     filterGoVersion,
+    filterDataset,
     filterCGO,
   ) {
     const map = new Map();
@@ -247,8 +306,7 @@
       }
 
       // Filter by GOARCH at entry level
-      var entryGOARCH = params.goarch || entry.goarch || "";
-      if (filterGOARCH !== null && entryGOARCH !== filterGOARCH) {
+      if (filterGOARCH !== null && entryGOARCH(entry) !== filterGOARCH) {
         continue;
       }
 
@@ -258,6 +316,12 @@
         continue;
       }
 
+      // Filter by dataset at entry level
+      var entryDataset = params.datasetHash || "";
+      if (filterDataset !== null && entryDataset !== filterDataset) {
+        continue;
+      }
+
       // Filter by CGO status at entry level
       var entryCGO = entry.params ? params.cgo : entry.cgo;
       if (filterCGO !== null && !!entryCGO !== filterCGO) {
@@ -279,6 +343,8 @@
           date: date,
           bench: bench,
           cpu: entryCPU,
+          cpuModels: entry.cpuModels || [],
+          profileUrl: entry.profileUrl || "",
           params: params,
         };
         var arr = map.get(bench.name);
@@ -466,18 +532,23 @@
                   lines.push(d.commit.message);
                 }
                 lines.push("");
-                if (d.cpu) {
+                if (d.cpuModels.length > 1) {
+                  lines.push("CPU: " + d.cpuModels.join(" + "));
+                } else if (d.cpu) {
                   lines.push("CPU: " + d.cpu);
                 }
                 if (d.params.goos) {
                   lines.push("GOOS: " + d.params.goos);
                 }
                 if (d.params.goarch) {
-                  lines.push("GOARCH: " + d.params.goarch);
+                  lines.push("GOARCH: " + entryGOARCH(d));
                 }
                 if (d.params.goVersion) {
                   lines.push("Go: " + d.params.goVersion);
                 }
+                if (d.params.datasetHash) {
+                  lines.push("Dataset: " + d.params.datasetHash);
+                }
                 lines.push("CGO: " + !!d.params.cgo);
                 if (d.commit.date) {
                   lines.push("Date: " + formatDate(d.commit.date));
@@ -485,6 +556,17 @@
                 if (d.commit.author) {
                   lines.push("Author: @" + d.commit.author);
                 }
+                if (d.profileUrl) {
+                  lines.push("Shift+click to open the profile");
+                }
+                (d.commit.annotations || []).forEach(function (a) {
+                  if (a.kind === "workload-change") {
+                    lines.push("Known step change: workload changed");
+                  }
+                  if (a.note) {
+                    lines.push("Note: " + a.note);
+                  }
+                });
                 return lines.join("\n");
               },
               label: function (item) {
@@ -493,15 +575,29 @@
               afterLabel: function (item) {
                 var idx = item.dataIndex;
                 var d = dataset[idx];
-                return d.bench.extra ? "\n" + d.bench.extra : "";
+                var text = "";
+                if (d.bench.samples > 1) {
+                  text +=
+                    "\n\u00b1 " +
+                    (d.bench.stdDev / scaleFactor).toPrecision(3) +
+                    " " +
+                    displayUnit +
+                    " (" +
+                    d.bench.samples +
+                    " samples)";
+                }
+                return d.bench.extra ? text + "\n" + d.bench.extra : text;
               },
             },
           },
         },
-        onClick: function (_event, elements) {
+        onClick: function (event, elements) {
           if (!elements || elements.length === 0) return;
           var idx = elements[0].index;
           var url = dataset[idx].commit.url;
+          if (event.native && event.native.shiftKey && dataset[idx].profileUrl) {
+            url = dataset[idx].profileUrl;
+          }
           if (url) {
             window.open(url, "_blank");
           }
@@ -552,6 +648,12 @@
       filterGoVersion = goversionVal;
     }
 
+    // Dataset filter: only apply when entries differ in their dataset
+    var filterDataset = null;
+    if (extractDatasets(entries).length > 1) {
+      filterDataset = datasetSelect.value;
+    }
+
     // CGO filter: only apply when both values exist in data
     var filterCGO = null;
     var cgoValues = extractCGOValues(entries);
@@ -569,6 +671,7 @@
       filterGOOS,
       filterGOARCH,
       filterGoVersion,
+      filterDataset,
       filterCGO,
     );
 
@@ -595,7 +698,7 @@
           continue;
         if (
           filterGOARCH !== null &&
-          (entParams.goarch || ent.goarch || "") !== filterGOARCH
+          entryGOARCH(ent) !== filterGOARCH
         )
           continue;
         if (
@@ -603,6 +706,11 @@
           (entParams.goVersion || "") !== filterGoVersion
         )
           continue;
+        if (
+          filterDataset !== null &&
+          (entParams.datasetHash || "") !== filterDataset
+        )
+          continue;
         var entCGO = ent.params ? entParams.cgo : ent.cgo;
         if (filterCGO !== null && !!entCGO !== filterCGO) continue;
         var matched = false;
@@ -636,7 +744,7 @@
             continue;
           if (
             filterGOARCH !== null &&
-            (entParams2.goarch || ent2.goarch || "") !== filterGOARCH
+            entryGOARCH(ent2) !== filterGOARCH
           )
             continue;
           if (
@@ -644,6 +752,11 @@
             (entParams2.goVersion || "") !== filterGoVersion
           )
             continue;
+          if (
+            filterDataset !== null &&
+            (entParams2.datasetHash || "") !== filterDataset
+          )
+            continue;
           var entCGO2 = ent2.params ? entParams2.cgo : ent2.cgo;
           if (filterCGO !== null && !!entCGO2 !== filterCGO) continue;
           for (var bi2 = 0; bi2 < ent2.benchmarks.length; bi2++) {
@@ -697,6 +810,10 @@
       chartsEl.className = "bench-group-charts";
       groupEl.appendChild(chartsEl);
 
+      // Custom metrics (b.ReportMetric) get their own section below the
+      // standard ones, created on first use.
+      var customChartsEl = null;
+
       for (var ci = 0; ci < group.benchNames.length; ci++) {
         var benchName = group.benchNames[ci];
         var dataset = benchMap.get(benchName);
@@ -706,7 +823,21 @@
         var metric = metricLabel(benchName);
         var displayTitle = metric ? metric : dataset[0].bench.unit;
 
-        renderChart(chartsEl, benchName, displayTitle, dataset, ci);
+        var targetEl = chartsEl;
+        if (dataset[0].bench.custom) {
+          if (!customChartsEl) {
+            var customTitleEl = document.createElement("div");
+            customTitleEl.className = "bench-group-subtitle";
+            customTitleEl.textContent = "Custom metrics";
+            groupEl.appendChild(customTitleEl);
+            customChartsEl = document.createElement("div");
+            customChartsEl.className = "bench-group-charts";
+            groupEl.appendChild(customChartsEl);
+          }
+          targetEl = customChartsEl;
+        }
+
+        renderChart(targetEl, benchName, displayTitle, dataset, ci);
         rendered++;
       }
 
@@ -962,6 +1093,39 @@
     }
   });
 
+  // ---- Dataset selector ----
+
+  function populateDatasetSelector(entries) {
+    var values = extractDatasets(entries);
+    var currentVal = datasetSelect.value;
+
+    datasetSelect.innerHTML = "";
+
+    for (var i = 0; i < values.length; i++) {
+      var opt = document.createElement("option");
+      opt.value = values[i];
+      opt.textContent = values[i] || "(none)";
+      datasetSelect.appendChild(opt);
+    }
+
+    if (values.length <= 1) {
+      datasetGroup.style.display = "none";
+    } else {
+      datasetGroup.style.display = "flex";
+      if (values.indexOf(currentVal) >= 0) {
+        datasetSelect.value = currentVal;
+      } else {
+        datasetSelect.value = values[0];
+      }
+    }
+  }
+
+  datasetSelect.addEventListener("change", function () {
+    if (currentBranchData) {
+      renderBranch(currentBranchData);
+    }
+  });
+
   // ---- CGO checkbox ----
 
   function populateCGOCheckbox(entries) {
@@ -1003,28 +1167,165 @@
       if (metadata.goModule) {
         goModulePath = metadata.goModule;
       }
+      if (metadata.branchAliases) {
+        branchAliases = metadata.branchAliases;
+      }
     } catch {
       // metadata.json is optional
       lastUpdateEl.textContent = "\u2014";
     }
   }
 
+  // loadSuites returns the named suites from suites.json, which only exists
+  // once store -suite was used.
+  async function loadSuites() {
+    try {
+      var suites = await fetchJSON(getBasePath() + "suites.json");
+      return Array.isArray(suites) ? suites : [];
+    } catch {
+      return [];
+    }
+  }
+
+  // dataPath returns the URL of the current suite's data directory.
+  function dataPath() {
+    var base = getBasePath() + "data/";
+    return currentSuite ? base + encodeURIComponent(currentSuite) + "/" : base;
+  }
+
+  function groupedPath() {
+    var base = getBasePath() + "grouped/";
+    return currentSuite ? base + encodeURIComponent(currentSuite) + "/" : base;
+  }
+
+  // Rebuilds the entry array from a grouped data file (see
+  // storage.WriteGrouped): one entry per commit and run configuration,
+  // holding a result for every series with a point at that commit. The
+  // grouped form has no commit messages, authors or URLs.
+  function ungroupBranchData(grouped) {
+    var byRun = new Map();
+    Object.keys(grouped).forEach(function (name) {
+      grouped[name].forEach(function (series) {
+        var params = series.params || {};
+        var run = JSON.stringify([params, series.tags || ""]);
+        series.points.forEach(function (p) {
+          var key = p.sha + "\u0000" + p.date + "\u0000" + run;
+          var entry = byRun.get(key);
+          if (!entry) {
+            entry = {
+              commit: { sha: p.sha },
+              date: p.date,
+              params: params,
+              benchmarks: [],
+            };
+            byRun.set(key, entry);
+          }
+          entry.benchmarks.push({
+            name: name,
+            value: p.value,
+            unit: p.unit,
+            package: series.package || "",
+            procs: series.procs || 0,
+          });
+        });
+      });
+    });
+    return Array.from(byRun.values()).sort(function (a, b) {
+      return a.date - b.date;
+    });
+  }
+
+  // fetchGroupedData returns the entries of branch rebuilt from its grouped
+  // data file, written by store -write-grouped, or null if there is none.
+  async function fetchGroupedData(safeName) {
+    var resp = await fetch(groupedPath() + safeName + ".json");
+    if (!resp.ok) {
+      return null;
+    }
+    return ungroupBranchData(await resp.json());
+  }
+
   async function loadBranches() {
+    if (currentSuite) {
+      return fetchJSON(
+        getBasePath() + "branches/" + encodeURIComponent(currentSuite) + ".json"
+      );
+    }
     var base = getBasePath();
     var branches = await fetchJSON(base + "branches.json");
     return branches;
   }
 
+  // Stable string for a tags/environment map, matching model.CanonicalTags
+  // including its escaping of "\\", "," and "=".
+  function canonicalMap(m) {
+    if (!m) return "";
+    var escape = function (s) {
+      return String(s).replace(/[\\,=]/g, "\\$&");
+    };
+    return Object.keys(m)
+      .sort()
+      .map(function (k) {
+        return escape(k) + "=" + escape(m[k]);
+      })
+      .join(",");
+  }
+
+  // Reconstructs absolute values of a delta-encoded branch data file (see
+  // storage.WithDeltaEncoding). Plain files are arrays and pass through.
+  function decodeBranchData(file) {
+    if (Array.isArray(file) || !file || file.encoding !== "delta") {
+      return file;
+    }
+    var every = file.anchorEvery;
+    var entries = file.entries || [];
+    var series = {};
+    for (var i = 0; i < entries.length; i++) {
+      var e = entries[i];
+      var p = e.params || {};
+      var run = [
+        p.cpu || "",
+        p.goos || "",
+        p.goarch || "",
+        p.microArch || "",
+        p.goVersion || "",
+        !!p.cgo,
+        p.datasetHash || "",
+        canonicalMap(e.tags),
+        canonicalMap(e.environment),
+      ].join("\u0000");
+      var benches = e.benchmarks || [];
+      for (var j = 0; j < benches.length; j++) {
+        var r = benches[j];
+        var key = [run, r.package || "", r.name, r.unit, r.procs || 0].join(
+          "\u0000",
+        );
+        var st = series[key] || (series[key] = { count: 0, prev: 0 });
+        r.value = r.value || 0; // zero values are omitted
+        if (st.count % every !== 0 && st.prev !== 0) {
+          r.value = st.prev * (1 + r.value / 100);
+        }
+        st.prev = r.value;
+        st.count++;
+      }
+    }
+    return entries;
+  }
+
   async function loadBranchData(branch) {
-    var base = getBasePath();
+    var dir = dataPath();
     var safeName = branch.replace(/[/\\:*?"<>|]/g, "_");
-    var data = await fetchJSON(base + "data/" + safeName + ".json");
+    // Load the grouped form instead of the entries if store wrote one.
+    var data = await fetchGroupedData(safeName);
+    if (!data) {
+      data = decodeBranchData(await fetchBranchJSON(dir + safeName + ".json"));
+    }
 
     // For the "releases" virtual branch, try to attach the tag name to each
     // entry by loading the tag map that the store command generates.
     if (branch === "releases") {
       try {
-        var tagMap = await fetchJSON(base + "data/release_tags.json");
+        var tagMap = await fetchJSON(dir + "release_tags.json");
         if (tagMap) {
           for (var i = 0; i < data.length; i++) {
             var sha = data[i].commit && data[i].commit.sha;
@@ -1038,6 +1339,26 @@
       }
     }
 
+    // Attach commit annotations (e.g. known workload changes) if present.
+    try {
+      var annotations = await fetchJSON(dir + "annotations.json");
+      if (annotations && annotations.length) {
+        var bySHA = {};
+        for (var a = 0; a < annotations.length; a++) {
+          var ann = annotations[a];
+          (bySHA[ann.sha] = bySHA[ann.sha] || []).push(ann);
+        }
+        for (var j = 0; j < data.length; j++) {
+          var entrySHA = data[j].commit && data[j].commit.sha;
+          if (entrySHA && bySHA[entrySHA]) {
+            data[j].commit.annotations = bySHA[entrySHA];
+          }
+        }
+      }
+    } catch (_e) {
+      // annotations.json is optional
+    }
+
     return data;
   }
 
@@ -1061,6 +1382,7 @@
       populateGOOSSelector(currentBranchData);
       populateGOARCHSelector(currentBranchData);
       populateGoVersionSelector(currentBranchData);
+      populateDatasetSelector(currentBranchData);
       populateCGOCheckbox(currentBranchData);
 
       // Extract and render package tabs
@@ -1078,6 +1400,12 @@
 
   // ---- Event listeners ----
 
+  suiteSelect.addEventListener("change", function () {
+    currentSuite = suiteSelect.value;
+    currentBranch = null;
+    showSuite(null).then(updateHash);
+  });
+
   branchSelect.addEventListener("change", function () {
     selectBranch(branchSelect.value);
     if (branchSelect.value) {
@@ -1111,6 +1439,9 @@
 
   function updateHash() {
     var params = new URLSearchParams();
+    if (currentSuite) {
+      params.set("suite", currentSuite);
+    }
     if (currentBranch) {
       params.set("branch", currentBranch);
     }
@@ -1119,8 +1450,26 @@
 
   // ---- Initialization ----
 
-  async function init() {
-    await loadMetadata();
+  // populateSuiteSelector lists the default suite and the named suites,
+  // showing the selector only if there are named suites.
+  function populateSuiteSelector(suites) {
+    suiteSelect.innerHTML = "";
+    var names = [""].concat(suites);
+    for (var i = 0; i < names.length; i++) {
+      var opt = document.createElement("option");
+      opt.value = names[i];
+      opt.textContent = names[i] || "default";
+      suiteSelect.appendChild(opt);
+    }
+    suiteSelect.value = currentSuite;
+    suiteGroup.style.display = "flex";
+  }
+
+  // showSuite loads the branch list of the current suite and selects
+  // requestedBranch, or the first branch if it is not in the list.
+  async function showSuite(requestedBranch) {
+    destroyCharts();
+    packageTabsEl.innerHTML = "";
 
     var branches;
     try {
@@ -1150,25 +1499,45 @@
       if (brName === "releases") {
         opt.textContent = "📦 releases";
       } else {
-        opt.textContent = brName;
+        opt.textContent = branchAliases[brName] || brName;
       }
       branchSelect.appendChild(opt);
     }
 
-    // Try to select from URL hash
     var initialBranch = branches[0];
-    var hash = window.location.hash;
-    if (hash) {
-      var params = new URLSearchParams(hash.slice(1));
-      var requested = params.get("branch");
-      if (requested && branches.indexOf(requested) >= 0) {
-        initialBranch = requested;
-      }
+    if (requestedBranch && branches.indexOf(requestedBranch) >= 0) {
+      initialBranch = requestedBranch;
     }
 
     branchSelect.value = initialBranch;
     await selectBranch(initialBranch);
   }
 
+  async function init() {
+    await loadMetadata();
+
+    // Try to select from URL hash
+    var params = new URLSearchParams(window.location.hash.slice(1));
+
+    var suites = await loadSuites();
+    if (suites.length > 0) {
+      var requestedSuite = params.get("suite");
+      if (requestedSuite && suites.indexOf(requestedSuite) >= 0) {
+        currentSuite = requestedSuite;
+      } else {
+        // Repositories that only store named suites have no flat
+        // branches.json; start on their first suite then.
+        try {
+          await loadBranches();
+        } catch {
+          currentSuite = suites[0];
+        }
+      }
+      populateSuiteSelector(suites);
+    }
+
+    await showSuite(params.get("branch"));
+  }
+
   init();
 })();
@@ -2,7 +2,10 @@ module github.com/royalcat/go-continuous-benchmarking
 
 go 1.24.0
 
-require github.com/shirou/gopsutil/v4 v4.26.1
+require (
+	github.com/andybalholm/brotli v1.2.0
+	github.com/shirou/gopsutil/v4 v4.26.1
+)
 
 require (
 	github.com/ebitengine/purego v0.9.1 // indirect
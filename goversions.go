@@ -0,0 +1,199 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// compare-goversions subcommand
+// ---------------------------------------------------------------------------
+
+func runCompareGoVersions(args []string) {
+	fs := flag.NewFlagSet("compare-goversions", flag.ExitOnError)
+
+	var (
+		branch  string
+		dataDir string
+		suite   string
+		sha     string
+		goos    string
+		goarch  string
+		cpu     string
+	)
+
+	fs.StringVar(&branch, "branch", "main", "Git branch name")
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.StringVar(&sha, "sha", "", "Commit SHA to compare, or a unique prefix of it (required)")
+	fs.StringVar(&goos, "goos", "", "Only compare entries with this GOOS")
+	fs.StringVar(&goarch, "goarch", "", "Only compare entries with this GOARCH")
+	fs.StringVar(&cpu, "cpu", "", "Only compare entries with this CPU model")
+
+	fs.Parse(args)
+
+	if sha == "" {
+		log.Fatal("Error: -sha is required")
+	}
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	byVersion, err := commitGoVersions(store, branch, sha, func(p model.RunParams) bool {
+		return (goos == "" || p.GOOS == goos) && (goarch == "" || p.GOARCH == goarch) && (cpu == "" || p.CPU == cpu)
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if len(byVersion) == 0 {
+		log.Fatalf("Error: no entries for commit %s on branch %q", sha, branch)
+	}
+
+	versions, rows := goVersionTable(byVersion)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "BENCHMARK\tUNIT\tPLATFORM\t%s\t\n", strings.Join(versions, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(versions))
+		for i, v := range versions {
+			cells[i] = "-"
+			if val, ok := row.values[v]; ok {
+				cells[i] = strconv.FormatFloat(val, 'f', -1, 64)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", row.series.Name, row.series.Unit, platformLabel(row.config), strings.Join(cells, "\t"))
+	}
+	tw.Flush()
+}
+
+// commitGoVersions returns the entries of the commit of branch that sha
+// names, which may be abbreviated, whose run parameters satisfy match,
+// keyed by their Go version.
+func commitGoVersions(store *storage.Storage, branch, sha string, match func(model.RunParams) bool) (map[string][]model.BenchmarkEntry, error) {
+	full, err := store.ResolveSHA(branch, sha)
+	if err != nil {
+		return nil, fmt.Errorf("resolving -sha: %w", err)
+	}
+	byVersion, err := store.ByGoVersionMatching(branch, full, match)
+	if err != nil {
+		return nil, fmt.Errorf("reading branch data: %w", err)
+	}
+	return byVersion, nil
+}
+
+// goVersionRow is one row of the compare-goversions table: a series on one
+// platform, with its value per Go version.
+type goVersionRow struct {
+	config model.EntryKeyValue // ConfigKey without the Go version
+	series model.SeriesKey
+	values map[string]float64
+}
+
+// goVersionTable returns the Go versions of byVersion in version order and
+// one row per series and platform (every run parameter but the Go version,
+// plus the experiment tags), in order of first appearance across versions,
+// so that entries of different platforms are never mixed into one row.
+func goVersionTable(byVersion map[string][]model.BenchmarkEntry) ([]string, []goVersionRow) {
+	versions := make([]string, 0, len(byVersion))
+	for v := range byVersion {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return goVersionLess(versions[i], versions[j]) })
+
+	type rowKey struct {
+		config model.EntryKeyValue
+		series model.SeriesKey
+	}
+	var rows []goVersionRow
+	index := make(map[rowKey]int)
+	for _, v := range versions {
+		for _, e := range byVersion[v] {
+			config := e.ConfigKey()
+			config.Params.GoVersion = ""
+			for _, r := range e.Benchmarks {
+				k := rowKey{config, r.SeriesKey()}
+				i, ok := index[k]
+				if !ok {
+					i = len(rows)
+					index[k] = i
+					rows = append(rows, goVersionRow{config: config, series: k.series, values: make(map[string]float64)})
+				}
+				rows[i].values[v] = r.Value
+			}
+		}
+	}
+	return versions, rows
+}
+
+// platformLabel renders a run configuration compactly, e.g.
+// `linux/amd64 go1.22.0 cgo1 "Intel Xeon"`. The Go version is left out when
+// empty.
+func platformLabel(config model.EntryKeyValue) string {
+	p := config.Params
+	cgo := "cgo0"
+	if p.CGO {
+		cgo = "cgo1"
+	}
+	label := p.GOOS + "/" + p.GOARCH
+	if p.GoVersion != "" {
+		label += " " + p.GoVersion
+	}
+	label += fmt.Sprintf(" %s %q", cgo, p.CPU)
+	if config.Tags != "" {
+		label += " " + config.Tags
+	}
+	return label
+}
+
+// goVersionLess orders Go version strings like "go1.9" < "go1.22.1".
+// Versions that are not numeric after the "go" prefix, such as "devel" or
+// "tip" builds, sort after all releases.
+func goVersionLess(a, b string) bool {
+	pa, okA := goVersionParts(a)
+	pb, okB := goVersionParts(b)
+	if okA != okB {
+		return okA
+	}
+	if okA {
+		for i := 0; i < max(len(pa), len(pb)); i++ {
+			var x, y int
+			if i < len(pa) {
+				x = pa[i]
+			}
+			if i < len(pb) {
+				y = pb[i]
+			}
+			if x != y {
+				return x < y
+			}
+		}
+	}
+	return a < b
+}
+
+// goVersionParts splits "go1.22.1" into [1 22 1].
+func goVersionParts(v string) ([]int, bool) {
+	rest, ok := strings.CutPrefix(v, "go")
+	if !ok || rest == "" {
+		return nil, false
+	}
+	var parts []int
+	for _, s := range strings.Split(rest, ".") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
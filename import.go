@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/importer"
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// import subcommand
+// ---------------------------------------------------------------------------
+
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+
+	var (
+		branch    string
+		dataDir   string
+		suite     string
+		format    string
+		promURL   string
+		query     string
+		start     string
+		end       string
+		step      time.Duration
+		timeout   time.Duration
+		goVersion string
+		cgo       bool
+	)
+
+	fs.StringVar(&branch, "branch", "main", "Git branch name to import into")
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.StringVar(&format, "format", "prometheus", "Source format: prometheus")
+	fs.StringVar(&promURL, "url", "http://localhost:9090", "Prometheus (or Thanos) server address")
+	fs.StringVar(&query, "query", "", "PromQL range query selecting the benchmark series (required)")
+	fs.StringVar(&start, "start", "", "Start of the range in RFC 3339 (default: 30 days before -end)")
+	fs.StringVar(&end, "end", "", "End of the range in RFC 3339 (default: now)")
+	fs.DurationVar(&step, "step", time.Hour, "Query resolution step")
+	fs.DurationVar(&timeout, "timeout", importer.DefaultPromTimeout, "Maximum duration of the query request")
+	fs.StringVar(&goVersion, "go-version", "", "Go version of series without a go_version label, e.g. go1.22.1; set it as parse recorded it so the imported entries share a series with stored ones")
+	fs.BoolVar(&cgo, "cgo", true, "Whether series without a cgo label were built with CGO enabled")
+
+	fs.Parse(args)
+
+	if format != "prometheus" {
+		log.Fatalf("Error: unknown import format %q", format)
+	}
+	if query == "" {
+		log.Fatal("Error: -query is required")
+	}
+
+	endTime := time.Now()
+	if end != "" {
+		var err error
+		if endTime, err = time.Parse(time.RFC3339, end); err != nil {
+			log.Fatalf("Error parsing -end %q: %v", end, err)
+		}
+	}
+	startTime := endTime.AddDate(0, 0, -30)
+	if start != "" {
+		var err error
+		if startTime, err = time.Parse(time.RFC3339, start); err != nil {
+			log.Fatalf("Error parsing -start %q: %v", start, err)
+		}
+	}
+
+	api := importer.HTTPPromAPI{BaseURL: promURL, Step: step, Client: &http.Client{Timeout: timeout}}
+	data, err := importer.FromPromRangeWithDefaults(api, query, startTime, endTime, model.RunParams{GoVersion: goVersion, CGO: cgo})
+	if err != nil {
+		log.Fatalf("Error importing from prometheus: %v", err)
+	}
+	if len(data) == 0 {
+		log.Fatal("Error: the query returned no samples")
+	}
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+	if err := store.AppendEntries(branch, data, 0); err != nil {
+		log.Fatalf("Error storing imported entries: %v", err)
+	}
+	fmt.Printf("Imported %d entry/entries into branch %q\n", len(data), branch)
+}
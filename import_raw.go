@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/importer"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// import-raw subcommand
+// ---------------------------------------------------------------------------
+
+func runImportRaw(args []string) {
+	fs := flag.NewFlagSet("import-raw", flag.ExitOnError)
+
+	var (
+		glob       string
+		shaFrom    string
+		shaPattern string
+		branch     string
+		dataDir    string
+		suite      string
+		goVersion  string
+		cgo        bool
+	)
+
+	fs.StringVar(&glob, "glob", "", "Glob of archived go test -bench output files, e.g. 'archive/*.txt' (required)")
+	fs.StringVar(&shaFrom, "sha-from", "filename", "Where to take each file's commit SHA from: filename")
+	fs.StringVar(&shaPattern, "sha-pattern", importer.DefaultSHAPattern,
+		"Regular expression extracting the SHA from the file name; a (?P<sha>...) group selects it, an optional (?P<date>...) group dates the commit and an optional (?P<branch>...) group skips files of other branches than -branch")
+	fs.StringVar(&branch, "branch", "main", "Git branch name to import into")
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.StringVar(&goVersion, "go-version", "", "Go version the archived runs were built with, e.g. go1.22.1; set it as parse recorded it so the imported entries share a series with stored ones")
+	fs.BoolVar(&cgo, "cgo", true, "Whether the archived runs were built with CGO enabled")
+
+	fs.Parse(args)
+
+	if glob == "" {
+		log.Fatal("Error: -glob is required")
+	}
+	if shaFrom != "filename" {
+		log.Fatalf("Error: unknown -sha-from %q (want filename)", shaFrom)
+	}
+
+	entries, err := importer.FromRawFiles(glob, shaPattern, branch)
+	if err != nil {
+		log.Fatalf("Error importing raw output: %v", err)
+	}
+	if len(entries) == 0 {
+		log.Fatalf("Error: no benchmark results in files matching %q", glob)
+	}
+	for i := range entries {
+		entries[i].Params.GoVersion = goVersion
+		entries[i].Params.CGO = cgo
+	}
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+	if err := store.AppendEntries(branch, entries, 0); err != nil {
+		log.Fatalf("Error storing imported entries: %v", err)
+	}
+	fmt.Printf("Imported %d entry/entries into branch %q\n", len(entries), branch)
+}
@@ -0,0 +1,153 @@
+// Package compare matches the benchmark results of two entries and computes
+// per-benchmark deltas.
+package compare
+
+import "github.com/royalcat/go-continuous-benchmarking/internal/model"
+
+// Metric holds one metric of a benchmark on both sides of a comparison.
+// HasBase/HasHead report whether the metric was present on that side.
+type Metric struct {
+	Base    float64 `json:"base"`
+	Head    float64 `json:"head"`
+	HasBase bool    `json:"hasBase"`
+	HasHead bool    `json:"hasHead"`
+}
+
+// Delta returns the percent change from Base to Head. ok is false when the
+// metric is missing on either side or Base is zero.
+func (m Metric) Delta() (pct float64, ok bool) {
+	if !m.HasBase || !m.HasHead || m.Base == 0 {
+		return 0, false
+	}
+	return (m.Head - m.Base) / m.Base * 100, true
+}
+
+// Row groups the time and allocation metrics of a single benchmark, as
+// reported by `go test -bench -benchmem`.
+type Row struct {
+	Package string `json:"package,omitempty"`
+	Name    string `json:"name"`
+	Procs   int    `json:"procs,omitempty"`
+	Time    Metric `json:"time"`
+	Bytes   Metric `json:"bytes"`
+	Allocs  Metric `json:"allocs"`
+}
+
+// rowKey identifies a benchmark independently of its metric.
+type rowKey struct {
+	pkg   string
+	name  string
+	procs int
+}
+
+// ByBenchmark matches the results of base and head by package, benchmark
+// name and procs, and returns one row per benchmark holding its ns/op,
+// B/op and allocs/op values on both sides. Results in other units are
+// ignored. Rows follow the order of head, followed by benchmarks only
+// present in base.
+func ByBenchmark(base, head model.BenchmarkEntry) []Row {
+	var order []rowKey
+	rows := make(map[rowKey]*Row)
+
+	add := func(r model.BenchmarkResult, isHead bool) {
+		var pick func(row *Row) *Metric
+		switch r.Unit {
+		case "ns/op":
+			pick = func(row *Row) *Metric { return &row.Time }
+		case "B/op":
+			pick = func(row *Row) *Metric { return &row.Bytes }
+		case "allocs/op":
+			pick = func(row *Row) *Metric { return &row.Allocs }
+		default:
+			return
+		}
+
+		k := rowKey{pkg: r.Package, name: r.BaseName(), procs: r.Procs}
+		row, ok := rows[k]
+		if !ok {
+			row = &Row{Package: k.pkg, Name: k.name, Procs: k.procs}
+			rows[k] = row
+			order = append(order, k)
+		}
+
+		m := pick(row)
+		if isHead {
+			m.Head, m.HasHead = r.Value, true
+		} else {
+			m.Base, m.HasBase = r.Value, true
+		}
+	}
+
+	for _, r := range head.Benchmarks {
+		add(r, true)
+	}
+	for _, r := range base.Benchmarks {
+		add(r, false)
+	}
+
+	out := make([]Row, 0, len(order))
+	for _, k := range order {
+		out = append(out, *rows[k])
+	}
+	return out
+}
+
+// Series is one metric of one benchmark on both sides of a comparison,
+// for any unit.
+type Series struct {
+	Package string `json:"package,omitempty"`
+	Name    string `json:"name"`
+	Unit    string `json:"unit"`
+	Procs   int    `json:"procs,omitempty"`
+	Metric
+}
+
+// Regressed reports whether the series got worse by more than threshold
+// percent in the direction of its unit (see model.UnitDirection). Series
+// missing on either side never regress.
+func (s Series) Regressed(threshold float64) bool {
+	pct, ok := s.Delta()
+	if !ok {
+		return false
+	}
+	if model.UnitDirection(s.Unit).Higher() {
+		return -pct > threshold
+	}
+	return pct > threshold
+}
+
+// BySeries matches the results of base and head by model.SeriesKey and
+// returns one Series per result, in any unit. Series follow the order of
+// head, followed by series only present in base.
+func BySeries(base, head model.BenchmarkEntry) []Series {
+	var order []model.SeriesKey
+	series := make(map[model.SeriesKey]*Series)
+
+	add := func(r model.BenchmarkResult, isHead bool) {
+		k := r.SeriesKey()
+		s, ok := series[k]
+		if !ok {
+			s = &Series{Package: r.Package, Name: r.Name, Unit: r.Unit, Procs: r.Procs}
+			series[k] = s
+			order = append(order, k)
+		}
+		if isHead {
+			s.Head, s.HasHead = r.Value, true
+		} else {
+			s.Base, s.HasBase = r.Value, true
+		}
+	}
+
+	for _, r := range head.Benchmarks {
+		add(r, true)
+	}
+	for _, r := range base.Benchmarks {
+		add(r, false)
+	}
+
+	out := make([]Series, 0, len(order))
+	for _, k := range order {
+		out = append(out, *series[k])
+	}
+	return out
+}
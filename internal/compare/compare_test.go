@@ -0,0 +1,132 @@
+package compare
+
+import (
+	"math"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func memEntry(sha string, results ...model.BenchmarkResult) model.BenchmarkEntry {
+	return model.BenchmarkEntry{Commit: model.Commit{SHA: sha}, Benchmarks: results}
+}
+
+func TestByBenchmark_GroupsMetricFamilies(t *testing.T) {
+	base := memEntry("base",
+		model.BenchmarkResult{Name: "BenchmarkEncode", Value: 1000, Unit: "ns/op", Package: "pkg", Procs: 8},
+		model.BenchmarkResult{Name: "BenchmarkEncode - B/op", Value: 512, Unit: "B/op", Package: "pkg", Procs: 8},
+		model.BenchmarkResult{Name: "BenchmarkEncode - allocs/op", Value: 4, Unit: "allocs/op", Package: "pkg", Procs: 8},
+		model.BenchmarkResult{Name: "BenchmarkRemoved", Value: 50, Unit: "ns/op", Package: "pkg", Procs: 8},
+	)
+	head := memEntry("head",
+		model.BenchmarkResult{Name: "BenchmarkEncode", Value: 900, Unit: "ns/op", Package: "pkg", Procs: 8},
+		model.BenchmarkResult{Name: "BenchmarkEncode - B/op", Value: 768, Unit: "B/op", Package: "pkg", Procs: 8},
+		model.BenchmarkResult{Name: "BenchmarkEncode - allocs/op", Value: 2, Unit: "allocs/op", Package: "pkg", Procs: 8},
+		model.BenchmarkResult{Name: "BenchmarkEncode - MB/s", Value: 10, Unit: "MB/s", Package: "pkg", Procs: 8},
+		model.BenchmarkResult{Name: "BenchmarkAdded", Value: 70, Unit: "ns/op", Package: "pkg", Procs: 8},
+	)
+
+	rows := ByBenchmark(base, head)
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d: %+v", len(rows), rows)
+	}
+
+	enc := rows[0]
+	if enc.Name != "BenchmarkEncode" || enc.Package != "pkg" || enc.Procs != 8 {
+		t.Fatalf("row 0: got %+v", enc)
+	}
+	checkDelta(t, "time", enc.Time, -10)
+	checkDelta(t, "bytes", enc.Bytes, 50)
+	checkDelta(t, "allocs", enc.Allocs, -50)
+
+	added := rows[1]
+	if added.Name != "BenchmarkAdded" || added.Time.HasBase || !added.Time.HasHead {
+		t.Errorf("added row: got %+v", added)
+	}
+	if _, ok := added.Time.Delta(); ok {
+		t.Error("added benchmark should have no delta")
+	}
+
+	removed := rows[2]
+	if removed.Name != "BenchmarkRemoved" || !removed.Time.HasBase || removed.Time.HasHead {
+		t.Errorf("removed row: got %+v", removed)
+	}
+}
+
+func TestByBenchmark_DistinctProcs(t *testing.T) {
+	base := memEntry("base",
+		model.BenchmarkResult{Name: "BenchmarkWork", Value: 100, Unit: "ns/op", Procs: 1},
+		model.BenchmarkResult{Name: "BenchmarkWork", Value: 40, Unit: "ns/op", Procs: 4},
+	)
+	head := memEntry("head",
+		model.BenchmarkResult{Name: "BenchmarkWork", Value: 110, Unit: "ns/op", Procs: 1},
+		model.BenchmarkResult{Name: "BenchmarkWork", Value: 20, Unit: "ns/op", Procs: 4},
+	)
+
+	rows := ByBenchmark(base, head)
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	checkDelta(t, "procs=1", rows[0].Time, 10)
+	checkDelta(t, "procs=4", rows[1].Time, -50)
+}
+
+func TestBySeries(t *testing.T) {
+	base := memEntry("base",
+		model.BenchmarkResult{Name: "BenchmarkEncode", Value: 1000, Unit: "ns/op"},
+		model.BenchmarkResult{Name: "BenchmarkEncode - MB/s", Value: 100, Unit: "MB/s"},
+		model.BenchmarkResult{Name: "BenchmarkEncode - hits/op", Value: 3, Unit: "hits/op"},
+		model.BenchmarkResult{Name: "BenchmarkRemoved", Value: 50, Unit: "ns/op"},
+	)
+	head := memEntry("head",
+		model.BenchmarkResult{Name: "BenchmarkEncode", Value: 1200, Unit: "ns/op"},
+		model.BenchmarkResult{Name: "BenchmarkEncode - MB/s", Value: 80, Unit: "MB/s"},
+		model.BenchmarkResult{Name: "BenchmarkEncode - hits/op", Value: 3, Unit: "hits/op"},
+		model.BenchmarkResult{Name: "BenchmarkAdded", Value: 70, Unit: "ns/op"},
+	)
+
+	series := BySeries(base, head)
+	var names []string
+	for _, s := range series {
+		names = append(names, s.Name)
+	}
+	want := []string{"BenchmarkEncode", "BenchmarkEncode - MB/s", "BenchmarkEncode - hits/op", "BenchmarkAdded", "BenchmarkRemoved"}
+	if len(names) != len(want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("got %v, want %v", names, want)
+		}
+	}
+
+	checkDelta(t, "ns/op", series[0].Metric, 20)
+	checkDelta(t, "MB/s", series[1].Metric, -20)
+	if !series[0].Regressed(10) || series[0].Regressed(25) {
+		t.Error("ns/op +20%: want regressed at 10%, not at 25%")
+	}
+	if !series[1].Regressed(10) {
+		t.Error("MB/s -20%: want regressed at 10%, higher is better")
+	}
+	if series[2].Regressed(0) {
+		t.Error("unchanged custom metric should not regress")
+	}
+	if series[3].HasBase || !series[3].HasHead || series[3].Regressed(0) {
+		t.Errorf("added series: got %+v", series[3])
+	}
+	if !series[4].HasBase || series[4].HasHead || series[4].Regressed(0) {
+		t.Errorf("removed series: got %+v", series[4])
+	}
+}
+
+func checkDelta(t *testing.T, label string, m Metric, want float64) {
+	t.Helper()
+	got, ok := m.Delta()
+	if !ok {
+		t.Errorf("%s: delta undefined for %+v", label, m)
+		return
+	}
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s: delta = %v, want %v", label, got, want)
+	}
+}
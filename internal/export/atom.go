@@ -0,0 +1,171 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// Change is one benchmark whose value moved notably at a commit.
+// Regression reports whether it moved for the worse in the direction of
+// Unit (see model.UnitDirection).
+type Change struct {
+	Name       string
+	Unit       string
+	From       float64
+	To         float64
+	Percent    float64
+	Regression bool
+}
+
+// ChangeEvent groups the notable changes of one commit on a branch.
+type ChangeEvent struct {
+	Branch  string
+	Commit  model.Commit
+	Date    int64
+	Changes []Change
+}
+
+// ChangeEvents compares every entry of data with the previous comparable
+// entry (see model.BranchData.PreviousComparable) and returns one event per
+// commit with at least one benchmark that changed by more than threshold
+// percent in either direction. Events are ordered like data.
+func ChangeEvents(branch string, data model.BranchData, threshold float64) []ChangeEvent {
+	var events []ChangeEvent
+	index := make(map[string]int)
+	for i, e := range data {
+		prev := data[:i].PreviousComparable(e)
+		if prev == nil {
+			continue
+		}
+		before := make(map[model.SeriesKey]float64, len(prev.Benchmarks))
+		for _, r := range prev.Benchmarks {
+			before[r.SeriesKey()] = r.Value
+		}
+
+		var changes []Change
+		for _, r := range e.Benchmarks {
+			from, ok := before[r.SeriesKey()]
+			if !ok || from == 0 {
+				continue
+			}
+			pct := (r.Value - from) / from * 100
+			if math.Abs(pct) > threshold {
+				changes = append(changes, Change{
+					Name:       r.Name,
+					Unit:       r.Unit,
+					From:       from,
+					To:         r.Value,
+					Percent:    pct,
+					Regression: (pct > 0) != model.UnitDirection(r.Unit).Higher(),
+				})
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		// Matrix entries of the same commit share one event.
+		if j, ok := index[e.Commit.SHA]; ok {
+			events[j].Changes = append(events[j].Changes, changes...)
+			continue
+		}
+		index[e.Commit.SHA] = len(events)
+		events = append(events, ChangeEvent{Branch: branch, Commit: e.Commit, Date: e.Date, Changes: changes})
+	}
+	return events
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomName    `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID      string    `xml:"id"`
+	Title   string    `xml:"title"`
+	Updated string    `xml:"updated"`
+	Link    *atomLink `xml:"link,omitempty"`
+	Author  *atomName `xml:"author,omitempty"`
+	Content atomText  `xml:"content"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomName struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// atomTime formats a Unix millisecond timestamp as an Atom date.
+func atomTime(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
+
+// AtomFeed renders the events of branch as an Atom feed, newest first, so
+// that notable benchmark changes can be followed in a feed reader. Each
+// commit is an entry linking to its Commit.URL, with one line per changed
+// benchmark in the body. The feed is identified and titled by branch, so a
+// feed without events keeps the identity of the branch's feed.
+func AtomFeed(branch string, events []ChangeEvent) ([]byte, error) {
+	feed := atomFeed{
+		ID:      "urn:gobenchdata:changes:" + branch,
+		Title:   "Benchmark changes " + branch,
+		Updated: atomTime(0),
+		Author:  atomName{Name: "gobenchdata"},
+	}
+
+	for i := len(events) - 1; i >= 0; i-- {
+		ev := events[i]
+		if u := atomTime(ev.Date); ev.Date > 0 && u > feed.Updated {
+			feed.Updated = u
+		}
+
+		short := ev.Commit.SHA
+		if len(short) > 7 {
+			short = short[:7]
+		}
+		var body strings.Builder
+		for _, c := range ev.Changes {
+			verdict := "improvement"
+			if c.Regression {
+				verdict = "regression"
+			}
+			fmt.Fprintf(&body, "%s: %g -> %g %s (%+.2f%%, %s)\n", c.Name, c.From, c.To, c.Unit, c.Percent, verdict)
+		}
+
+		entry := atomEntry{
+			ID:      fmt.Sprintf("urn:gobenchdata:changes:%s:%s", ev.Branch, ev.Commit.SHA),
+			Title:   strings.TrimSpace(fmt.Sprintf("%s: %d benchmark change(s) %s", short, len(ev.Changes), ev.Commit.Message)),
+			Updated: atomTime(ev.Date),
+			Content: atomText{Type: "text", Text: body.String()},
+		}
+		if ev.Commit.URL != "" {
+			entry.Link = &atomLink{Href: ev.Commit.URL}
+		}
+		if ev.Commit.Author != "" {
+			entry.Author = &atomName{Name: ev.Commit.Author}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding atom feed: %w", err)
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestAtomFeed(t *testing.T) {
+	params := model.RunParams{GOOS: "linux", GOARCH: "amd64"}
+	entry := func(sha string, date int64, value float64) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha, Message: "change " + sha, URL: "https://example.com/commit/" + sha},
+			Date:       date,
+			Params:     params,
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: value, Unit: "ns/op"}},
+		}
+	}
+	data := model.BranchData{
+		entry("aaa", 1_700_000_000_000, 100),
+		entry("bbb", 1_700_000_100_000, 103), // +3%: below threshold
+		entry("ccc", 1_700_000_200_000, 130), // +26%
+		entry("ddd", 1_700_000_300_000, 91),  // -30%
+	}
+
+	events := ChangeEvents("main", data, 10)
+	if len(events) != 2 || events[0].Commit.SHA != "ccc" || events[1].Commit.SHA != "ddd" {
+		t.Fatalf("got events %+v, want ccc and ddd", events)
+	}
+
+	out, err := AtomFeed("main", events)
+	if err != nil {
+		t.Fatalf("AtomFeed() error: %v", err)
+	}
+
+	var feed atomFeed
+	if err := xml.Unmarshal(out, &feed); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out)
+	}
+	if feed.XMLName.Space != "http://www.w3.org/2005/Atom" || feed.XMLName.Local != "feed" {
+		t.Errorf("got root %v, want Atom feed", feed.XMLName)
+	}
+	if feed.ID != "urn:gobenchdata:changes:main" || feed.Title != "Benchmark changes main" || feed.Updated != "2023-11-14T22:18:20Z" {
+		t.Errorf("feed header: got id %q title %q updated %q", feed.ID, feed.Title, feed.Updated)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(feed.Entries))
+	}
+	newest := feed.Entries[0]
+	if newest.Link == nil || newest.Link.Href != "https://example.com/commit/ddd" {
+		t.Errorf("newest entry link: got %+v", newest.Link)
+	}
+	if !strings.Contains(newest.Content.Text, "BenchmarkFoo: 130 -> 91 ns/op (-30.00%, improvement)") {
+		t.Errorf("newest entry body: got %q", newest.Content.Text)
+	}
+	if !strings.Contains(feed.Entries[1].Content.Text, "(+26.21%, regression)") {
+		t.Errorf("older entry body: got %q", feed.Entries[1].Content.Text)
+	}
+	if newest.ID == feed.Entries[1].ID {
+		t.Error("entries share an id")
+	}
+}
+
+func TestAtomFeed_NoEvents(t *testing.T) {
+	out, err := AtomFeed("release/v2", nil)
+	if err != nil {
+		t.Fatalf("AtomFeed() error: %v", err)
+	}
+	var feed atomFeed
+	if err := xml.Unmarshal(out, &feed); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out)
+	}
+	if feed.ID != "urn:gobenchdata:changes:release/v2" || feed.Title != "Benchmark changes release/v2" || len(feed.Entries) != 0 {
+		t.Errorf("got id %q title %q with %d entries, want the release/v2 feed without entries", feed.ID, feed.Title, len(feed.Entries))
+	}
+}
@@ -0,0 +1,118 @@
+// Package export renders stored benchmark data in formats understood by
+// external tooling.
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// BenchFmt writes entries in the golang.org/x/perf textual benchmark format
+// so that the stored history can be fed to benchstat.
+//
+// Each entry starts with configuration lines derived from its commit and
+// RunParams, followed by one line per benchmark. Metrics that the parser
+// split into separate "Name - unit" results are folded back onto the line
+// of their benchmark.
+func BenchFmt(w io.Writer, entries model.BranchData) error {
+	bw := bufio.NewWriter(w)
+
+	for i, e := range entries {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		writeConfig(bw, "commit", e.Commit.SHA)
+		writeConfig(bw, "goos", e.Params.GOOS)
+		writeConfig(bw, "goarch", e.Params.GOARCH)
+		writeConfig(bw, "cpu", e.Params.CPU)
+		writeConfig(bw, "go", e.Params.GoVersion)
+		fmt.Fprintf(bw, "cgo: %t\n", e.Params.CGO)
+		writeConfig(bw, "tags", model.CanonicalTags(e.Tags))
+
+		pkg := ""
+		for j, line := range benchLines(e.Benchmarks) {
+			if j == 0 || line.pkg != pkg {
+				pkg = line.pkg
+				fmt.Fprintf(bw, "pkg: %s\n", pkg)
+			}
+			bw.WriteString(line.String())
+			bw.WriteString("\n")
+		}
+	}
+
+	return bw.Flush()
+}
+
+func writeConfig(w *bufio.Writer, key, value string) {
+	if value != "" {
+		fmt.Fprintf(w, "%s: %s\n", key, value)
+	}
+}
+
+// benchLine is one benchmark result line with all of its metrics.
+type benchLine struct {
+	pkg     string
+	name    string
+	procs   int
+	iters   string
+	metrics []model.BenchmarkResult
+}
+
+func (l benchLine) String() string {
+	var sb strings.Builder
+	sb.WriteString(l.name)
+	if l.procs > 1 {
+		sb.WriteString("-")
+		sb.WriteString(strconv.Itoa(l.procs))
+	}
+	sb.WriteString("\t")
+	sb.WriteString(l.iters)
+	for _, m := range l.metrics {
+		sb.WriteString("\t")
+		sb.WriteString(strconv.FormatFloat(m.Value, 'f', -1, 64))
+		sb.WriteString(" ")
+		sb.WriteString(m.Unit)
+	}
+	return sb.String()
+}
+
+// benchLines groups results by (package, benchmark, procs) in order of first
+// appearance. The primary ns/op metric is kept first on its line, matching
+// go test output.
+func benchLines(results []model.BenchmarkResult) []benchLine {
+	type key struct {
+		pkg, name string
+		procs     int
+	}
+
+	var lines []benchLine
+	index := make(map[key]int)
+	for _, r := range results {
+		name := r.BaseName()
+		k := key{r.Package, name, r.Procs}
+
+		i, ok := index[k]
+		if !ok {
+			i = len(lines)
+			index[k] = i
+			lines = append(lines, benchLine{
+				pkg:   r.Package,
+				name:  name,
+				procs: r.Procs,
+				iters: strconv.Itoa(max(r.Iterations(), 1)),
+			})
+		}
+
+		if r.Unit == "ns/op" {
+			lines[i].metrics = append([]model.BenchmarkResult{r}, lines[i].metrics...)
+		} else {
+			lines[i].metrics = append(lines[i].metrics, r)
+		}
+	}
+	return lines
+}
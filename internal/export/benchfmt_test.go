@@ -0,0 +1,81 @@
+package export
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/parse"
+)
+
+func TestBenchFmt_RoundTrip(t *testing.T) {
+	input := `goos: linux
+goarch: amd64
+pkg: github.com/user/repo/a
+cpu: Intel(R) Core(TM) i7-8700 CPU @ 3.20GHz
+BenchmarkFoo-8   	 1000000	      1234 ns/op	     256 B/op	       5 allocs/op
+BenchmarkBar/sub-4   	 500	      0.5 ns/op
+pkg: github.com/user/repo/b
+BenchmarkBaz	 100	 42 MB/s
+PASS
+`
+	want, meta, err := parse.ParseGoBenchOutputWithMeta(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: "abc123"},
+		Params:     model.RunParams{CPU: meta.CPU, GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.24.0"},
+		Benchmarks: want,
+	}
+
+	var sb strings.Builder
+	if err := BenchFmt(&sb, model.BranchData{entry}); err != nil {
+		t.Fatalf("BenchFmt() error: %v", err)
+	}
+
+	got, gotMeta, err := parse.ParseGoBenchOutputWithMeta(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("parsing exported output: %v\n%s", err, sb.String())
+	}
+	if gotMeta.CPU != meta.CPU {
+		t.Errorf("cpu: got %q, want %q", gotMeta.CPU, meta.CPU)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch\ngot:  %+v\nwant: %+v\noutput:\n%s", got, want, sb.String())
+	}
+}
+
+func TestBenchFmt_ConfigLines(t *testing.T) {
+	entries := model.BranchData{
+		{
+			Commit:     model.Commit{SHA: "aaa"},
+			Params:     model.RunParams{CPU: "cpu1", GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.22.0", CGO: true},
+			Tags:       map[string]string{"gc": "off"},
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 10, Unit: "ns/op", Package: "p"}},
+		},
+		{
+			Commit:     model.Commit{SHA: "bbb"},
+			Params:     model.RunParams{CPU: "cpu1", GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.22.0"},
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 12, Unit: "ns/op", Package: "p"}},
+		},
+	}
+
+	var sb strings.Builder
+	if err := BenchFmt(&sb, entries); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+
+	for _, want := range []string{
+		"commit: aaa\n", "commit: bbb\n", "goos: linux\n", "goarch: amd64\n",
+		"cpu: cpu1\n", "go: go1.22.0\n", "cgo: true\n", "cgo: false\n",
+		"tags: gc=off\n", "pkg: p\n", "BenchmarkFoo\t1\t10 ns/op\n", "BenchmarkFoo\t1\t12 ns/op\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// csvHeader names the columns written by CSV.
+var csvHeader = []string{"commit", "date", "message", "benchmark", "value", "unit", "cpu", "goos", "goarch", "go_version"}
+
+// CSV writes entries as RFC 4180 CSV for spreadsheets: a header row, then
+// one row per benchmark result, so a commit with ten results yields ten
+// rows. Fields holding commas, quotes or newlines (e.g. multi-line commit
+// messages) are quoted, and rows end in CRLF.
+//
+// The date is the commit date as stored, or the entry timestamp in RFC
+// 3339 if the commit has none.
+func CSV(w io.Writer, entries model.BranchData) error {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		date := e.Commit.Date
+		if date == "" && e.Date != 0 {
+			date = time.UnixMilli(e.Date).UTC().Format(time.RFC3339)
+		}
+		for _, r := range e.Benchmarks {
+			row := []string{
+				e.Commit.SHA,
+				date,
+				e.Commit.Message,
+				r.Name,
+				strconv.FormatFloat(r.Value, 'f', -1, 64),
+				r.Unit,
+				e.Params.CPU,
+				e.Params.GOOS,
+				e.Params.GOARCH,
+				e.Params.GoVersion,
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
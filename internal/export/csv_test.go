@@ -0,0 +1,53 @@
+package export
+
+import (
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestCSV(t *testing.T) {
+	params := model.RunParams{CPU: "Intel Xeon", GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.24.0"}
+	data := model.BranchData{
+		{
+			Commit: model.Commit{SHA: "abc123", Date: "2024-01-01T00:00:00Z", Message: "Speed up encoder\n\nUses a \"fast\" path, finally"},
+			Params: params,
+			Benchmarks: []model.BenchmarkResult{
+				{Name: "BenchmarkEncode", Value: 1234.5, Unit: "ns/op"},
+				{Name: "BenchmarkEncode - B/op", Value: 256, Unit: "B/op"},
+			},
+		},
+		{
+			Commit:     model.Commit{SHA: "def456"},
+			Date:       1704153600000,
+			Params:     params,
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkEncode", Value: 1000, Unit: "ns/op"}},
+		},
+	}
+
+	var sb strings.Builder
+	if err := CSV(&sb, data); err != nil {
+		t.Fatalf("CSV() error: %v", err)
+	}
+	out := sb.String()
+	if !strings.Contains(out, "\"Speed up encoder\r\n\r\nUses a \"\"fast\"\" path, finally\"") {
+		t.Errorf("commit message not quoted per RFC 4180:\n%s", out)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("reading back CSV: %v", err)
+	}
+	want := [][]string{
+		csvHeader,
+		{"abc123", "2024-01-01T00:00:00Z", "Speed up encoder\n\nUses a \"fast\" path, finally", "BenchmarkEncode", "1234.5", "ns/op", "Intel Xeon", "linux", "amd64", "go1.24.0"},
+		{"abc123", "2024-01-01T00:00:00Z", "Speed up encoder\n\nUses a \"fast\" path, finally", "BenchmarkEncode - B/op", "256", "B/op", "Intel Xeon", "linux", "amd64", "go1.24.0"},
+		{"def456", "2024-01-02T00:00:00Z", "", "BenchmarkEncode", "1000", "ns/op", "Intel Xeon", "linux", "amd64", "go1.24.0"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got rows\n%q\nwant\n%q", rows, want)
+	}
+}
@@ -0,0 +1,112 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/regression"
+)
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit writes regression results as a JUnit XML report, so that CI test
+// result views can show benchmark gating. Each result is a test case named
+// after the benchmark, its unit, procs and run configuration, so that the
+// entries of a build matrix get distinct names, classed by package; it fails
+// when the result regressed, with the policy's message (which includes the
+// delta) as failure message, and is skipped when the regression was
+// suppressed.
+func JUnit(w io.Writer, results []regression.Result) error {
+	suite := junitTestSuite{Name: "benchmarks", Tests: len(results)}
+	for _, r := range results {
+		attrs := []string{r.Series.Unit}
+		if r.Series.Procs > 0 {
+			attrs = append(attrs, fmt.Sprintf("procs=%d", r.Series.Procs))
+		}
+		attrs = append(attrs, configAttrs(r.Config)...)
+		tc := junitTestCase{
+			ClassName: r.Series.Package,
+			Name:      fmt.Sprintf("%s [%s]", r.Series.Name, strings.Join(attrs, ", ")),
+		}
+		detail := fmt.Sprintf("%s -> %s %s (commit %s, baseline %s)",
+			strconv.FormatFloat(r.Previous.Value, 'f', -1, 64), strconv.FormatFloat(r.Current.Value, 'f', -1, 64), r.Series.Unit, r.Current.SHA, r.Previous.SHA)
+		switch {
+		case r.Regressed:
+			suite.Failures++
+			tc.Failure = &junitMessage{Message: r.Message, Type: "regression", Text: detail}
+		case r.Suppressed:
+			suite.Skipped++
+			tc.Skipped = &junitMessage{Message: r.SuppressReason, Text: r.Message}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return fmt.Errorf("encoding junit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// configAttrs lists the set dimensions of a run configuration, e.g.
+// ["linux/amd64", "go1.22.0", "cgo", "cpu=Intel Xeon", "alloc=arena"].
+func configAttrs(k model.EntryKeyValue) []string {
+	p := k.Params
+	var attrs []string
+	if p.GOOS != "" || p.GOARCH != "" {
+		attrs = append(attrs, p.GOOS+"/"+p.GOARCH)
+	}
+	if p.MicroArch != "" {
+		attrs = append(attrs, "microarch="+p.MicroArch)
+	}
+	if p.GoVersion != "" {
+		attrs = append(attrs, p.GoVersion)
+	}
+	if p.CGO {
+		attrs = append(attrs, "cgo")
+	}
+	if p.CPU != "" {
+		attrs = append(attrs, "cpu="+p.CPU)
+	}
+	if p.DatasetHash != "" {
+		attrs = append(attrs, "dataset="+p.DatasetHash)
+	}
+	if k.Tags != "" {
+		attrs = append(attrs, k.Tags)
+	}
+	return attrs
+}
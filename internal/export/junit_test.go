@@ -0,0 +1,74 @@
+package export
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/regression"
+)
+
+func TestJUnit(t *testing.T) {
+	results := []regression.Result{
+		{
+			Series:   model.SeriesKey{Package: "example.com/pkg", Name: "BenchmarkFast", Unit: "ns/op", Procs: 8},
+			Previous: model.HistoryPoint{SHA: "aaa", Value: 100},
+			Current:  model.HistoryPoint{SHA: "bbb", Value: 101},
+			Message:  "+1.00% (threshold 10.00%)",
+		},
+		{
+			Config: model.EntryKeyValue{
+				Params: model.RunParams{CPU: "Apple M2", GOOS: "darwin", GOARCH: "arm64", GoVersion: "go1.24.0", CGO: true},
+				Tags:   "alloc=arena",
+			},
+			Series:    model.SeriesKey{Package: "example.com/pkg", Name: "BenchmarkSlow", Unit: "ns/op", Procs: 8},
+			Previous:  model.HistoryPoint{SHA: "aaa", Value: 100},
+			Current:   model.HistoryPoint{SHA: "bbb", Value: 150},
+			Regressed: true,
+			Message:   "+50.00% (threshold 10.00%)",
+		},
+		{
+			Series:         model.SeriesKey{Name: "BenchmarkStep", Unit: "ns/op"},
+			Suppressed:     true,
+			SuppressReason: "known workload change",
+		},
+	}
+
+	var sb strings.Builder
+	if err := JUnit(&sb, results); err != nil {
+		t.Fatalf("JUnit() error: %v", err)
+	}
+
+	var got junitTestSuites
+	if err := xml.Unmarshal([]byte(sb.String()), &got); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, sb.String())
+	}
+	if len(got.Suites) != 1 {
+		t.Fatalf("got %d suites, want 1", len(got.Suites))
+	}
+	suite := got.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Skipped != 1 {
+		t.Errorf("got tests=%d failures=%d skipped=%d, want 3/1/1", suite.Tests, suite.Failures, suite.Skipped)
+	}
+
+	pass, fail, skip := suite.Cases[0], suite.Cases[1], suite.Cases[2]
+	if pass.Failure != nil || pass.Skipped != nil {
+		t.Errorf("passing case has failure or skip: %+v", pass)
+	}
+	if pass.ClassName != "example.com/pkg" || pass.Name != "BenchmarkFast [ns/op, procs=8]" {
+		t.Errorf("got case %q/%q", pass.ClassName, pass.Name)
+	}
+	if want := "BenchmarkSlow [ns/op, procs=8, darwin/arm64, go1.24.0, cgo, cpu=Apple M2, alloc=arena]"; fail.Name != want {
+		t.Errorf("got case name %q, want %q", fail.Name, want)
+	}
+	if fail.Failure == nil || fail.Failure.Message != "+50.00% (threshold 10.00%)" {
+		t.Fatalf("failing case: got %+v", fail.Failure)
+	}
+	if !strings.Contains(fail.Failure.Text, "100 -> 150 ns/op") {
+		t.Errorf("failure text %q lacks the values", fail.Failure.Text)
+	}
+	if skip.Skipped == nil || skip.Skipped.Message != "known workload change" {
+		t.Errorf("suppressed case: got %+v", skip.Skipped)
+	}
+}
@@ -0,0 +1,80 @@
+package export
+
+import (
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// DashboardSnapshot is a summary of the whole store for embedding elsewhere, e.g. a
+// status page: the newest values of every registered branch.
+type DashboardSnapshot struct {
+	// GeneratedAt is the time the snapshot was taken, in Unix milliseconds
+	// like metadata.json's lastUpdate.
+	GeneratedAt int64            `json:"generatedAt"`
+	Branches    []SnapshotBranch `json:"branches"`
+}
+
+// SnapshotBranch holds the newest entry of one branch. Latest is nil for a
+// branch without data.
+type SnapshotBranch struct {
+	Name   string          `json:"name"`
+	Latest *SnapshotLatest `json:"latest,omitempty"`
+}
+
+// SnapshotLatest is the newest entry of a branch reduced to its values.
+type SnapshotLatest struct {
+	SHA        string           `json:"sha"`
+	Date       int64            `json:"date"`
+	Benchmarks []SnapshotResult `json:"benchmarks"`
+}
+
+// SnapshotResult is one value of a SnapshotLatest.
+type SnapshotResult struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+// Snapshot reads every branch in branches.json, up to concurrency files at
+// a time, and returns the entry with the newest date of each, in the order
+// of branches.json. Of entries with equal dates the one stored last wins.
+// GeneratedAt is taken from the storage clock (see storage.WithClock).
+func Snapshot(s *storage.Storage, concurrency int) (DashboardSnapshot, error) {
+	snap := DashboardSnapshot{GeneratedAt: s.Now().UnixMilli(), Branches: []SnapshotBranch{}}
+
+	branches, err := s.ReadBranches()
+	if err != nil {
+		return DashboardSnapshot{}, err
+	}
+	all, err := s.ReadAllBranches(concurrency)
+	if err != nil {
+		return DashboardSnapshot{}, err
+	}
+	for _, branch := range branches {
+		snap.Branches = append(snap.Branches, SnapshotBranch{Name: branch, Latest: latest(all[branch])})
+	}
+	return snap, nil
+}
+
+// latest returns the newest entry of data as a SnapshotLatest, or nil.
+func latest(data model.BranchData) *SnapshotLatest {
+	var newest *model.BenchmarkEntry
+	for i := range data {
+		if newest == nil || data[i].Date >= newest.Date {
+			newest = &data[i]
+		}
+	}
+	if newest == nil {
+		return nil
+	}
+
+	l := &SnapshotLatest{
+		SHA:        newest.Commit.SHA,
+		Date:       newest.Date,
+		Benchmarks: make([]SnapshotResult, len(newest.Benchmarks)),
+	}
+	for i, r := range newest.Benchmarks {
+		l.Benchmarks[i] = SnapshotResult{Name: r.Name, Value: r.Value, Unit: r.Unit}
+	}
+	return l
+}
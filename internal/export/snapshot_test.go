@@ -0,0 +1,61 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+func TestSnapshot_NewestEntryPerBranch(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, err := storage.New(t.TempDir(), storage.WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	entry := func(sha string, date int64, value float64) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha},
+			Date:       date,
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: value, Unit: "ns/op"}},
+		}
+	}
+	if err := s.AppendEntries("main", []model.BenchmarkEntry{entry("m2", 2000, 20), entry("m1", 1000, 10)}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AppendEntries("develop", []model.BenchmarkEntry{entry("d1", 1500, 15), entry("d3", 3000, 30)}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.EnsureBranch("empty"); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := Snapshot(s, 4)
+	if err != nil {
+		t.Fatalf("Snapshot() error: %v", err)
+	}
+	if snap.GeneratedAt != now.UnixMilli() {
+		t.Errorf("GeneratedAt: got %d, want %d from the storage clock", snap.GeneratedAt, now.UnixMilli())
+	}
+
+	want := map[string]string{"main": "m2", "develop": "d3", "empty": ""}
+	if len(snap.Branches) != len(want) {
+		t.Fatalf("got %d branches, want %d", len(snap.Branches), len(want))
+	}
+	for _, b := range snap.Branches {
+		got := ""
+		if b.Latest != nil {
+			got = b.Latest.SHA
+		}
+		if got != want[b.Name] {
+			t.Errorf("%s: got latest %q, want %q", b.Name, got, want[b.Name])
+		}
+	}
+	for _, b := range snap.Branches {
+		if b.Name == "develop" && b.Latest.Benchmarks[0].Value != 30 {
+			t.Errorf("develop: got value %v, want 30", b.Latest.Benchmarks[0].Value)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package gitinfo reads commit information from a local git repository by
+// shelling out to the git binary.
+package gitinfo
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// git runs git with args inside dir and returns its trimmed stdout.
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// MergeBase returns the SHA of the best common ancestor of refs a and b.
+func MergeBase(dir, a, b string) (string, error) {
+	return git(dir, "merge-base", a, b)
+}
+
+// Ancestors returns the SHA of ref followed by its first-parent ancestors,
+// nearest first, up to limit commits in total (0 = no limit).
+func Ancestors(dir, ref string, limit int) ([]string, error) {
+	args := []string{"rev-list", "--first-parent"}
+	if limit > 0 {
+		args = append(args, "--max-count="+strconv.Itoa(limit))
+	}
+	args = append(args, ref)
+
+	out, err := git(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// Commit is the metadata of a single commit.
+type Commit struct {
+	SHA     string
+	Subject string
+	Author  string
+	Date    string // author date, strict ISO 8601
+}
+
+// HeadCommit returns the metadata of the commit checked out at HEAD.
+func HeadCommit(dir string) (Commit, error) {
+	return CommitInfo(dir, "HEAD")
+}
+
+// CommitInfo returns the metadata of the commit ref resolves to, e.g. a
+// full or abbreviated SHA.
+func CommitInfo(dir, ref string) (Commit, error) {
+	out, err := git(dir, "log", "-1", "--format=%H%x00%s%x00%an%x00%aI", ref, "--")
+	if err != nil {
+		return Commit{}, err
+	}
+	fields := strings.Split(out, "\x00")
+	if len(fields) != 4 {
+		return Commit{}, fmt.Errorf("unexpected git log output %q", out)
+	}
+	return Commit{SHA: fields[0], Subject: fields[1], Author: fields[2], Date: fields[3]}, nil
+}
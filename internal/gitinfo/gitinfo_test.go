@@ -0,0 +1,117 @@
+package gitinfo
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// initRepo creates a repository with a main line of three commits and a
+// feature branch forked from the second one.
+func initRepo(t *testing.T) (dir string, main []string, feature string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir = t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := git(dir, args...)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		return out
+	}
+
+	run("init", "-q", "-b", "main")
+	run("config", "user.name", "test")
+	run("config", "user.email", "test@example.com")
+	for _, msg := range []string{"one", "two"} {
+		run("commit", "-q", "--allow-empty", "-m", msg)
+		main = append(main, run("rev-parse", "HEAD"))
+	}
+	run("checkout", "-q", "-b", "feature")
+	run("commit", "-q", "--allow-empty", "-m", "feature")
+	feature = run("rev-parse", "HEAD")
+	run("checkout", "-q", "main")
+	run("commit", "-q", "--allow-empty", "-m", "three")
+	main = append(main, run("rev-parse", "HEAD"))
+
+	return dir, main, feature
+}
+
+func TestMergeBase(t *testing.T) {
+	dir, main, feature := initRepo(t)
+
+	got, err := MergeBase(dir, "main", feature)
+	if err != nil {
+		t.Fatalf("MergeBase() error: %v", err)
+	}
+	if got != main[1] {
+		t.Errorf("MergeBase() = %s, want %s", got, main[1])
+	}
+}
+
+func TestAncestors(t *testing.T) {
+	dir, main, _ := initRepo(t)
+
+	got, err := Ancestors(dir, "main", 0)
+	if err != nil {
+		t.Fatalf("Ancestors() error: %v", err)
+	}
+	want := []string{main[2], main[1], main[0]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Ancestors() = %v, want %v", got, want)
+	}
+
+	got, err = Ancestors(dir, "main", 2)
+	if err != nil {
+		t.Fatalf("Ancestors() error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("Ancestors(limit=2) = %v, want %v", got, want[:2])
+	}
+}
+
+func TestMergeBase_UnknownRef(t *testing.T) {
+	dir, _, _ := initRepo(t)
+
+	if _, err := MergeBase(dir, "main", "does-not-exist"); err == nil {
+		t.Fatal("expected error for unknown ref")
+	}
+}
+
+func TestHeadCommit(t *testing.T) {
+	dir, main, _ := initRepo(t)
+
+	got, err := HeadCommit(dir)
+	if err != nil {
+		t.Fatalf("HeadCommit() error: %v", err)
+	}
+	if got.SHA != main[2] || got.Subject != "three" || got.Author != "test" {
+		t.Errorf("HeadCommit() = %+v, want SHA %s, subject three, author test", got, main[2])
+	}
+	if _, err := time.Parse(time.RFC3339, got.Date); err != nil {
+		t.Errorf("HeadCommit() date %q is not RFC 3339: %v", got.Date, err)
+	}
+}
+
+func TestCommitInfo(t *testing.T) {
+	dir, main, feature := initRepo(t)
+
+	got, err := CommitInfo(dir, feature[:10])
+	if err != nil {
+		t.Fatalf("CommitInfo() error: %v", err)
+	}
+	if got.SHA != feature || got.Subject != "feature" {
+		t.Errorf("CommitInfo() = %+v, want SHA %s, subject feature", got, feature)
+	}
+	if got, err := CommitInfo(dir, main[0]); err != nil || got.Subject != "one" {
+		t.Errorf("CommitInfo(%s) = %+v, %v; want subject one", main[0], got, err)
+	}
+	if _, err := CommitInfo(dir, "0000000000000000000000000000000000000000"); err == nil {
+		t.Error("unknown commit: want an error")
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/shirou/gopsutil/v4/cpu"
 )
 
+// cpuInfo is the gopsutil CPU info source, replaceable in tests.
+var cpuInfo = cpu.Info
+
 // CPUModel returns a human-readable string identifying the CPU of the
 // current machine.  It uses gopsutil which works on Linux, FreeBSD,
 // OpenBSD, macOS, Windows, Solaris, and AIX.
@@ -16,7 +19,7 @@ import (
 // built from GOARCH and the available identifiers is returned.
 // The result is never empty.
 func CPUModel() string {
-	infos, err := cpu.Info()
+	infos, err := cpuInfo()
 	if err == nil && len(infos) > 0 {
 		if infos[0].ModelName != "" {
 			return infos[0].ModelName
@@ -44,3 +47,36 @@ func CPUModel() string {
 
 	return runtime.GOARCH
 }
+
+// CPUModels returns the distinct CPU model names of the current machine in
+// the order gopsutil reports them. Heterogeneous hosts (big.LITTLE ARM,
+// mixed multi-socket servers) yield more than one name; most machines yield
+// exactly one. The result is nil if no model name is available.
+//
+// Unlike CPUModel, no fallback string is synthesized.
+func CPUModels() []string {
+	infos, err := cpuInfo()
+	if err != nil {
+		return nil
+	}
+	return distinctModels(infos)
+}
+
+// distinctModels returns the unique non-empty model names in infos,
+// preserving first-seen order.
+func distinctModels(infos []cpu.InfoStat) []string {
+	seen := make(map[string]struct{})
+	var models []string
+	for _, info := range infos {
+		name := info.ModelName
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		models = append(models, name)
+	}
+	return models
+}
@@ -1,7 +1,10 @@
 package hwinfo
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/shirou/gopsutil/v4/cpu"
 )
 
 func TestCPUModel_NonEmpty(t *testing.T) {
@@ -19,3 +22,45 @@ func TestCPUModel_Deterministic(t *testing.T) {
 		t.Errorf("CPUModel() returned different values on consecutive calls: %q vs %q", a, b)
 	}
 }
+
+func TestCPUModels_Host(t *testing.T) {
+	// Most hosts report a single model; heterogeneous ones report more.
+	// Either way the names must be distinct and non-empty.
+	models := CPUModels()
+	seen := make(map[string]bool)
+	for _, m := range models {
+		if m == "" {
+			t.Error("CPUModels() returned an empty name")
+		}
+		if seen[m] {
+			t.Errorf("CPUModels() returned duplicate %q", m)
+		}
+		seen[m] = true
+	}
+	t.Logf("detected CPU models: %v", models)
+}
+
+func TestCPUModels_Heterogeneous(t *testing.T) {
+	orig := cpuInfo
+	t.Cleanup(func() { cpuInfo = orig })
+
+	cpuInfo = func() ([]cpu.InfoStat, error) {
+		return []cpu.InfoStat{
+			{ModelName: "Cortex-A78"},
+			{ModelName: "Cortex-A78"},
+			{ModelName: ""},
+			{ModelName: "Cortex-X1"},
+			{ModelName: "Cortex-A78"},
+		}, nil
+	}
+
+	want := []string{"Cortex-A78", "Cortex-X1"}
+	if got := CPUModels(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CPUModels() = %v, want %v", got, want)
+	}
+
+	// The representative single model is still the first one.
+	if got := CPUModel(); got != "Cortex-A78" {
+		t.Errorf("CPUModel() = %q, want %q", got, "Cortex-A78")
+	}
+}
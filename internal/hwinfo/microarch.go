@@ -0,0 +1,42 @@
+package hwinfo
+
+import (
+	"os"
+	"strings"
+)
+
+// microArchEnv maps each GOARCH to the environment variable that selects
+// its microarchitecture level, see "go help environment".
+var microArchEnv = map[string]string{
+	"386":      "GO386",
+	"amd64":    "GOAMD64",
+	"arm":      "GOARM",
+	"arm64":    "GOARM64",
+	"mips":     "GOMIPS",
+	"mipsle":   "GOMIPS",
+	"mips64":   "GOMIPS64",
+	"mips64le": "GOMIPS64",
+	"ppc64":    "GOPPC64",
+	"ppc64le":  "GOPPC64",
+	"riscv64":  "GORISCV64",
+	"wasm":     "GOWASM",
+}
+
+// MicroArchEnv returns the name of the environment variable that selects
+// the microarchitecture level for goarch, e.g. GOAMD64 for amd64, or "" if
+// goarch has none.
+func MicroArchEnv(goarch string) string {
+	return microArchEnv[goarch]
+}
+
+// MicroArch returns the microarchitecture level go builds for goarch with,
+// as set in the environment (e.g. "v3" from GOAMD64=v3). It returns "" if
+// the variable is unset or goarch has none: the toolchain default is not
+// assumed, since it can change between Go releases.
+func MicroArch(goarch string) string {
+	name := MicroArchEnv(goarch)
+	if name == "" {
+		return ""
+	}
+	return strings.TrimSpace(os.Getenv(name))
+}
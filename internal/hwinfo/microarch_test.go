@@ -0,0 +1,25 @@
+package hwinfo
+
+import "testing"
+
+func TestMicroArch(t *testing.T) {
+	t.Setenv("GOAMD64", "v3")
+	t.Setenv("GOARM", " 7 ")
+	t.Setenv("GOARM64", "")
+
+	tests := []struct {
+		goarch string
+		want   string
+	}{
+		{"amd64", "v3"},
+		{"arm", "7"},
+		{"arm64", ""},
+		{"s390x", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := MicroArch(tt.goarch); got != tt.want {
+			t.Errorf("MicroArch(%q) = %q, want %q", tt.goarch, got, tt.want)
+		}
+	}
+}
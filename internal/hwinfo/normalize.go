@@ -0,0 +1,33 @@
+package hwinfo
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// reCPUMarks matches trademark marks: "(R)", "(TM)", "®" and "™".
+	reCPUMarks = regexp.MustCompile(`(?i)\((?:r|tm)\)|®|™`)
+	// reCPUWord matches the standalone word "CPU".
+	reCPUWord = regexp.MustCompile(`(?i)\bcpu\b`)
+	// reCPUClock matches a trailing clock speed like "@ 3.20GHz".
+	reCPUClock = regexp.MustCompile(`(?i)@\s*\d+(?:\.\d+)?\s*[gm]hz\s*$`)
+)
+
+// NormalizeCPUModel reduces a CPU model string, as reported by a go test
+// cpu: line or by CPUModel, to a stable canonical name: trademark marks,
+// the word "CPU" and a trailing "@ <clock>" are removed and whitespace is
+// collapsed, so that "Intel(R) Core(TM) i7-8700 CPU @ 3.20GHz" becomes
+// "Intel Core i7-8700". Names without such noise, like "Apple M2 Pro", are
+// returned unchanged. If nothing would be left, the trimmed raw string is
+// returned instead.
+func NormalizeCPUModel(raw string) string {
+	s := reCPUMarks.ReplaceAllString(raw, " ")
+	s = reCPUClock.ReplaceAllString(s, "")
+	s = reCPUWord.ReplaceAllString(s, " ")
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return strings.TrimSpace(raw)
+	}
+	return s
+}
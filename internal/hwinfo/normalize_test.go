@@ -0,0 +1,50 @@
+package hwinfo
+
+import "testing"
+
+func TestNormalizeCPUModel(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		// Intel
+		{"Intel(R) Core(TM) i7-8700 CPU @ 3.20GHz", "Intel Core i7-8700"},
+		{"Intel(R) Core(TM) i7-8700 CPU @ 3.19GHz", "Intel Core i7-8700"},
+		{"Intel(R) Xeon(R) Platinum 8370C CPU @ 2.80GHz", "Intel Xeon Platinum 8370C"},
+		{"Intel(R) Xeon(R) CPU E5-2673 v4 @ 2.30GHz", "Intel Xeon E5-2673 v4"},
+		{"12th Gen Intel(R) Core(TM) i7-12700H", "12th Gen Intel Core i7-12700H"},
+		{"Intel(R) Core(TM) Ultra 7 155H", "Intel Core Ultra 7 155H"},
+		{"Intel(R) Xeon(R) Processor", "Intel Xeon Processor"},
+		{"Intel® Core™ i5-1135G7 CPU @ 2.40 GHz", "Intel Core i5-1135G7"},
+		{"Intel(r) Pentium(tm) cpu G4560 @ 3500MHz", "Intel Pentium G4560"},
+		// AMD
+		{"AMD Ryzen 9 5950X 16-Core Processor", "AMD Ryzen 9 5950X 16-Core Processor"},
+		{"AMD EPYC 7763 64-Core Processor                ", "AMD EPYC 7763 64-Core Processor"},
+		{"AMD Ryzen 7 5800U with Radeon Graphics", "AMD Ryzen 7 5800U with Radeon Graphics"},
+		{"AMD Athlon(tm) II X2 250 Processor", "AMD Athlon II X2 250 Processor"},
+		// ARM
+		{"Neoverse-N1", "Neoverse-N1"},
+		{"Cortex-A72", "Cortex-A72"},
+		{"ARMv8 Processor rev 1 (v8l)", "ARMv8 Processor rev 1 (v8l)"},
+		// Apple silicon
+		{"Apple M1", "Apple M1"},
+		{"Apple M2 Pro", "Apple M2 Pro"},
+		{"VirtualApple @ 2.50GHz", "VirtualApple"},
+		// Virtual machines and edge cases
+		{"QEMU Virtual CPU version 2.5+", "QEMU Virtual version 2.5+"},
+		{"  Intel(R)   Core(TM)\ti5 ", "Intel Core i5"},
+		{"CPUID-less", "CPUID-less"},
+		{"CPU", "CPU"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got := NormalizeCPUModel(tt.raw)
+		if got != tt.want {
+			t.Errorf("NormalizeCPUModel(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+		if again := NormalizeCPUModel(got); again != got {
+			t.Errorf("NormalizeCPUModel(%q) = %q, not idempotent", got, again)
+		}
+	}
+}
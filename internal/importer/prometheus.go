@@ -0,0 +1,246 @@
+// Package importer reconstructs stored benchmark data from external sources.
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// Labels read by FromPromRange. A series must carry at least LabelBenchmark;
+// the others are optional.
+const (
+	LabelCGO       = "cgo"
+	LabelBenchmark = "benchmark"
+	LabelUnit      = "unit"
+	LabelPackage   = "package"
+	LabelProcs     = "procs"
+	LabelSHA       = "sha"
+	LabelCPU       = "cpu"
+	LabelGOOS      = "goos"
+	LabelGOARCH    = "goarch"
+	LabelGoVersion = "go_version"
+)
+
+// DefaultPromTimeout bounds an HTTPPromAPI query without its own Client.
+const DefaultPromTimeout = time.Minute
+
+// PromSample is one value of a Prometheus series.
+type PromSample struct {
+	Time  time.Time
+	Value float64
+}
+
+// PromSeries is one series of a Prometheus range query result.
+type PromSeries struct {
+	Labels  map[string]string
+	Samples []PromSample
+}
+
+// PromAPI runs Prometheus range queries.
+type PromAPI interface {
+	QueryRange(query string, start, end time.Time) ([]PromSeries, error)
+}
+
+// FromPromRange runs query over [start, end] and turns the returned samples
+// into branch data. Samples become results of the entry for their commit
+// (the "sha" label) or, without one, for their timestamp; the entry is dated
+// by its earliest sample, so the repeated evaluations of a range query
+// collapse into a single entry per commit. Run parameters are read from the
+// "cpu", "goos", "goarch", "go_version" and "cgo" labels, and the result from
+// "benchmark", "unit", "package" and "procs" (unit defaults to ns/op).
+//
+// Entries are returned oldest first.
+func FromPromRange(api PromAPI, query string, start, end time.Time) (model.BranchData, error) {
+	return FromPromRangeWithDefaults(api, query, start, end, model.RunParams{})
+}
+
+// FromPromRangeWithDefaults is FromPromRange taking each run parameter a
+// series carries no label for from defaults instead, e.g. the Go version
+// and CGO setting of sources that do not record them, so that the entries
+// share a series with the ones parse and store record.
+func FromPromRangeWithDefaults(api PromAPI, query string, start, end time.Time, defaults model.RunParams) (model.BranchData, error) {
+	series, err := api.QueryRange(query, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("querying prometheus: %w", err)
+	}
+
+	type entryKey struct {
+		sha    string
+		ts     int64
+		params model.RunParams
+	}
+	entries := make(map[entryKey]*model.BenchmarkEntry)
+	// seen avoids adding the same result twice to an entry when a range
+	// query repeats a commit's value at every step.
+	seen := make(map[entryKey]map[model.SeriesKey]struct{})
+
+	for _, s := range series {
+		name := s.Labels[LabelBenchmark]
+		if name == "" {
+			return nil, fmt.Errorf("series %v has no %q label", s.Labels, LabelBenchmark)
+		}
+		result := model.BenchmarkResult{
+			Name:    name,
+			Unit:    s.Labels[LabelUnit],
+			Package: s.Labels[LabelPackage],
+		}
+		if result.Unit == "" {
+			result.Unit = "ns/op"
+		}
+		if p := s.Labels[LabelProcs]; p != "" {
+			if result.Procs, err = strconv.Atoi(p); err != nil {
+				return nil, fmt.Errorf("series %v: invalid %q label: %w", s.Labels, LabelProcs, err)
+			}
+		}
+		params, err := promParams(s.Labels, defaults)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sample := range s.Samples {
+			ms := sample.Time.UnixMilli()
+			k := entryKey{sha: s.Labels[LabelSHA], params: params}
+			if k.sha == "" {
+				k.ts = ms
+			}
+			e, ok := entries[k]
+			if !ok {
+				e = &model.BenchmarkEntry{Commit: model.Commit{SHA: k.sha}, Date: ms, Params: params}
+				entries[k] = e
+				seen[k] = make(map[model.SeriesKey]struct{})
+			}
+			e.Date = min(e.Date, ms)
+
+			r := result
+			r.Value = sample.Value
+			if _, dup := seen[k][r.SeriesKey()]; dup {
+				continue
+			}
+			seen[k][r.SeriesKey()] = struct{}{}
+			e.Benchmarks = append(e.Benchmarks, r)
+		}
+	}
+
+	data := make(model.BranchData, 0, len(entries))
+	for _, e := range entries {
+		model.SortBenchmarks(e.Benchmarks)
+		data = append(data, *e)
+	}
+	sort.SliceStable(data, func(i, j int) bool {
+		if data[i].Date != data[j].Date {
+			return data[i].Date < data[j].Date
+		}
+		return data[i].Commit.SHA < data[j].Commit.SHA
+	})
+	return data, nil
+}
+
+// promParams returns the run parameters of a series with the given labels:
+// those it has a label for from the label, the others from defaults.
+func promParams(labels map[string]string, defaults model.RunParams) (model.RunParams, error) {
+	params := defaults
+	for label, field := range map[string]*string{
+		LabelCPU:       &params.CPU,
+		LabelGOOS:      &params.GOOS,
+		LabelGOARCH:    &params.GOARCH,
+		LabelGoVersion: &params.GoVersion,
+	} {
+		if v, ok := labels[label]; ok {
+			*field = v
+		}
+	}
+	if v, ok := labels[LabelCGO]; ok {
+		cgo, err := strconv.ParseBool(v)
+		if err != nil {
+			return model.RunParams{}, fmt.Errorf("series %v: invalid %q label: %w", labels, LabelCGO, err)
+		}
+		params.CGO = cgo
+	}
+	return params, nil
+}
+
+// HTTPPromAPI queries the HTTP API of Prometheus or a compatible server
+// such as Thanos.
+type HTTPPromAPI struct {
+	// BaseURL is the server address, e.g. "http://prometheus:9090".
+	BaseURL string
+	// Step is the query resolution step.
+	Step time.Duration
+	// Client defaults to a client with DefaultPromTimeout.
+	Client *http.Client
+}
+
+// promResponse is the body of /api/v1/query_range.
+type promResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string    `json:"metric"`
+			Values [][2]json.RawMessage `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// QueryRange implements PromAPI.
+func (a HTTPPromAPI) QueryRange(query string, start, end time.Time) ([]PromSeries, error) {
+	client := a.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultPromTimeout}
+	}
+	params := url.Values{
+		"query": {query},
+		"start": {strconv.FormatInt(start.Unix(), 10)},
+		"end":   {strconv.FormatInt(end.Unix(), 10)},
+		"step":  {strconv.FormatFloat(a.Step.Seconds(), 'f', -1, 64)},
+	}
+	resp, err := client.Get(a.BaseURL + "/api/v1/query_range?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body promResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if body.Status != "success" {
+		return nil, fmt.Errorf("query failed (HTTP %d): %s", resp.StatusCode, body.Error)
+	}
+	if body.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("unexpected result type %q, want matrix", body.Data.ResultType)
+	}
+
+	series := make([]PromSeries, len(body.Data.Result))
+	for i, r := range body.Data.Result {
+		series[i].Labels = r.Metric
+		for _, v := range r.Values {
+			var ts float64
+			var raw string
+			if err := json.Unmarshal(v[0], &ts); err != nil {
+				return nil, fmt.Errorf("decoding sample time: %w", err)
+			}
+			if err := json.Unmarshal(v[1], &raw); err != nil {
+				return nil, fmt.Errorf("decoding sample value: %w", err)
+			}
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("decoding sample value: %w", err)
+			}
+			series[i].Samples = append(series[i].Samples, PromSample{
+				Time:  time.UnixMilli(int64(math.Round(ts * 1000))),
+				Value: value,
+			})
+		}
+	}
+	return series, nil
+}
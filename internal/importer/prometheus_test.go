@@ -0,0 +1,132 @@
+package importer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// fakeProm returns canned series and records the query it was asked.
+type fakeProm struct {
+	series []PromSeries
+	query  string
+}
+
+func (f *fakeProm) QueryRange(query string, _, _ time.Time) ([]PromSeries, error) {
+	f.query = query
+	return f.series, nil
+}
+
+func TestFromPromRange(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	at := func(d time.Duration, v float64) PromSample { return PromSample{Time: t0.Add(d), Value: v} }
+	labels := func(sha, name, unit string) map[string]string {
+		return map[string]string{
+			LabelBenchmark: name, LabelUnit: unit, LabelSHA: sha, LabelProcs: "8",
+			LabelGOOS: "linux", LabelGOARCH: "amd64", LabelCPU: "Test CPU",
+		}
+	}
+
+	api := &fakeProm{series: []PromSeries{
+		// The range query repeats each commit's value at every step.
+		{Labels: labels("aaa", "BenchmarkFoo", "ns/op"), Samples: []PromSample{at(0, 100), at(time.Minute, 100)}},
+		{Labels: labels("aaa", "BenchmarkFoo - B/op", "B/op"), Samples: []PromSample{at(time.Minute, 64)}},
+		{Labels: labels("bbb", "BenchmarkFoo", "ns/op"), Samples: []PromSample{at(time.Hour, 90)}},
+	}}
+
+	data, err := FromPromRange(api, `go_benchmark{branch="main"}`, t0, t0.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("FromPromRange() error: %v", err)
+	}
+	if api.query != `go_benchmark{branch="main"}` {
+		t.Errorf("got query %q", api.query)
+	}
+	if len(data) != 2 {
+		t.Fatalf("got %d entries, want 2", len(data))
+	}
+
+	first := data[0]
+	if first.Commit.SHA != "aaa" || first.Date != t0.UnixMilli() {
+		t.Errorf("first entry: got sha %q date %d, want aaa %d", first.Commit.SHA, first.Date, t0.UnixMilli())
+	}
+	if first.Params.GOOS != "linux" || first.Params.CPU != "Test CPU" {
+		t.Errorf("first entry params: got %+v", first.Params)
+	}
+	if len(first.Benchmarks) != 2 {
+		t.Fatalf("first entry: got %d results, want 2", len(first.Benchmarks))
+	}
+	if r := first.Benchmarks[0]; r.Name != "BenchmarkFoo" || r.Value != 100 || r.Procs != 8 {
+		t.Errorf("first result: got %+v", r)
+	}
+	if data[1].Commit.SHA != "bbb" || data[1].Benchmarks[0].Value != 90 {
+		t.Errorf("second entry: got %s %+v", data[1].Commit.SHA, data[1].Benchmarks)
+	}
+}
+
+func TestFromPromRangeWithDefaults(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	api := &fakeProm{series: []PromSeries{
+		{Labels: map[string]string{LabelBenchmark: "BenchmarkFoo", LabelSHA: "aaa", LabelGOOS: "linux"}, Samples: []PromSample{{Time: t0, Value: 1}}},
+		{Labels: map[string]string{LabelBenchmark: "BenchmarkFoo", LabelSHA: "bbb", LabelGOOS: "", LabelGoVersion: "go1.21.0", LabelCGO: "false"}, Samples: []PromSample{{Time: t0, Value: 2}}},
+	}}
+	defaults := model.RunParams{GOOS: "darwin", GoVersion: "go1.22.1", CGO: true}
+
+	data, err := FromPromRangeWithDefaults(api, "q", t0, t0, defaults)
+	if err != nil {
+		t.Fatalf("FromPromRangeWithDefaults() error: %v", err)
+	}
+	want := map[string]model.RunParams{
+		"aaa": {GOOS: "linux", GoVersion: "go1.22.1", CGO: true},
+		"bbb": {GoVersion: "go1.21.0"},
+	}
+	if len(data) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(data), len(want))
+	}
+	for _, e := range data {
+		if e.Params != want[e.Commit.SHA] {
+			t.Errorf("%s: got params %+v, want %+v", e.Commit.SHA, e.Params, want[e.Commit.SHA])
+		}
+	}
+
+	api.series[0].Labels[LabelCGO] = "maybe"
+	if _, err := FromPromRangeWithDefaults(api, "q", t0, t0, defaults); err == nil {
+		t.Error("expected an error for an invalid cgo label")
+	}
+}
+
+func TestFromPromRange_MissingBenchmarkLabel(t *testing.T) {
+	api := &fakeProm{series: []PromSeries{{Labels: map[string]string{"unit": "ns/op"}}}}
+	if _, err := FromPromRange(api, "q", time.Time{}, time.Time{}); err == nil {
+		t.Error("expected error for a series without a benchmark label")
+	}
+}
+
+func TestHTTPPromAPI_QueryRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" || r.URL.Query().Get("step") != "60" {
+			http.Error(w, "unexpected request "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"benchmark":"BenchmarkFoo"},"values":[[1700000000.5,"41.5"],[1700000060,"42"]]}
+		]}}`)
+	}))
+	defer srv.Close()
+
+	api := HTTPPromAPI{BaseURL: srv.URL, Step: time.Minute}
+	series, err := api.QueryRange("go_benchmark", time.Unix(1700000000, 0), time.Unix(1700000060, 0))
+	if err != nil {
+		t.Fatalf("QueryRange() error: %v", err)
+	}
+	if len(series) != 1 || len(series[0].Samples) != 2 {
+		t.Fatalf("got %+v, want 1 series with 2 samples", series)
+	}
+	s := series[0].Samples[0]
+	if s.Value != 41.5 || s.Time.UnixMilli() != 1700000000500 {
+		t.Errorf("got sample %v at %d, want 41.5 at 1700000000500", s.Value, s.Time.UnixMilli())
+	}
+}
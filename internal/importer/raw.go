@@ -0,0 +1,131 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/parse"
+)
+
+// DefaultSHAPattern matches the last run of 7 to 40 hex digits in a file
+// name as an abbreviated or full commit SHA, so that a leading date such as
+// "20240101_abc1234.txt" is not mistaken for one.
+const DefaultSHAPattern = `^.*(?:^|[^0-9a-f])(?P<sha>[0-9a-f]{7,40})(?:[^0-9a-f]|$)`
+
+// rawDateLayouts are the layouts tried for the "date" group of a SHA
+// pattern.
+var rawDateLayouts = []string{time.RFC3339, "2006-01-02T150405Z0700", "2006-01-02"}
+
+// FromRawFiles builds one entry per archived `go test -bench` output file
+// matching glob, for backfilling a dashboard from raw logs.
+//
+// The commit of a file is derived from its base name with shaPattern, a
+// regular expression: the "sha" named group if present, else the first
+// group, else the whole match. An optional "date" group (RFC 3339,
+// 2006-01-02T150405Z or 2006-01-02) dates the commit; without one the
+// file's modification time is used. An optional "branch" group names the
+// branch a file was recorded on, for archives that mix branches: files of
+// other branches than branch are skipped. Run parameters come from the
+// goos:, goarch: and cpu: lines of the output.
+//
+// Files whose name does not match shaPattern are an error; files without
+// any benchmark result are skipped. Entries are returned oldest first.
+func FromRawFiles(glob, shaPattern, branch string) ([]model.BenchmarkEntry, error) {
+	re, err := regexp.Compile(shaPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SHA pattern: %w", err)
+	}
+	paths, err := filepath.Glob(glob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+	}
+
+	var entries []model.BenchmarkEntry
+	for _, path := range paths {
+		entry, ok, err := rawEntry(path, re, branch)
+		if err != nil {
+			return nil, fmt.Errorf("importing %s: %w", path, err)
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Date != entries[j].Date {
+			return entries[i].Date < entries[j].Date
+		}
+		return entries[i].Commit.SHA < entries[j].Commit.SHA
+	})
+	return entries, nil
+}
+
+// rawEntry parses one output file. ok is false if it holds no results or
+// belongs to another branch than branch.
+func rawEntry(path string, re *regexp.Regexp, branch string) (entry model.BenchmarkEntry, ok bool, err error) {
+	name := filepath.Base(path)
+	m := re.FindStringSubmatch(name)
+	if m == nil {
+		return entry, false, fmt.Errorf("file name %q does not match the SHA pattern", name)
+	}
+	sha := m[0]
+	if i := re.SubexpIndex("sha"); i > 0 {
+		sha = m[i]
+	} else if len(m) > 1 {
+		sha = m[1]
+	}
+	if sha == "" {
+		return entry, false, fmt.Errorf("empty SHA in file name %q", name)
+	}
+	if i := re.SubexpIndex("branch"); i > 0 && m[i] != "" && m[i] != branch {
+		return entry, false, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return entry, false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return entry, false, err
+	}
+
+	date := info.ModTime().UTC()
+	if i := re.SubexpIndex("date"); i > 0 && m[i] != "" {
+		if date, err = parseRawDate(m[i]); err != nil {
+			return entry, false, err
+		}
+	}
+
+	results, meta, err := parse.ParseGoBenchOutputWithMeta(f)
+	if errors.Is(err, parse.ErrNoResults) {
+		return entry, false, nil
+	}
+	if err != nil {
+		return entry, false, err
+	}
+
+	return model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: sha, Date: date.Format(time.RFC3339)},
+		Date:       date.UnixMilli(),
+		Params:     model.RunParams{CPU: meta.CPU, GOOS: meta.GOOS, GOARCH: meta.GOARCH},
+		Benchmarks: results,
+	}, true, nil
+}
+
+// parseRawDate parses s with the first matching rawDateLayouts entry.
+func parseRawDate(s string) (time.Time, error) {
+	for _, layout := range rawDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q in file name", s)
+}
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+const fixturePattern = `^(?P<date>\d{4}-\d{2}-\d{2})_(?P<sha>[0-9a-f]+)\.txt$`
+
+func TestFromRawFiles(t *testing.T) {
+	entries, err := FromRawFiles("testdata/raw/*.txt", fixturePattern, "main")
+	if err != nil {
+		t.Fatalf("FromRawFiles() error: %v", err)
+	}
+
+	// ddd4444 has no results and is skipped; the rest are oldest first.
+	var shas []string
+	for _, e := range entries {
+		shas = append(shas, e.Commit.SHA)
+	}
+	want := []string{"aaa1111", "bbb2222", "ccc3333"}
+	if len(shas) != len(want) {
+		t.Fatalf("got commits %v, want %v", shas, want)
+	}
+	for i := range want {
+		if shas[i] != want[i] {
+			t.Fatalf("got commits %v, want %v", shas, want)
+		}
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Date <= entries[i-1].Date {
+			t.Errorf("entries not in chronological order: %d then %d", entries[i-1].Date, entries[i].Date)
+		}
+	}
+
+	first := entries[0]
+	if first.Commit.Date != "2024-01-01T00:00:00Z" || first.Date != time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli() {
+		t.Errorf("date: got %q / %d", first.Commit.Date, first.Date)
+	}
+	if first.Params.GOOS != "linux" || first.Params.GOARCH != "amd64" || first.Params.CPU != "Intel(R) Xeon(R) CPU @ 2.20GHz" {
+		t.Errorf("params: got %+v", first.Params)
+	}
+	if len(first.Benchmarks) != 3 || first.Benchmarks[0].Value != 1000 {
+		t.Errorf("benchmarks: got %+v", first.Benchmarks)
+	}
+}
+
+func TestFromRawFiles_ModTimeAndErrors(t *testing.T) {
+	dir := t.TempDir()
+	output := []byte("BenchmarkFoo-8   100   5 ns/op\nPASS\n")
+	for i, name := range []string{"run-abcdef1.log", "run-0123456.log"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, output, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Date(2023, 6, 2-i, 12, 0, 0, 0, time.UTC)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := FromRawFiles(filepath.Join(dir, "*.log"), DefaultSHAPattern, "main")
+	if err != nil {
+		t.Fatalf("FromRawFiles() error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Commit.SHA != "0123456" || entries[1].Commit.SHA != "abcdef1" {
+		t.Fatalf("without a date group entries should be ordered by mtime, got %+v", entries)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README.log"), output, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromRawFiles(filepath.Join(dir, "*.log"), DefaultSHAPattern, "main"); err == nil {
+		t.Error("expected an error for a file name without a SHA")
+	}
+	if _, err := FromRawFiles(filepath.Join(dir, "*.log"), "(", "main"); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestDefaultSHAPattern(t *testing.T) {
+	re := regexp.MustCompile(DefaultSHAPattern)
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"abc1234.txt", "abc1234"},
+		{"run-abcdef1.log", "abcdef1"},
+		{"20240101_abc1234.txt", "abc1234"},
+		{"2024-01-01_0123456789abcdef0123456789abcdef01234567.txt", "0123456789abcdef0123456789abcdef01234567"},
+		{"bench.txt", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if m := re.FindStringSubmatch(tt.name); m != nil {
+			got = m[re.SubexpIndex("sha")]
+		}
+		if got != tt.want {
+			t.Errorf("%s: got SHA %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFromRawFiles_BranchGroup(t *testing.T) {
+	dir := t.TempDir()
+	output := []byte("BenchmarkFoo-8   100   5 ns/op\nPASS\n")
+	for _, name := range []string{"main_abc1234.txt", "dev_def5678.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), output, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := FromRawFiles(filepath.Join(dir, "*.txt"), `^(?P<branch>[a-z]+)_(?P<sha>[0-9a-f]+)\.txt$`, "dev")
+	if err != nil {
+		t.Fatalf("FromRawFiles() error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Commit.SHA != "def5678" {
+		t.Errorf("got %+v, want only the entry of branch dev", entries)
+	}
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	// BinarySizeName is the benchmark name of results from BinarySizeResult.
+	BinarySizeName = "BinarySize"
+	// BinarySizeUnit is the unit of results from BinarySizeResult.
+	BinarySizeUnit = "bytes"
+)
+
+// BinarySizeResult returns the size of the file at path as a synthetic
+// benchmark result, so that binary growth is charted and gated like any
+// other metric. It fails if path does not name a regular file.
+func BinarySizeResult(path string) (BenchmarkResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return BenchmarkResult{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return BenchmarkResult{}, fmt.Errorf("%s is not a regular file", path)
+	}
+	return BenchmarkResult{Name: BinarySizeName, Value: float64(info.Size()), Unit: BinarySizeUnit}, nil
+}
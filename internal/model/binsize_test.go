@@ -0,0 +1,33 @@
+package model
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBinarySizeResult(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pkg.test")
+	if err := os.WriteFile(path, make([]byte, 12345), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := BinarySizeResult(path)
+	if err != nil {
+		t.Fatalf("BinarySizeResult() error: %v", err)
+	}
+	want := BenchmarkResult{Name: "BinarySize", Value: 12345, Unit: "bytes"}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := BinarySizeResult(filepath.Join(dir, "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: got error %v, want fs.ErrNotExist", err)
+	}
+	if _, err := BinarySizeResult(dir); err == nil {
+		t.Error("directory: expected an error")
+	}
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Direction says whether a smaller or a larger value of a unit is an
+// improvement.
+type Direction int
+
+const (
+	// DirectionUnknown is returned for units without a known direction.
+	// Callers treat it as lower-is-better but may warn about it.
+	DirectionUnknown Direction = iota
+	// LowerIsBetter is the direction of costs like ns/op, B/op and
+	// allocs/op.
+	LowerIsBetter
+	// HigherIsBetter is the direction of throughputs like MB/s and ops/s.
+	HigherIsBetter
+)
+
+// String returns "unknown", "lower" or "higher".
+func (d Direction) String() string {
+	switch d {
+	case LowerIsBetter:
+		return "lower"
+	case HigherIsBetter:
+		return "higher"
+	default:
+		return "unknown"
+	}
+}
+
+// Higher reports whether a larger value is an improvement. It is false for
+// DirectionUnknown, which defaults to lower-is-better.
+func (d Direction) Higher() bool {
+	return d == HigherIsBetter
+}
+
+var (
+	directionsMu sync.RWMutex
+	// directions is the built-in table of unit directions, extended by
+	// RegisterUnitDirection.
+	directions = map[string]Direction{
+		"ns/op":     LowerIsBetter,
+		"B/op":      LowerIsBetter,
+		"allocs/op": LowerIsBetter,
+		"bytes":     LowerIsBetter,
+		"MB/s":      HigherIsBetter,
+		"B/s":       HigherIsBetter,
+		"ops/s":     HigherIsBetter,
+	}
+)
+
+// standardUnits are the units go test itself reports. Any other unit comes
+// from b.ReportMetric.
+var standardUnits = []string{"ns/op", "B/op", "allocs/op", "MB/s"}
+
+// IsStandardUnit reports whether unit is one of the units go test reports
+// on its own (ns/op, B/op, allocs/op, MB/s), as opposed to a custom metric
+// from b.ReportMetric. All of them have a built-in direction.
+func IsStandardUnit(unit string) bool {
+	return slices.Contains(standardUnits, unit)
+}
+
+// UnitDirection returns the direction of unit: a registered or built-in
+// direction, else HigherIsBetter for any other per-second rate ("/s"
+// suffix) and DirectionUnknown for the rest.
+func UnitDirection(unit string) Direction {
+	directionsMu.RLock()
+	d, ok := directions[unit]
+	directionsMu.RUnlock()
+	if ok {
+		return d
+	}
+	if strings.HasSuffix(unit, "/s") {
+		return HigherIsBetter
+	}
+	return DirectionUnknown
+}
+
+// RegisterUnitDirection sets the direction of a custom unit, e.g. one
+// reported with b.ReportMetric, overriding any built-in entry.
+func RegisterUnitDirection(unit string, d Direction) {
+	directionsMu.Lock()
+	defer directionsMu.Unlock()
+	directions[unit] = d
+}
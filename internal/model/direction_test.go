@@ -0,0 +1,52 @@
+package model
+
+import "testing"
+
+func TestUnitDirection(t *testing.T) {
+	tests := []struct {
+		unit string
+		want Direction
+	}{
+		{"ns/op", LowerIsBetter},
+		{"B/op", LowerIsBetter},
+		{"allocs/op", LowerIsBetter},
+		{"MB/s", HigherIsBetter},
+		{"ops/s", HigherIsBetter},
+		{"requests/s", HigherIsBetter},
+		{"p99-ns", DirectionUnknown},
+	}
+	for _, tt := range tests {
+		if got := UnitDirection(tt.unit); got != tt.want {
+			t.Errorf("UnitDirection(%q) = %v, want %v", tt.unit, got, tt.want)
+		}
+	}
+	if UnitDirection("p99-ns").Higher() {
+		t.Error("unknown units should default to lower-is-better")
+	}
+
+	RegisterUnitDirection("hits/op", HigherIsBetter)
+	t.Cleanup(func() {
+		directionsMu.Lock()
+		delete(directions, "hits/op")
+		directionsMu.Unlock()
+	})
+	if got := UnitDirection("hits/op"); got != HigherIsBetter {
+		t.Errorf("registered unit: got %v, want higher", got)
+	}
+}
+
+func TestIsStandardUnit(t *testing.T) {
+	for _, unit := range standardUnits {
+		if !IsStandardUnit(unit) {
+			t.Errorf("IsStandardUnit(%q) = false, want true", unit)
+		}
+		if UnitDirection(unit) == DirectionUnknown {
+			t.Errorf("standard unit %q has no built-in direction", unit)
+		}
+	}
+	for _, unit := range []string{"frames/sec", "MB", "req/s", ""} {
+		if IsStandardUnit(unit) {
+			t.Errorf("IsStandardUnit(%q) = true, want false", unit)
+		}
+	}
+}
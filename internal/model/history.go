@@ -0,0 +1,102 @@
+package model
+
+// SeriesKey identifies one metric series of one benchmark. Within entries
+// that share the same ConfigKey, results with equal SeriesKey values are
+// comparable across commits.
+type SeriesKey struct {
+	Package string
+	Name    string
+	Unit    string
+	Procs   int
+}
+
+// SeriesKey returns the series this result belongs to.
+func (r BenchmarkResult) SeriesKey() SeriesKey {
+	return SeriesKey{
+		Package: r.Package,
+		Name:    r.Name,
+		Unit:    r.Unit,
+		Procs:   r.Procs,
+	}
+}
+
+// HistoryPoint is the value of a single benchmark series at one commit.
+//
+// Smoothed is only set by queries that request smoothing and holds the
+// moving average ending at this point.
+type HistoryPoint struct {
+	SHA      string   `json:"sha"`
+	Date     int64    `json:"date"`
+	Value    float64  `json:"value"`
+	Unit     string   `json:"unit"`
+	Smoothed *float64 `json:"smoothed,omitempty"`
+}
+
+// Point returns the HistoryPoint for result r recorded in entry e.
+func (e BenchmarkEntry) Point(r BenchmarkResult) HistoryPoint {
+	return HistoryPoint{
+		SHA:   e.Commit.SHA,
+		Date:  e.Date,
+		Value: r.Value,
+		Unit:  r.Unit,
+	}
+}
+
+// History returns the points of the series identified by key, taken from
+// entries whose ConfigKey equals config, in the order the entries appear in
+// d (chronological for data read from storage).
+func (d BranchData) History(config EntryKeyValue, key SeriesKey) []HistoryPoint {
+	var points []HistoryPoint
+	for _, e := range d {
+		if e.ConfigKey() != config {
+			continue
+		}
+		for _, r := range e.Benchmarks {
+			if r.SeriesKey() == key {
+				points = append(points, e.Point(r))
+				break
+			}
+		}
+	}
+	return points
+}
+
+// SeriesID identifies a benchmark series together with the run
+// configuration (see BenchmarkEntry.ConfigKey) it was measured on.
+type SeriesID struct {
+	Config EntryKeyValue
+	Key    SeriesKey
+}
+
+// SeriesIDs returns every distinct series found in d, in the order of first
+// appearance.
+func (d BranchData) SeriesIDs() []SeriesID {
+	seen := make(map[SeriesID]struct{})
+	var ids []SeriesID
+	for _, e := range d {
+		for _, r := range e.Benchmarks {
+			id := SeriesID{Config: e.ConfigKey(), Key: r.SeriesKey()}
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// PreviousComparable returns the newest entry in d that was measured with the
+// same RunParams and experiment tags as e and is not dated after it, or nil if
+// there is none. Entries of the same commit count as comparable.
+func (d BranchData) PreviousComparable(e BenchmarkEntry) *BenchmarkEntry {
+	config := e.ConfigKey()
+	for i := len(d) - 1; i >= 0; i-- {
+		c := d[i]
+		if c.ConfigKey() != config || c.Date > e.Date {
+			continue
+		}
+		return &d[i]
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBranchData_History(t *testing.T) {
+	linux := RunParams{CPU: "Intel Xeon", GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.22.0", CGO: true}
+	darwin := RunParams{CPU: "Apple M1", GOOS: "darwin", GOARCH: "arm64", GoVersion: "go1.22.0"}
+
+	data := BranchData{
+		{
+			Commit: Commit{SHA: "aaa"}, Date: 1, Params: linux,
+			Benchmarks: []BenchmarkResult{
+				{Name: "BenchmarkFoo", Value: 100, Unit: "ns/op", Procs: 8},
+				{Name: "BenchmarkFoo - B/op", Value: 64, Unit: "B/op", Procs: 8},
+			},
+		},
+		{
+			Commit: Commit{SHA: "bbb"}, Date: 2, Params: darwin,
+			Benchmarks: []BenchmarkResult{
+				{Name: "BenchmarkFoo", Value: 50, Unit: "ns/op", Procs: 8},
+			},
+		},
+		{
+			Commit: Commit{SHA: "ccc"}, Date: 3, Params: linux,
+			Benchmarks: []BenchmarkResult{
+				{Name: "BenchmarkFoo", Value: 110, Unit: "ns/op", Procs: 8},
+				{Name: "BenchmarkFoo", Value: 400, Unit: "ns/op", Procs: 1},
+			},
+		},
+		{
+			// Same params but an experiment tag: a different configuration.
+			Commit: Commit{SHA: "ddd"}, Date: 4, Params: linux, Tags: map[string]string{"alloc": "arena"},
+			Benchmarks: []BenchmarkResult{
+				{Name: "BenchmarkFoo", Value: 10, Unit: "ns/op", Procs: 8},
+			},
+		},
+	}
+
+	got := data.History(EntryKeyValue{Params: linux}, SeriesKey{Name: "BenchmarkFoo", Unit: "ns/op", Procs: 8})
+	want := []HistoryPoint{
+		{SHA: "aaa", Date: 1, Value: 100, Unit: "ns/op"},
+		{SHA: "ccc", Date: 3, Value: 110, Unit: "ns/op"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("History() = %+v, want %+v", got, want)
+	}
+
+	if got := data.History(EntryKeyValue{Params: linux}, SeriesKey{Name: "BenchmarkMissing", Unit: "ns/op", Procs: 8}); got != nil {
+		t.Errorf("expected nil history for unknown series, got %+v", got)
+	}
+}
+
+func TestBranchData_SeriesIDs(t *testing.T) {
+	linux := RunParams{GOOS: "linux"}
+	darwin := RunParams{GOOS: "darwin"}
+
+	data := BranchData{
+		{Params: linux, Benchmarks: []BenchmarkResult{
+			{Name: "BenchmarkA", Unit: "ns/op"},
+			{Name: "BenchmarkB", Unit: "ns/op"},
+		}},
+		{Params: darwin, Benchmarks: []BenchmarkResult{
+			{Name: "BenchmarkA", Unit: "ns/op"},
+		}},
+		{Params: linux, Benchmarks: []BenchmarkResult{
+			{Name: "BenchmarkB", Unit: "ns/op"},
+			{Name: "BenchmarkA", Unit: "ns/op"},
+		}},
+	}
+
+	want := []SeriesID{
+		{Config: EntryKeyValue{Params: linux}, Key: SeriesKey{Name: "BenchmarkA", Unit: "ns/op"}},
+		{Config: EntryKeyValue{Params: linux}, Key: SeriesKey{Name: "BenchmarkB", Unit: "ns/op"}},
+		{Config: EntryKeyValue{Params: darwin}, Key: SeriesKey{Name: "BenchmarkA", Unit: "ns/op"}},
+	}
+	if got := data.SeriesIDs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SeriesIDs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPreviousComparable(t *testing.T) {
+	linux := RunParams{GOOS: "linux", GOARCH: "amd64"}
+	darwin := RunParams{GOOS: "darwin", GOARCH: "arm64"}
+	data := BranchData{
+		{Commit: Commit{SHA: "a"}, Date: 100, Params: linux},
+		{Commit: Commit{SHA: "b"}, Date: 200, Params: darwin},
+		{Commit: Commit{SHA: "c"}, Date: 300, Params: linux, Tags: map[string]string{"gc": "off"}},
+		{Commit: Commit{SHA: "d"}, Date: 400, Params: linux},
+	}
+
+	tests := []struct {
+		name  string
+		entry BenchmarkEntry
+		want  string
+	}{
+		{"newest same params", BenchmarkEntry{Date: 500, Params: linux}, "d"},
+		{"skips later entries", BenchmarkEntry{Date: 350, Params: linux}, "a"},
+		{"matches tags", BenchmarkEntry{Date: 500, Params: linux, Tags: map[string]string{"gc": "off"}}, "c"},
+		{"other params", BenchmarkEntry{Date: 500, Params: darwin}, "b"},
+		{"none", BenchmarkEntry{Date: 50, Params: linux}, ""},
+	}
+	for _, tt := range tests {
+		got := data.PreviousComparable(tt.entry)
+		switch {
+		case tt.want == "" && got != nil:
+			t.Errorf("%s: got %q, want nil", tt.name, got.Commit.SHA)
+		case tt.want != "" && (got == nil || got.Commit.SHA != tt.want):
+			t.Errorf("%s: got %v, want %q", tt.name, got, tt.want)
+		}
+	}
+}
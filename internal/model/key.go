@@ -0,0 +1,110 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyConfig selects which dimensions make up an entry's deduplication key.
+// Dimensions that are off are zeroed in the key, so entries differing only in
+// them are treated as the same run.
+type KeyConfig struct {
+	SHA       bool
+	CPU       bool
+	GOOS      bool
+	GOARCH    bool
+	MicroArch bool
+	GoVersion bool
+	CGO       bool
+	Dataset   bool
+	Tags      bool
+	Env       bool
+}
+
+// DefaultKeyConfig is the key used by EntryKey: every dimension except the
+// captured environment.
+var DefaultKeyConfig = KeyConfig{
+	SHA: true, CPU: true, GOOS: true, GOARCH: true, MicroArch: true, GoVersion: true, CGO: true, Dataset: true, Tags: true,
+}
+
+// keyNames maps the names accepted by ParseKeyConfig to their KeyConfig
+// field.
+var keyNames = map[string]func(*KeyConfig) *bool{
+	"sha":       func(c *KeyConfig) *bool { return &c.SHA },
+	"cpu":       func(c *KeyConfig) *bool { return &c.CPU },
+	"goos":      func(c *KeyConfig) *bool { return &c.GOOS },
+	"goarch":    func(c *KeyConfig) *bool { return &c.GOARCH },
+	"microarch": func(c *KeyConfig) *bool { return &c.MicroArch },
+	"goversion": func(c *KeyConfig) *bool { return &c.GoVersion },
+	"cgo":       func(c *KeyConfig) *bool { return &c.CGO },
+	"dataset":   func(c *KeyConfig) *bool { return &c.Dataset },
+	"tags":      func(c *KeyConfig) *bool { return &c.Tags },
+	"env":       func(c *KeyConfig) *bool { return &c.Env },
+}
+
+// ParseKeyConfig parses a comma-separated list of key dimensions, e.g.
+// "sha,cpu,goos,goarch,microarch,goversion,cgo,dataset,tags". Names are
+// case-insensitive; unknown names are an error.
+func ParseKeyConfig(s string) (KeyConfig, error) {
+	return KeyConfig{}.set(s, true)
+}
+
+// Without returns c with the comma-separated dimensions in s turned off,
+// e.g. DefaultKeyConfig.Without("cpu") for cloud runners whose CPU model
+// string varies from run to run. Names are parsed as in ParseKeyConfig.
+func (c KeyConfig) Without(s string) (KeyConfig, error) {
+	return c.set(s, false)
+}
+
+// set returns c with the comma-separated dimensions in s set to on.
+func (c KeyConfig) set(s string, on bool) (KeyConfig, error) {
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		field, ok := keyNames[name]
+		if !ok {
+			return KeyConfig{}, fmt.Errorf("unknown key dimension %q (valid: sha, cpu, goos, goarch, microarch, goversion, cgo, dataset, tags, env)", name)
+		}
+		*field(&c) = on
+	}
+	return c, nil
+}
+
+// KeyWith returns the entry's key restricted to the dimensions enabled in
+// cfg. The result is comparable like EntryKey.
+func (e BenchmarkEntry) KeyWith(cfg KeyConfig) EntryKeyValue {
+	var k EntryKeyValue
+	if cfg.SHA {
+		k.SHA = e.Commit.SHA
+	}
+	if cfg.CPU {
+		k.Params.CPU = e.Params.CPU
+	}
+	if cfg.GOOS {
+		k.Params.GOOS = e.Params.GOOS
+	}
+	if cfg.GOARCH {
+		k.Params.GOARCH = e.Params.GOARCH
+	}
+	if cfg.MicroArch {
+		k.Params.MicroArch = e.Params.MicroArch
+	}
+	if cfg.GoVersion {
+		k.Params.GoVersion = e.Params.GoVersion
+	}
+	if cfg.CGO {
+		k.Params.CGO = e.Params.CGO
+	}
+	if cfg.Dataset {
+		k.Params.DatasetHash = e.Params.DatasetHash
+	}
+	if cfg.Tags {
+		k.Tags = CanonicalTags(e.Tags)
+	}
+	if cfg.Env {
+		k.Env = CanonicalTags(e.Environment)
+	}
+	return k
+}
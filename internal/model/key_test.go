@@ -0,0 +1,105 @@
+package model
+
+import "testing"
+
+func TestParseKeyConfig(t *testing.T) {
+	cfg, err := ParseKeyConfig("sha, CPU,goversion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (KeyConfig{SHA: true, CPU: true, GoVersion: true}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+
+	full, err := ParseKeyConfig("sha,cpu,goos,goarch,microarch,goversion,cgo,dataset,tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if full != DefaultKeyConfig {
+		t.Errorf("full key list should equal DefaultKeyConfig, got %+v", full)
+	}
+
+	if _, err := ParseKeyConfig("sha,hostname"); err == nil {
+		t.Error("expected error for unknown dimension")
+	}
+}
+
+func TestKeyConfigWithout(t *testing.T) {
+	run := func(sha, cpu, goVersion string) BenchmarkEntry {
+		return BenchmarkEntry{
+			Commit: Commit{SHA: sha},
+			Params: RunParams{CPU: cpu, GOOS: "linux", GOARCH: "amd64", GoVersion: goVersion},
+		}
+	}
+	a := run("abc", "AMD EPYC 7763", "go1.22.0")
+
+	tests := []struct {
+		ignore string
+		other  BenchmarkEntry
+		same   bool
+	}{
+		{"", run("abc", "AMD EPYC 9V74", "go1.22.0"), false},
+		{"cpu", run("abc", "AMD EPYC 9V74", "go1.22.0"), true},
+		{"cpu", run("abc", "AMD EPYC 9V74", "go1.23.0"), false},
+		{"cpu", run("def", "AMD EPYC 9V74", "go1.22.0"), false},
+		{"goversion", run("abc", "AMD EPYC 7763", "go1.23.0"), true},
+		{"goversion", run("abc", "AMD EPYC 9V74", "go1.23.0"), false},
+		{"cpu, GoVersion", run("abc", "AMD EPYC 9V74", "go1.23.0"), true},
+	}
+	for _, tt := range tests {
+		cfg, err := DefaultKeyConfig.Without(tt.ignore)
+		if err != nil {
+			t.Fatalf("Without(%q) error: %v", tt.ignore, err)
+		}
+		if same := a.KeyWith(cfg) == tt.other.KeyWith(cfg); same != tt.same {
+			t.Errorf("Without(%q): %+v vs %+v same = %v, want %v", tt.ignore, a.Params, tt.other.Params, same, tt.same)
+		}
+	}
+
+	cfg, err := DefaultKeyConfig.Without("cpu,goversion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CPU || cfg.GoVersion || !cfg.SHA || !cfg.GOOS || !cfg.Tags {
+		t.Errorf("got %+v, want only cpu and goversion off", cfg)
+	}
+	if _, err := DefaultKeyConfig.Without("hostname"); err == nil {
+		t.Error("expected error for unknown dimension")
+	}
+}
+
+func TestKeyWith(t *testing.T) {
+	base := BenchmarkEntry{
+		Commit:      Commit{SHA: "abc"},
+		Params:      RunParams{CPU: "Intel", GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.22.0", CGO: true},
+		Tags:        map[string]string{"gc": "off"},
+		Environment: map[string]string{"REGION": "eu"},
+	}
+	other := BenchmarkEntry{
+		Commit:      Commit{SHA: "abc"},
+		Params:      RunParams{CPU: "AMD", GOOS: "darwin", GOARCH: "arm64", GoVersion: "go1.23.0"},
+		Environment: map[string]string{"REGION": "us"},
+	}
+
+	shaOnly := KeyConfig{SHA: true}
+	if base.KeyWith(shaOnly) != other.KeyWith(shaOnly) {
+		t.Error("sha-only key should ignore every other dimension")
+	}
+
+	maximal := DefaultKeyConfig
+	maximal.Env = true
+	if base.KeyWith(maximal) == other.KeyWith(maximal) {
+		t.Error("maximal key should distinguish differing entries")
+	}
+	envOnlyDiff := base
+	envOnlyDiff.Environment = map[string]string{"REGION": "us"}
+	if base.KeyWith(maximal) == envOnlyDiff.KeyWith(maximal) {
+		t.Error("maximal key should include the environment")
+	}
+	if base.KeyWith(DefaultKeyConfig) != envOnlyDiff.KeyWith(DefaultKeyConfig) {
+		t.Error("default key should ignore the environment")
+	}
+	if base.EntryKey() != base.KeyWith(DefaultKeyConfig) {
+		t.Error("EntryKey should equal KeyWith(DefaultKeyConfig)")
+	}
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// CanonicalName reorders the key=value segments of a sub-benchmark name into
+// sorted order, so that "BenchmarkX/b=2/a=1" and "BenchmarkX/a=1/b=2" map to
+// the same series. Segments without '=' keep their position; the key=value
+// segments are sorted among the slots they occupy. A " - unit" suffix added
+// by the parser is left untouched.
+func CanonicalName(name string) string {
+	base, suffix := name, ""
+	if i := strings.LastIndex(name, " - "); i >= 0 {
+		base, suffix = name[:i], name[i:]
+	}
+
+	segments := strings.Split(base, "/")
+	var slots []int
+	var kv []string
+	for i, seg := range segments {
+		if i > 0 && strings.Contains(seg, "=") {
+			slots = append(slots, i)
+			kv = append(kv, seg)
+		}
+	}
+	if len(kv) < 2 {
+		return name
+	}
+
+	sort.Strings(kv)
+	for j, i := range slots {
+		segments[i] = kv[j]
+	}
+	return strings.Join(segments, "/") + suffix
+}
+
+// NameDimensions returns the key=value segments of a sub-benchmark name as
+// a map, e.g. {"size": "1024", "mode": "fast"} for
+// "BenchmarkX/size=1024/mode=fast". The first segment and segments without
+// '=' are ignored, as is a " - unit" suffix. It returns nil if there are no
+// such segments.
+func NameDimensions(name string) map[string]string {
+	if i := strings.LastIndex(name, " - "); i >= 0 {
+		name = name[:i]
+	}
+	var dims map[string]string
+	for i, seg := range strings.Split(name, "/") {
+		k, v, ok := strings.Cut(seg, "=")
+		if i == 0 || !ok || k == "" {
+			continue
+		}
+		if dims == nil {
+			dims = make(map[string]string)
+		}
+		dims[k] = v
+	}
+	return dims
+}
+
+// Name normalization modes accepted by NormalizeName.
+const (
+	NameNormalizeNone = "none"
+	NameNormalizeTrim = "trim"
+	NameNormalizeFold = "fold"
+)
+
+// NormalizeName returns the form of name used to decide whether two names
+// denote the same benchmark. "trim" strips surrounding whitespace and
+// collapses inner runs of whitespace to one space; "fold" additionally
+// ignores case. Any other mode, including "none", returns name unchanged.
+func NormalizeName(name, mode string) string {
+	switch mode {
+	case NameNormalizeTrim:
+		return strings.Join(strings.Fields(name), " ")
+	case NameNormalizeFold:
+		return strings.ToLower(strings.Join(strings.Fields(name), " "))
+	}
+	return name
+}
+
+// ResolveNames renames results whose name normalizes (see NormalizeName)
+// to the name of a result already in existing with the same package and
+// unit, so that variants like "BenchmarkFoo" and "Benchmarkfoo" share one
+// series under the name first stored. Names seen first among results are
+// used for later results in the same way. It is a no-op for mode "none".
+func ResolveNames(existing BranchData, results []BenchmarkResult, mode string) {
+	if mode != NameNormalizeTrim && mode != NameNormalizeFold {
+		return
+	}
+
+	type nameKey struct {
+		pkg  string
+		unit string
+		norm string
+	}
+	canonical := make(map[nameKey]string)
+	remember := func(r BenchmarkResult) string {
+		k := nameKey{pkg: r.Package, unit: r.Unit, norm: NormalizeName(r.Name, mode)}
+		if name, ok := canonical[k]; ok {
+			return name
+		}
+		canonical[k] = r.Name
+		return r.Name
+	}
+
+	for _, e := range existing {
+		for _, r := range e.Benchmarks {
+			remember(r)
+		}
+	}
+	for i := range results {
+		results[i].Name = remember(results[i])
+	}
+}
+
+// QualifiedName returns the name of r prefixed with its package, e.g.
+// "example.com/m/codec.BenchmarkEncode/size=1024", so that benchmarks of the
+// same name in different packages can be told apart. It is r.Name when the
+// package is unknown. Only derived, never stored: EntryKey and dedup keep
+// comparing Package and Name separately.
+func (r BenchmarkResult) QualifiedName() string {
+	if r.Package == "" {
+		return r.Name
+	}
+	return r.Package + "." + r.Name
+}
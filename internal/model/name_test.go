@@ -0,0 +1,111 @@
+package model
+
+import "testing"
+
+func TestCanonicalName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"BenchmarkX/a=1/b=2", "BenchmarkX/a=1/b=2"},
+		{"BenchmarkX/b=2/a=1", "BenchmarkX/a=1/b=2"},
+		{"BenchmarkX/c=3/a=1/b=2", "BenchmarkX/a=1/b=2/c=3"},
+		{"BenchmarkX/json/size=10/mode=fast", "BenchmarkX/json/mode=fast/size=10"},
+		{"BenchmarkX/size=10/json/mode=fast", "BenchmarkX/mode=fast/json/size=10"},
+		{"BenchmarkX/b=2/a=1 - B/op", "BenchmarkX/a=1/b=2 - B/op"},
+		{"BenchmarkX", "BenchmarkX"},
+		{"BenchmarkX/small/large", "BenchmarkX/small/large"},
+		{"BenchmarkX/n=1", "BenchmarkX/n=1"},
+		{"BenchmarkX - allocs/op", "BenchmarkX - allocs/op"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalName(tt.in); got != tt.want {
+			t.Errorf("CanonicalName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalName_ReorderedSegmentsMatch(t *testing.T) {
+	a := CanonicalName("BenchmarkMap/keys=100/load=0.5/impl=swiss")
+	b := CanonicalName("BenchmarkMap/impl=swiss/keys=100/load=0.5")
+	if a != b {
+		t.Errorf("reordered names differ: %q vs %q", a, b)
+	}
+}
+
+func TestNameDimensions(t *testing.T) {
+	got := NameDimensions("BenchmarkX/json/size=1024/mode=fast - B/op")
+	if len(got) != 2 || got["size"] != "1024" || got["mode"] != "fast" {
+		t.Errorf("got %v, want map[mode:fast size:1024]", got)
+	}
+	if got := NameDimensions("BenchmarkX/small"); got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+}
+
+func TestQualifiedName(t *testing.T) {
+	tests := []struct {
+		pkg, name, want string
+	}{
+		{"example.com/m/codec", "BenchmarkEncode", "example.com/m/codec.BenchmarkEncode"},
+		{"example.com/m/codec", "BenchmarkEncode/size=1024/json", "example.com/m/codec.BenchmarkEncode/size=1024/json"},
+		{"", "BenchmarkEncode/small", "BenchmarkEncode/small"},
+		{"example.com/m/codec", "BenchmarkEncode - B/op", "example.com/m/codec.BenchmarkEncode - B/op"},
+	}
+	for _, tt := range tests {
+		r := BenchmarkResult{Package: tt.pkg, Name: tt.name}
+		if got := r.QualifiedName(); got != tt.want {
+			t.Errorf("QualifiedName(%q, %q) = %q, want %q", tt.pkg, tt.name, got, tt.want)
+		}
+	}
+
+	a := BenchmarkResult{Package: "example.com/m/a", Name: "BenchmarkEncode"}
+	b := BenchmarkResult{Package: "example.com/m/b", Name: "BenchmarkEncode"}
+	if a.QualifiedName() == b.QualifiedName() {
+		t.Errorf("same name in different packages collides: %q", a.QualifiedName())
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name, mode, want string
+	}{
+		{"  BenchmarkFoo ", NameNormalizeNone, "  BenchmarkFoo "},
+		{"  BenchmarkFoo ", NameNormalizeTrim, "BenchmarkFoo"},
+		{"BenchmarkFoo  -\tB/op", NameNormalizeTrim, "BenchmarkFoo - B/op"},
+		{"BenchmarkFoo", NameNormalizeTrim, "BenchmarkFoo"},
+		{" BenchmarkFoo", NameNormalizeFold, "benchmarkfoo"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeName(tt.name, tt.mode); got != tt.want {
+			t.Errorf("NormalizeName(%q, %q) = %q, want %q", tt.name, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestResolveNames(t *testing.T) {
+	existing := BranchData{{Benchmarks: []BenchmarkResult{{Name: "BenchmarkFoo", Unit: "ns/op"}}}}
+
+	tests := []struct {
+		mode, incoming, want string
+	}{
+		{NameNormalizeTrim, " BenchmarkFoo ", "BenchmarkFoo"},
+		{NameNormalizeTrim, "Benchmarkfoo", "Benchmarkfoo"},
+		{NameNormalizeFold, "Benchmarkfoo", "BenchmarkFoo"},
+		{NameNormalizeNone, " BenchmarkFoo", " BenchmarkFoo"},
+	}
+	for _, tt := range tests {
+		results := []BenchmarkResult{{Name: tt.incoming, Unit: "ns/op"}}
+		ResolveNames(existing, results, tt.mode)
+		if results[0].Name != tt.want {
+			t.Errorf("%s: %q resolved to %q, want %q", tt.mode, tt.incoming, results[0].Name, tt.want)
+		}
+	}
+
+	// The two variants now form a single series.
+	results := []BenchmarkResult{{Name: "Benchmarkfoo", Unit: "ns/op"}}
+	ResolveNames(existing, results, NameNormalizeFold)
+	data := append(existing, BenchmarkEntry{Date: 1, Benchmarks: results})
+	if got := len(data.SeriesIDs()); got != 1 {
+		t.Errorf("got %d series, want 1", got)
+	}
+}
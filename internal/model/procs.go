@@ -0,0 +1,19 @@
+package model
+
+import "slices"
+
+// FilterProcs returns the results whose Procs is in keep, preserving order.
+// Results with unknown procs (0) are always kept. An empty keep list keeps
+// everything.
+func FilterProcs(results []BenchmarkResult, keep []int) []BenchmarkResult {
+	if len(keep) == 0 {
+		return results
+	}
+	out := make([]BenchmarkResult, 0, len(results))
+	for _, r := range results {
+		if r.Procs == 0 || slices.Contains(keep, r.Procs) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+package model
+
+import "testing"
+
+func TestFilterProcs(t *testing.T) {
+	// As parsed from "go test -bench . -cpu=1,2,4,8".
+	var results []BenchmarkResult
+	for _, procs := range []int{1, 2, 4, 8} {
+		results = append(results,
+			BenchmarkResult{Name: "BenchmarkFoo", Value: 100, Unit: "ns/op", Procs: procs},
+			BenchmarkResult{Name: "BenchmarkFoo - B/op", Value: 64, Unit: "B/op", Procs: procs},
+		)
+	}
+	results = append(results, BenchmarkResult{Name: "BenchmarkImported", Value: 1, Unit: "ns/op"})
+
+	got := FilterProcs(results, []int{1, 8})
+	var procs []int
+	for _, r := range got {
+		procs = append(procs, r.Procs)
+	}
+	want := []int{1, 1, 8, 8, 0}
+	if len(procs) != len(want) {
+		t.Fatalf("got procs %v, want %v", procs, want)
+	}
+	for i := range want {
+		if procs[i] != want[i] {
+			t.Errorf("got procs %v, want %v", procs, want)
+			break
+		}
+	}
+
+	if got := FilterProcs(results, nil); len(got) != len(results) {
+		t.Errorf("empty keep list: got %d results, want %d", len(got), len(results))
+	}
+}
@@ -0,0 +1,30 @@
+package model
+
+import "math"
+
+// DefaultPrecision is the number of decimal places kept per unit by
+// RoundByUnit when no override is given. Counts are integers; timings keep
+// sub-nanosecond detail only to two places.
+var DefaultPrecision = map[string]int{
+	"ns/op":     2,
+	"B/op":      0,
+	"allocs/op": 0,
+	"MB/s":      1,
+}
+
+// RoundByUnit rounds r.Value (and r.StdDev) to the number of decimal places
+// configured for r.Unit in precision. Units missing from precision are left
+// untouched.
+func RoundByUnit(r *BenchmarkResult, precision map[string]int) {
+	places, ok := precision[r.Unit]
+	if !ok {
+		return
+	}
+	r.Value = roundTo(r.Value, places)
+	r.StdDev = roundTo(r.StdDev, places)
+}
+
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
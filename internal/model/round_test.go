@@ -0,0 +1,36 @@
+package model
+
+import "testing"
+
+func TestRoundByUnit(t *testing.T) {
+	tests := []struct {
+		unit      string
+		value     float64
+		stddev    float64
+		want      float64
+		wantStdev float64
+	}{
+		{"allocs/op", 5.0000, 0, 5, 0},
+		{"allocs/op", 4.6, 0.4, 5, 0},
+		{"B/op", 127.5, 0, 128, 0},
+		{"ns/op", 456.789, 1.2345, 456.79, 1.23},
+		{"ns/op", 0.004, 0, 0, 0},
+		{"MB/s", 123.456, 0, 123.5, 0},
+		{"items/op", 1.23456789, 0, 1.23456789, 0},
+	}
+	for _, tt := range tests {
+		r := BenchmarkResult{Unit: tt.unit, Value: tt.value, StdDev: tt.stddev}
+		RoundByUnit(&r, DefaultPrecision)
+		if r.Value != tt.want || r.StdDev != tt.wantStdev {
+			t.Errorf("%v %s: got %v ± %v, want %v ± %v", tt.value, tt.unit, r.Value, r.StdDev, tt.want, tt.wantStdev)
+		}
+	}
+}
+
+func TestRoundByUnit_Override(t *testing.T) {
+	r := BenchmarkResult{Unit: "ns/op", Value: 456.789}
+	RoundByUnit(&r, map[string]int{"ns/op": 0})
+	if r.Value != 457 {
+		t.Errorf("got %v, want 457", r.Value)
+	}
+}
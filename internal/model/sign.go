@@ -0,0 +1,105 @@
+package model
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnsigned is returned by VerifyEntryJSON for an entry without a
+	// signature.
+	ErrUnsigned = errors.New("entry is not signed")
+	// ErrBadSignature is returned by VerifyEntryJSON when the signature does
+	// not match the entry, i.e. the entry was changed after signing or
+	// signed with a different key.
+	ErrBadSignature = errors.New("entry signature does not match")
+)
+
+// signedEntry holds the members of an encoded entry that VerifyEntryJSON
+// reads itself.
+type signedEntry struct {
+	Commit    Commit `json:"commit"`
+	Signature string `json:"signature"`
+}
+
+// signaturePayload returns the bytes a signature covers, given the JSON
+// object of an entry: the object without its "signature" member, with keys
+// sorted at every level and numbers kept as written. It depends only on the
+// encoded entry, not on the fields of BenchmarkEntry, so an entry signed by
+// one version verifies in another that drops or adds fields.
+func signaturePayload(raw []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("decoding entry for signing: %w", err)
+	}
+	delete(obj, "signature")
+	payload, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("encoding entry for signing: %w", err)
+	}
+	return payload, nil
+}
+
+func entryMAC(raw, key []byte) ([]byte, error) {
+	payload, err := signaturePayload(raw)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil), nil
+}
+
+// SignEntry sets e.Signature to the hex HMAC-SHA256 of the entry's JSON
+// encoding under key, so that a store holding the same key can reject
+// entries forged or edited by an untrusted runner. Any later change to the
+// encoded entry invalidates it. See VerifyEntryJSON.
+func SignEntry(e *BenchmarkEntry, key []byte) error {
+	unsigned := *e
+	unsigned.Signature = ""
+	raw, err := json.Marshal(unsigned)
+	if err != nil {
+		return fmt.Errorf("encoding entry for signing: %w", err)
+	}
+	sum, err := entryMAC(raw, key)
+	if err != nil {
+		return err
+	}
+	e.Signature = hex.EncodeToString(sum)
+	return nil
+}
+
+// VerifyEntryJSON checks the signature of the entry encoded in raw, as read
+// from entry.json, against key. It returns ErrUnsigned if the entry has no
+// signature and ErrBadSignature if it does not match. The check runs on the
+// raw encoding rather than on a decoded BenchmarkEntry, whose re-encoding
+// would differ whenever the signing and verifying versions disagree on the
+// entry's fields.
+func VerifyEntryJSON(raw, key []byte) error {
+	var e signedEntry
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return fmt.Errorf("decoding entry: %w", err)
+	}
+	if e.Signature == "" {
+		return ErrUnsigned
+	}
+	got, err := hex.DecodeString(e.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBadSignature, err)
+	}
+	want, err := entryMAC(raw, key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(got, want) {
+		return fmt.Errorf("%w for commit %s", ErrBadSignature, e.Commit.SHA)
+	}
+	return nil
+}
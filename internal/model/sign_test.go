@@ -0,0 +1,63 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSignEntry(t *testing.T) {
+	key := []byte("runner-secret")
+	signed := BenchmarkEntry{
+		Commit: Commit{SHA: "abc123", Date: "2024-01-01T00:00:00Z"},
+		Date:   1704067200000,
+		Params: RunParams{CPU: "Intel Xeon", GOOS: "linux", GOARCH: "amd64"},
+		Benchmarks: []BenchmarkResult{
+			{Name: "BenchmarkFoo", Value: 123.456, Unit: "ns/op"},
+		},
+	}
+	if err := SignEntry(&signed, key); err != nil {
+		t.Fatalf("SignEntry() error: %v", err)
+	}
+
+	// parse writes entry.json indented; store verifies the bytes as read.
+	raw, err := json.MarshalIndent(signed, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		t.Fatal(err)
+	}
+	edit := func(old, new string) []byte {
+		if !bytes.Contains(raw, []byte(old)) {
+			t.Fatalf("entry.json has no %s:\n%s", old, raw)
+		}
+		return bytes.Replace(raw, []byte(old), []byte(new), 1)
+	}
+
+	tests := []struct {
+		name string
+		raw  []byte
+		key  []byte
+		want error
+	}{
+		{"valid", raw, key, nil},
+		{"reformatted", compact.Bytes(), key, nil},
+		{"tampered value", edit("123.456", "99"), key, ErrBadSignature},
+		{"tampered SHA", edit(`"abc123"`, `"def456"`), key, ErrBadSignature},
+		{"added field", edit(`"date": 1704067200000`, `"date": 1704067200000, "extra": true`), key, ErrBadSignature},
+		{"wrong key", raw, []byte("other"), ErrBadSignature},
+		{"not hex", edit(signed.Signature, "zz"), key, ErrBadSignature},
+		{"unsigned", edit(`"signature": "`+signed.Signature+`"`, `"signature": ""`), key, ErrUnsigned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyEntryJSON(tt.raw, tt.key)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package model
+
+// DefaultSlowThresholdNs is the ns/op above which SlowBenchmarks reports a
+// benchmark: 10s.
+const DefaultSlowThresholdNs = 1e10
+
+// SlowBenchmarks returns the ns/op results slower than thresholdNs, in
+// their original order. Such benchmarks usually mean a workload that does
+// not belong in the regular suite, or an accidental complexity blowup. A
+// thresholdNs of zero or less disables the check.
+func SlowBenchmarks(results []BenchmarkResult, thresholdNs float64) []BenchmarkResult {
+	if thresholdNs <= 0 {
+		return nil
+	}
+	var slow []BenchmarkResult
+	for _, r := range results {
+		if r.Unit == "ns/op" && r.Value > thresholdNs {
+			slow = append(slow, r)
+		}
+	}
+	return slow
+}
@@ -0,0 +1,23 @@
+package model
+
+import "testing"
+
+func TestSlowBenchmarks(t *testing.T) {
+	results := []BenchmarkResult{
+		{Name: "BenchmarkFast", Value: 1200, Unit: "ns/op"},
+		{Name: "BenchmarkQuadratic", Value: 95_000_000_000, Unit: "ns/op"},
+		{Name: "BenchmarkQuadratic - B/op", Value: 95_000_000_000, Unit: "B/op"},
+		{Name: "BenchmarkBorderline", Value: DefaultSlowThresholdNs, Unit: "ns/op"},
+	}
+
+	slow := SlowBenchmarks(results, DefaultSlowThresholdNs)
+	if len(slow) != 1 || slow[0].Name != "BenchmarkQuadratic" {
+		t.Errorf("got %+v, want only BenchmarkQuadratic", slow)
+	}
+	if slow := SlowBenchmarks(results[:1], DefaultSlowThresholdNs); len(slow) != 0 {
+		t.Errorf("fast benchmark: got %+v, want none", slow)
+	}
+	if slow := SlowBenchmarks(results, 0); slow != nil {
+		t.Errorf("disabled: got %+v, want nil", slow)
+	}
+}
@@ -0,0 +1,24 @@
+package model
+
+import "sort"
+
+// SortBenchmarks sorts results in place by (Package, Name, Unit, Procs).
+//
+// The parser emits results in the order they appear in the go test output,
+// which depends on package iteration order. Sorting before writing makes the
+// stored JSON independent of that order so that re-runs produce stable diffs.
+func SortBenchmarks(results []BenchmarkResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Unit != b.Unit {
+			return a.Unit < b.Unit
+		}
+		return a.Procs < b.Procs
+	})
+}
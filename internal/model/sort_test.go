@@ -0,0 +1,39 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortBenchmarks_StableRegardlessOfInputOrder(t *testing.T) {
+	want := []BenchmarkResult{
+		{Name: "BenchmarkA", Unit: "B/op", Package: "pkg/a", Procs: 1},
+		{Name: "BenchmarkA", Unit: "ns/op", Package: "pkg/a", Procs: 1},
+		{Name: "BenchmarkA", Unit: "ns/op", Package: "pkg/a", Procs: 4},
+		{Name: "BenchmarkB", Unit: "ns/op", Package: "pkg/a", Procs: 1},
+		{Name: "BenchmarkA", Unit: "ns/op", Package: "pkg/b", Procs: 1},
+	}
+
+	orders := [][]int{
+		{0, 1, 2, 3, 4},
+		{4, 3, 2, 1, 0},
+		{2, 4, 0, 3, 1},
+		{3, 0, 4, 1, 2},
+	}
+
+	for _, order := range orders {
+		got := make([]BenchmarkResult, 0, len(want))
+		for _, i := range order {
+			got = append(got, want[i])
+		}
+		SortBenchmarks(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("order %v: got %+v, want %+v", order, got, want)
+		}
+	}
+}
+
+func TestSortBenchmarks_Empty(t *testing.T) {
+	SortBenchmarks(nil)
+	SortBenchmarks([]BenchmarkResult{})
+}
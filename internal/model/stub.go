@@ -0,0 +1,45 @@
+package model
+
+// DefaultStubMaxNs is the ns/op below which DropSingleIteration treats a
+// single-iteration benchmark as a setup stub: 1ms.
+const DefaultStubMaxNs = 1e6
+
+// DropSingleIteration removes benchmarks that look like b.N-insensitive
+// setup stubs: their ns/op result ran a single iteration and took less
+// than maxNs. All metrics of such a benchmark (same package, base name and
+// procs) are removed together. Genuinely heavy benchmarks that only fit one
+// iteration in the bench time are kept because their ns/op is large.
+//
+// It returns the kept results in their original order and the names of the
+// dropped benchmarks.
+func DropSingleIteration(results []BenchmarkResult, maxNs float64) (kept []BenchmarkResult, dropped []string) {
+	type benchKey struct {
+		pkg   string
+		name  string
+		procs int
+	}
+	keyOf := func(r BenchmarkResult) benchKey {
+		return benchKey{pkg: r.Package, name: r.BaseName(), procs: r.Procs}
+	}
+
+	stubs := make(map[benchKey]struct{})
+	for _, r := range results {
+		if r.Unit == "ns/op" && r.Iterations() == 1 && r.Value < maxNs {
+			if _, ok := stubs[keyOf(r)]; !ok {
+				stubs[keyOf(r)] = struct{}{}
+				dropped = append(dropped, r.BaseName())
+			}
+		}
+	}
+	if len(stubs) == 0 {
+		return results, nil
+	}
+
+	kept = make([]BenchmarkResult, 0, len(results))
+	for _, r := range results {
+		if _, ok := stubs[keyOf(r)]; !ok {
+			kept = append(kept, r)
+		}
+	}
+	return kept, dropped
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDropSingleIteration(t *testing.T) {
+	results := []BenchmarkResult{
+		// Setup stub: one iteration, 2µs.
+		{Name: "BenchmarkSetup", Value: 2000, Unit: "ns/op", Extra: "1 times\n8 procs", Procs: 8},
+		{Name: "BenchmarkSetup - B/op", Value: 64, Unit: "B/op", Extra: "1 times\n8 procs", Procs: 8},
+		// Genuine heavy benchmark: one iteration of ~95s.
+		{Name: "BenchmarkBuildIndex", Value: 95_000_000_000, Unit: "ns/op", Extra: "1 times\n8 procs", Procs: 8},
+		// Normal benchmark.
+		{Name: "BenchmarkFast", Value: 12.5, Unit: "ns/op", Extra: "100000000 times\n8 procs", Procs: 8},
+	}
+
+	kept, dropped := DropSingleIteration(results, DefaultStubMaxNs)
+	if want := []string{"BenchmarkSetup"}; !slices.Equal(dropped, want) {
+		t.Errorf("dropped: got %v, want %v", dropped, want)
+	}
+	var names []string
+	for _, r := range kept {
+		names = append(names, r.Name)
+	}
+	if want := []string{"BenchmarkBuildIndex", "BenchmarkFast"}; !slices.Equal(names, want) {
+		t.Errorf("kept: got %v, want %v", names, want)
+	}
+
+	// A higher threshold also drops the heavy benchmark.
+	if _, dropped := DropSingleIteration(results, 1e12); len(dropped) != 2 {
+		t.Errorf("got %d dropped with a 1000s threshold, want 2", len(dropped))
+	}
+}
+
+func TestBenchmarkResult_Iterations(t *testing.T) {
+	tests := []struct {
+		extra string
+		want  int
+	}{
+		{"1000 times\n8 procs", 1000},
+		{"1 times", 1},
+		{"", 0},
+		{"many times", 0},
+	}
+	for _, tt := range tests {
+		if got := (BenchmarkResult{Extra: tt.extra}).Iterations(); got != tt.want {
+			t.Errorf("Iterations(%q) = %d, want %d", tt.extra, got, tt.want)
+		}
+	}
+}
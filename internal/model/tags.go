@@ -0,0 +1,55 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseTag splits a "key=value" experiment tag. The key must be non-empty
+// and must not contain ',' or '=', and the value must not contain ',', so
+// that tags render unescaped in CanonicalTags.
+func ParseTag(s string) (key, value string, err error) {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid tag %q: expected key=value", s)
+	}
+	if strings.ContainsAny(key, ",=") {
+		return "", "", fmt.Errorf("invalid tag %q: key must not contain ',' or '='", s)
+	}
+	if strings.Contains(value, ",") {
+		return "", "", fmt.Errorf("invalid tag %q: value must not contain ','", s)
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// CanonicalTags renders tags as "k1=v1,k2=v2" with keys sorted, so that
+// equal tag sets always produce the same string. A '\\', ',' or '=' within
+// a key or value is escaped with a backslash, so that distinct maps, e.g.
+// captured environment variables holding commas, never render alike. It
+// returns "" for no tags.
+func CanonicalTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(tagEscaper.Replace(k))
+		sb.WriteByte('=')
+		sb.WriteString(tagEscaper.Replace(tags[k]))
+	}
+	return sb.String()
+}
+
+// tagEscaper escapes the separators of CanonicalTags.
+var tagEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`)
@@ -0,0 +1,49 @@
+package model
+
+import "testing"
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		in        string
+		key, val  string
+		expectErr bool
+	}{
+		{"alloc=arena", "alloc", "arena", false},
+		{" gc = off ", "gc", "off", false},
+		{"flag=", "flag", "", false},
+		{"noequals", "", "", true},
+		{"=value", "", "", true},
+		{"a,b=c", "", "", true},
+		{"a=b,c", "", "", true},
+	}
+	for _, tt := range tests {
+		k, v, err := ParseTag(tt.in)
+		if (err != nil) != tt.expectErr {
+			t.Errorf("ParseTag(%q) error = %v, expectErr %v", tt.in, err, tt.expectErr)
+			continue
+		}
+		if k != tt.key || v != tt.val {
+			t.Errorf("ParseTag(%q) = (%q, %q), want (%q, %q)", tt.in, k, v, tt.key, tt.val)
+		}
+	}
+}
+
+func TestCanonicalTags(t *testing.T) {
+	if got := CanonicalTags(nil); got != "" {
+		t.Errorf("CanonicalTags(nil) = %q, want empty", got)
+	}
+	got := CanonicalTags(map[string]string{"gc": "off", "alloc": "arena"})
+	if want := "alloc=arena,gc=off"; got != want {
+		t.Errorf("CanonicalTags() = %q, want %q", got, want)
+	}
+
+	// Separators inside values are escaped, so these maps stay distinct.
+	joined := CanonicalTags(map[string]string{"A": "1,B=2"})
+	split := CanonicalTags(map[string]string{"A": "1", "B": "2"})
+	if joined == split {
+		t.Errorf("CanonicalTags() renders %q for two different maps", joined)
+	}
+	if want := `A=1\,B\=2`; joined != want {
+		t.Errorf("CanonicalTags() = %q, want %q", joined, want)
+	}
+}
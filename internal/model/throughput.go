@@ -0,0 +1,48 @@
+package model
+
+import "strconv"
+
+// ThroughputUnit is the unit of metrics derived by DeriveThroughput.
+const ThroughputUnit = "bytes/sec"
+
+// DeriveThroughput appends a bytes/sec metric for every ns/op result whose
+// name carries a numeric size=N dimension (see NameDimensions), computed as
+// N / (ns/op × 1e-9). Results without a size, with a zero ns/op, or that
+// already have a derived throughput are skipped, so applying it twice is
+// harmless. The derived result is named like the parser names secondary
+// metrics, "<name> - bytes/sec".
+func DeriveThroughput(results []BenchmarkResult) []BenchmarkResult {
+	have := make(map[SeriesKey]struct{})
+	for _, r := range results {
+		if r.Unit == ThroughputUnit {
+			have[r.SeriesKey()] = struct{}{}
+		}
+	}
+
+	n := len(results)
+	for i := 0; i < n; i++ {
+		r := results[i]
+		if r.Unit != "ns/op" || r.Value == 0 {
+			continue
+		}
+		size, err := strconv.ParseFloat(NameDimensions(r.Name)["size"], 64)
+		if err != nil || size <= 0 {
+			continue
+		}
+		derived := BenchmarkResult{
+			Name:    r.BaseName() + " - " + ThroughputUnit,
+			Value:   size / (r.Value * 1e-9),
+			Unit:    ThroughputUnit,
+			Extra:   r.Extra,
+			Package: r.Package,
+			Procs:   r.Procs,
+			Samples: r.Samples,
+		}
+		if _, ok := have[derived.SeriesKey()]; ok {
+			continue
+		}
+		have[derived.SeriesKey()] = struct{}{}
+		results = append(results, derived)
+	}
+	return results
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDeriveThroughput(t *testing.T) {
+	// As parsed from "BenchmarkEncode/size=1024-8  1000  500 ns/op".
+	results := []BenchmarkResult{
+		{Name: "BenchmarkEncode/size=1024", Value: 500, Unit: "ns/op", Extra: "1000 times\n8 procs", Procs: 8},
+		{Name: "BenchmarkEncode/size=1024 - B/op", Value: 64, Unit: "B/op", Procs: 8},
+		{Name: "BenchmarkDecode", Value: 500, Unit: "ns/op"},
+		{Name: "BenchmarkZero/size=10", Value: 0, Unit: "ns/op"},
+		{Name: "BenchmarkHuman/size=1KB", Value: 500, Unit: "ns/op"},
+	}
+
+	got := DeriveThroughput(results)
+	if len(got) != len(results)+1 {
+		t.Fatalf("got %d results, want %d", len(got), len(results)+1)
+	}
+	d := got[len(got)-1]
+	if d.Name != "BenchmarkEncode/size=1024 - bytes/sec" || d.Unit != ThroughputUnit || d.Procs != 8 {
+		t.Errorf("got %s (%s, %d procs), want BenchmarkEncode/size=1024 - bytes/sec (bytes/sec, 8 procs)", d.Name, d.Unit, d.Procs)
+	}
+	if want := 1024 / 500e-9; math.Abs(d.Value-want) > 1e-6*want {
+		t.Errorf("got %v bytes/sec, want %v", d.Value, want)
+	}
+
+	if again := DeriveThroughput(got); len(again) != len(got) {
+		t.Errorf("applying twice: got %d results, want %d", len(again), len(got))
+	}
+}
@@ -1,56 +1,156 @@
 package model
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // BenchmarkResult represents a single benchmark measurement.
+//
+// When several samples of the same benchmark (e.g. from -count=N) are
+// aggregated, Value holds their mean, StdDev the sample standard deviation
+// and Samples the number of samples that contributed.
+//
+// Custom marks a metric in a unit go test does not report itself (see
+// IsStandardUnit), i.e. one from b.ReportMetric.
+//
+// BytesPerOp and AllocsPerOp hold the -benchmem metrics of an ns/op result
+// when the parser attached them to it instead of emitting separate
+// "- B/op" and "- allocs/op" results (parse.ParseOptions.InlineMemMetrics).
+// They are nil otherwise, so a measured zero is distinguishable from none.
 type BenchmarkResult struct {
-	Name    string  `json:"name"`
-	Value   float64 `json:"value"`
-	Unit    string  `json:"unit"`
-	Extra   string  `json:"extra,omitempty"`
-	Package string  `json:"package,omitempty"`
-	Procs   int     `json:"procs,omitempty"`
+	Name        string   `json:"name"`
+	Value       float64  `json:"value"`
+	Unit        string   `json:"unit"`
+	Extra       string   `json:"extra,omitempty"`
+	Package     string   `json:"package,omitempty"`
+	Procs       int      `json:"procs,omitempty"`
+	StdDev      float64  `json:"stdDev,omitempty"`
+	Samples     int      `json:"samples,omitempty"`
+	Custom      bool     `json:"custom,omitempty"`
+	BytesPerOp  *float64 `json:"bytesPerOp,omitempty"`
+	AllocsPerOp *float64 `json:"allocsPerOp,omitempty"`
+}
+
+// BaseName returns the benchmark name without the " - unit" suffix the
+// parser adds to secondary metrics, e.g. "BenchmarkFoo - B/op" becomes
+// "BenchmarkFoo".
+func (r BenchmarkResult) BaseName() string {
+	return strings.TrimSuffix(r.Name, " - "+r.Unit)
+}
+
+// Iterations returns the b.N the result was measured with, recorded by
+// the parser in Extra ("N times\nP procs"). It returns 0 when unknown.
+func (r BenchmarkResult) Iterations() int {
+	first, _, _ := strings.Cut(r.Extra, "\n")
+	n, ok := strings.CutSuffix(first, " times")
+	if !ok {
+		return 0
+	}
+	v, err := strconv.Atoi(n)
+	if err != nil {
+		return 0
+	}
+	return v
 }
 
 // Commit represents the git commit associated with a benchmark run.
+//
+// Parents optionally lists ancestors of the commit, nearest first, as
+// printed by `git rev-list --first-parent`. It lets the commits between
+// two benchmarked points be recovered without the repository.
 type Commit struct {
-	SHA     string `json:"sha"`
-	Message string `json:"message"`
-	Author  string `json:"author"`
-	Date    string `json:"date"`
-	URL     string `json:"url"`
+	SHA     string   `json:"sha"`
+	Message string   `json:"message"`
+	Author  string   `json:"author"`
+	Date    string   `json:"date"`
+	URL     string   `json:"url"`
+	Parents []string `json:"parents,omitempty"`
 }
 
 // RunParams holds the environment and configuration parameters that uniquely
 // identify a benchmark run configuration. Two runs with the same commit and
 // the same RunParams are considered the same logical run — newer results
 // replace older ones.
+//
+// DatasetHash optionally identifies the input corpus data-driven benchmarks
+// read (e.g. a hash of their fixtures), so that runs against different
+// inputs are neither deduplicated nor compared with each other.
+//
+// MicroArch is the microarchitecture level the benchmarks were built for
+// within GOARCH, i.e. the value of GOAMD64, GOARM, GOARM64 or the like
+// (e.g. "v3" for amd64, "7" for arm); empty if unknown.
 type RunParams struct {
-	CPU       string `json:"cpu,omitempty"`
-	GOOS      string `json:"goos,omitempty"`
-	GOARCH    string `json:"goarch,omitempty"`
-	GoVersion string `json:"goVersion,omitempty"`
-	CGO       bool   `json:"cgo"`
+	CPU         string `json:"cpu,omitempty"`
+	GOOS        string `json:"goos,omitempty"`
+	GOARCH      string `json:"goarch,omitempty"`
+	MicroArch   string `json:"microArch,omitempty"`
+	GoVersion   string `json:"goVersion,omitempty"`
+	CGO         bool   `json:"cgo"`
+	DatasetHash string `json:"datasetHash,omitempty"`
 }
 
 // BenchmarkEntry represents a single benchmark run (one commit's results
 // from a specific host/configuration).
+//
+// CPUModels lists every distinct CPU model of heterogeneous hosts (e.g.
+// big.LITTLE ARM). It is informational only; Params.CPU remains the single
+// representative model used for deduplication.
+//
+// CPURaw keeps the CPU model as detected when parse -cpu-normalize changed
+// it for Params.CPU. Like CPUModels it is not part of EntryKey.
+//
+// Tags are free-form experiment labels (e.g. "alloc=arena") that distinguish
+// runs of the same commit on the same host. They are unrelated to git tags.
+//
+// Environment holds host environment variables captured at parse time (e.g.
+// INSTANCE_TYPE). It is descriptive and not part of EntryKey.
+//
+// ProfileURL optionally links to a pprof profile recorded during the run.
+//
+// Signature is the optional HMAC of the entry written by parse with a
+// signing key; see SignEntry. It is checked and cleared by store.
 type BenchmarkEntry struct {
-	Commit     Commit            `json:"commit"`
-	Date       int64             `json:"date"`
-	Params     RunParams         `json:"params"`
-	Benchmarks []BenchmarkResult `json:"benchmarks"`
+	Commit      Commit            `json:"commit"`
+	Date        int64             `json:"date"`
+	Params      RunParams         `json:"params"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"`
+	CPUModels   []string          `json:"cpuModels,omitempty"`
+	CPURaw      string            `json:"cpuRaw,omitempty"`
+	ProfileURL  string            `json:"profileUrl,omitempty"`
+	Benchmarks  []BenchmarkResult `json:"benchmarks"`
+	Signature   string            `json:"signature,omitempty"`
 }
 
 // EntryKey returns a composite key that uniquely identifies a benchmark run
-// by its commit SHA and all run parameters. Entries with the same key
-// represent the same logical run and newer results should replace older ones.
+// by its commit SHA, all run parameters and its experiment tags. Entries with
+// the same key represent the same logical run and newer results should
+// replace older ones. It is KeyWith(DefaultKeyConfig).
 //
 // RunParams is a simple comparable struct (no slices, maps, or pointers),
-// so we use it directly as part of the map key.
+// so we use it directly as part of the map key. Tags are a map and enter the
+// key in their canonical string form.
 func (e BenchmarkEntry) EntryKey() EntryKeyValue {
-	return EntryKeyValue{
-		SHA:    e.Commit.SHA,
-		Params: e.Params,
-	}
+	return e.KeyWith(DefaultKeyConfig)
+}
+
+// EntryKeyWithEnv is like EntryKey but also distinguishes entries by their
+// captured Environment, for setups where e.g. different instance types of the
+// same configuration must be kept apart.
+func (e BenchmarkEntry) EntryKeyWithEnv() EntryKeyValue {
+	cfg := DefaultKeyConfig
+	cfg.Env = true
+	return e.KeyWith(cfg)
+}
+
+// ConfigKey is EntryKey without the commit SHA: the run parameters and
+// experiment tags under which results of different commits are comparable.
+func (e BenchmarkEntry) ConfigKey() EntryKeyValue {
+	cfg := DefaultKeyConfig
+	cfg.SHA = false
+	return e.KeyWith(cfg)
 }
 
 // EntryKeyValue is the composite key type used for deduplication.
@@ -58,6 +158,23 @@ func (e BenchmarkEntry) EntryKey() EntryKeyValue {
 type EntryKeyValue struct {
 	SHA    string
 	Params RunParams
+	Tags   string
+	Env    string
+}
+
+// String renders the key for diagnostics, e.g.
+// `sha=abc123 cpu="Intel Xeon" goos=linux goarch=amd64 go=go1.22.0 cgo=true`.
+// Tags and Env are appended only when set.
+func (k EntryKeyValue) String() string {
+	s := fmt.Sprintf("sha=%s cpu=%q goos=%s goarch=%s go=%s cgo=%t",
+		k.SHA, k.Params.CPU, k.Params.GOOS, k.Params.GOARCH, k.Params.GoVersion, k.Params.CGO)
+	if k.Tags != "" {
+		s += fmt.Sprintf(" tags=%q", k.Tags)
+	}
+	if k.Env != "" {
+		s += fmt.Sprintf(" env=%q", k.Env)
+	}
+	return s
 }
 
 // BranchData is a slice of benchmark entries for a given branch,
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBenchmarkResult_InlineMemMetricsJSON(t *testing.T) {
+	bytes, allocs := 64.0, 0.0
+	r := BenchmarkResult{Name: "BenchmarkAlloc", Value: 100, Unit: "ns/op", BytesPerOp: &bytes, AllocsPerOp: &allocs}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"bytesPerOp":64`) || !strings.Contains(string(data), `"allocsPerOp":0`) {
+		t.Errorf("got %s, want bytesPerOp 64 and an explicit allocsPerOp 0", data)
+	}
+
+	var got BenchmarkResult
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, r) {
+		t.Errorf("round trip: got %+v, want %+v", got, r)
+	}
+
+	// Results without inline metrics serialize as before.
+	data, err = json.Marshal(BenchmarkResult{Name: "BenchmarkAlloc", Value: 100, Unit: "ns/op"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"BenchmarkAlloc","value":100,"unit":"ns/op"}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,76 @@
+package parse
+
+import (
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/stats"
+)
+
+// sampleKey identifies repeated samples of the same benchmark metric.
+type sampleKey struct {
+	pkg   string
+	name  string
+	procs int
+	unit  string
+}
+
+// AggregateSamples collapses repeated results for the same
+// (package, name, procs, unit) tuple — as produced by `go test -count=N` —
+// into a single result whose Value is the mean of the samples. StdDev and
+// Samples are filled in from the samples that were kept, and inlined
+// BytesPerOp and AllocsPerOp are averaged likewise.
+//
+// When discardFirst is true the first sample of each benchmark is dropped
+// before computing the statistics, as it is frequently a cold-cache outlier.
+// A benchmark with only one sample is never discarded.
+//
+// The output preserves the order in which each benchmark first appeared.
+func AggregateSamples(results []model.BenchmarkResult, discardFirst bool) []model.BenchmarkResult {
+	var order []sampleKey
+	groups := make(map[sampleKey][]model.BenchmarkResult)
+	for _, r := range results {
+		k := sampleKey{pkg: r.Package, name: r.Name, procs: r.Procs, unit: r.Unit}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], r)
+	}
+
+	out := make([]model.BenchmarkResult, 0, len(order))
+	for _, k := range order {
+		samples := groups[k]
+		if discardFirst && len(samples) > 1 {
+			samples = samples[1:]
+		}
+
+		values := make([]float64, len(samples))
+		for i, s := range samples {
+			values[i] = s.Value
+		}
+		mean, stddev := stats.MeanStdDev(values)
+
+		agg := samples[0]
+		agg.Value = mean
+		agg.StdDev = stddev
+		agg.Samples = len(samples)
+		agg.BytesPerOp = meanOf(samples, func(r model.BenchmarkResult) *float64 { return r.BytesPerOp })
+		agg.AllocsPerOp = meanOf(samples, func(r model.BenchmarkResult) *float64 { return r.AllocsPerOp })
+		out = append(out, agg)
+	}
+	return out
+}
+
+// meanOf returns the mean of an optional field over the samples that have
+// it, or nil if none does.
+func meanOf(samples []model.BenchmarkResult, field func(model.BenchmarkResult) *float64) *float64 {
+	var values []float64
+	for _, s := range samples {
+		if v := field(s); v != nil {
+			values = append(values, *v)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	mean, _ := stats.MeanStdDev(values)
+	return &mean
+}
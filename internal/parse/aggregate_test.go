@@ -0,0 +1,143 @@
+package parse
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const fiveSamplesOutput = `pkg: github.com/user/repo
+BenchmarkWarm-8      1000        500 ns/op
+BenchmarkWarm-8      1000        100 ns/op
+BenchmarkWarm-8      1000        100 ns/op
+BenchmarkWarm-8      1000        100 ns/op
+BenchmarkWarm-8      1000        100 ns/op
+BenchmarkOnce-8      1000        42 ns/op
+PASS
+`
+
+func TestAggregateSamples_Mean(t *testing.T) {
+	results, err := ParseGoBenchOutput(strings.NewReader(fiveSamplesOutput))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	agg := AggregateSamples(results, false)
+	if len(agg) != 2 {
+		t.Fatalf("expected 2 aggregated results, got %d", len(agg))
+	}
+
+	if agg[0].Name != "BenchmarkWarm" {
+		t.Fatalf("expected first result BenchmarkWarm, got %s", agg[0].Name)
+	}
+	if agg[0].Value != 180 {
+		t.Errorf("mean: got %v, want 180", agg[0].Value)
+	}
+	if agg[0].Samples != 5 {
+		t.Errorf("samples: got %d, want 5", agg[0].Samples)
+	}
+	if math.Abs(agg[0].StdDev-178.8854381999832) > 1e-9 {
+		t.Errorf("stddev: got %v", agg[0].StdDev)
+	}
+}
+
+func TestAggregateSamples_DiscardFirst(t *testing.T) {
+	results, err := ParseGoBenchOutput(strings.NewReader(fiveSamplesOutput))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	agg := AggregateSamples(results, true)
+	if len(agg) != 2 {
+		t.Fatalf("expected 2 aggregated results, got %d", len(agg))
+	}
+
+	// The cold 500 ns/op sample is dropped, leaving four 100 ns/op samples.
+	if agg[0].Value != 100 {
+		t.Errorf("mean: got %v, want 100", agg[0].Value)
+	}
+	if agg[0].StdDev != 0 {
+		t.Errorf("stddev: got %v, want 0", agg[0].StdDev)
+	}
+	if agg[0].Samples != 4 {
+		t.Errorf("samples: got %d, want 4", agg[0].Samples)
+	}
+
+	// A single-sample benchmark is kept as is.
+	if agg[1].Name != "BenchmarkOnce" || agg[1].Value != 42 || agg[1].Samples != 1 {
+		t.Errorf("single sample: got %+v", agg[1])
+	}
+}
+
+func TestAggregateSamples_DistinctProcsAndUnits(t *testing.T) {
+	input := `pkg: github.com/user/repo
+BenchmarkWork-4      1000        200 ns/op     16 B/op
+BenchmarkWork-8      1000        100 ns/op     16 B/op
+BenchmarkWork-4      1000        400 ns/op     16 B/op
+BenchmarkWork-8      1000        300 ns/op     16 B/op
+`
+	results, err := ParseGoBenchOutput(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	agg := AggregateSamples(results, false)
+	if len(agg) != 4 {
+		t.Fatalf("expected 4 aggregated results, got %d", len(agg))
+	}
+	if agg[0].Procs != 4 || agg[0].Unit != "ns/op" || agg[0].Value != 300 {
+		t.Errorf("result 0: got %+v", agg[0])
+	}
+	if agg[2].Procs != 8 || agg[2].Unit != "ns/op" || agg[2].Value != 200 {
+		t.Errorf("result 2: got %+v", agg[2])
+	}
+}
+
+func TestParseGoBenchOutputWithOptions_Aggregate(t *testing.T) {
+	// The zero options keep every sample.
+	raw, _, _, err := ParseGoBenchOutputWithOptions(strings.NewReader(fiveSamplesOutput), ParseOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(raw) != 6 {
+		t.Fatalf("without Aggregate: got %d results, want 6", len(raw))
+	}
+
+	agg, _, _, err := ParseGoBenchOutputWithOptions(strings.NewReader(fiveSamplesOutput), ParseOptions{Aggregate: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(agg) != 2 {
+		t.Fatalf("with Aggregate: got %d results, want 2", len(agg))
+	}
+	if agg[0].Value != 180 || agg[0].Samples != 5 || math.Abs(agg[0].StdDev-178.885) > 0.001 {
+		t.Errorf("aggregated: got %+v, want mean 180, stddev ~178.885, 5 samples", agg[0])
+	}
+
+	agg, _, _, err = ParseGoBenchOutputWithOptions(strings.NewReader(fiveSamplesOutput), ParseOptions{DiscardFirst: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if agg[0].Value != 100 || agg[0].Samples != 4 {
+		t.Errorf("DiscardFirst: got %+v, want mean 100 over 4 samples", agg[0])
+	}
+}
+
+func TestAggregateSamples_InlineMemMetrics(t *testing.T) {
+	input := `pkg: github.com/user/repo
+BenchmarkAlloc-8   1000   100 ns/op   64 B/op   1 allocs/op
+BenchmarkAlloc-8   1000   120 ns/op   96 B/op   3 allocs/op
+PASS
+`
+	results, _, _, err := ParseGoBenchOutputWithOptions(strings.NewReader(input), ParseOptions{InlineMemMetrics: true, Aggregate: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d: %+v", len(results), results)
+	}
+	r := results[0]
+	if r.Value != 110 || r.BytesPerOp == nil || *r.BytesPerOp != 80 || r.AllocsPerOp == nil || *r.AllocsPerOp != 2 {
+		t.Errorf("got %+v, want mean 110 ns/op with 80 B/op and 2 allocs/op", r)
+	}
+}
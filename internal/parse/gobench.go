@@ -2,33 +2,114 @@ package parse
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"path"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/royalcat/go-continuous-benchmarking/internal/model"
 )
 
-// reGoBench matches Go benchmark result lines.
+// benchName is the pattern of a benchmark name as go test prints it:
+// "Benchmark" followed by nothing or a non-lowercase character, as in the
+// testing package. A trailing ':' is excluded so that log lines like
+// "BenchmarkFoo: warming up" in -v output are not taken for results.
+const benchName = `Benchmark(?:[^a-z\s:](?:\S*?[^\s:])??)??`
+
+// reGoBench matches the shape of Go benchmark result lines.
 // Format: BenchmarkName-PROCS  iterations  value unit [value unit ...]
 // Reference: https://go.googlesource.com/proposal/+/master/design/14313-benchmark-format.md
+//
+// The metrics are validated separately: a line is only a result if all of
+// them are numeric value/unit pairs.
 var reGoBench = regexp.MustCompile(
-	`^(?P<name>Benchmark\S+?)(?:-(?P<procs>\d+))?\s+(?P<iters>\d+)\s+(?P<rest>.+)$`,
+	`^(?P<name>` + benchName + `)(?:-(?P<procs>\d+))?\s+(?P<iters>\d+)\s+(?P<rest>.+)$`,
 )
 
+// reBenchName matches a field that is a benchmark name, with or without
+// the -PROCS suffix.
+var reBenchName = regexp.MustCompile(`^` + benchName + `$`)
+
+// reValue matches a metric value: a decimal number, optionally in
+// scientific notation ("0.3456", "1.2e+03", "5E-7"). strconv.ParseFloat
+// alone is too lenient here, since it also takes "NaN", "Inf" and hex
+// floats, which go test never prints as values.
+var reValue = regexp.MustCompile(`^[-+]?(?:\d+(?:\.\d*)?|\.\d+)(?:[eE][-+]?\d+)?$`)
+
 // rePkgLine matches the "pkg: ..." line that precedes benchmark output for a package.
 var rePkgLine = regexp.MustCompile(`^pkg:\s+(\S+)`)
 
 // reCPULine matches the "cpu: ..." line emitted by go test.
 var reCPULine = regexp.MustCompile(`^cpu:\s+(.+)$`)
 
+// reGOOSLine and reGOARCHLine match the "goos: ..." and "goarch: ..."
+// header lines.
+var (
+	reGOOSLine   = regexp.MustCompile(`^goos:\s+(\S+)`)
+	reGOARCHLine = regexp.MustCompile(`^goarch:\s+(\S+)`)
+)
+
+// reTerminalLine matches the lines go test prints when a package finishes:
+// "PASS", "FAIL", "ok  <pkg> <time>" and "FAIL <pkg> <time>".
+var reTerminalLine = regexp.MustCompile(`^(?:PASS|FAIL)\s*$|^(?:ok|FAIL)\s+\S+`)
+
+// reOkLine matches the "ok  <pkg> <seconds>s" line and captures the
+// package's wall-clock test duration.
+var reOkLine = regexp.MustCompile(`^ok\s+(\S+)\s+(\d+(?:\.\d+)?)s\b`)
+
+// ErrNoResults is returned when the output holds no benchmark result line.
+var ErrNoResults = errors.New("no benchmark results found in output")
+
 // OutputMetadata contains metadata extracted from go test benchmark output headers.
 type OutputMetadata struct {
-	// CPU is the CPU model string extracted from the "cpu: ..." line.
-	// Empty if the line was not present in the output.
+	// CPU is the CPU model string extracted from the first "cpu: ..."
+	// line. Empty if the line was not present in the output.
 	CPU string
+
+	// GOOS and GOARCH are taken from the first "goos: ..." and
+	// "goarch: ..." lines. Empty if absent.
+	GOOS   string
+	GOARCH string
+
+	// PackageCPUs maps each package to the CPU model of the "cpu: ..." line
+	// in its header, so that differing lines within one output (see
+	// CPUConflicts) are not silently reduced to the first. Lines before any
+	// "pkg:" line are recorded under "".
+	PackageCPUs map[string]string
+
+	// Complete reports whether the last package in the output was followed
+	// by a PASS/ok/FAIL line. It is false when the stream ended abruptly,
+	// e.g. because the producer of a pipe died mid-run.
+	Complete bool
+
+	// PackageDurations holds the wall-clock duration go test reported for
+	// each package on its "ok" line. Nil if no such line was present.
+	PackageDurations map[string]time.Duration
+}
+
+// CPUConflicts returns the distinct CPU models of PackageCPUs, sorted, if
+// there is more than one; otherwise nil. A conflict means CPU, the model the
+// whole entry is attributed to, is wrong for some of the results.
+func (m OutputMetadata) CPUConflicts() []string {
+	seen := make(map[string]struct{})
+	var models []string
+	for _, cpu := range m.PackageCPUs {
+		if _, ok := seen[cpu]; !ok {
+			seen[cpu] = struct{}{}
+			models = append(models, cpu)
+		}
+	}
+	if len(models) < 2 {
+		return nil
+	}
+	sort.Strings(models)
+	return models
 }
 
 // ParseGoBenchOutput parses the output of `go test -bench` and returns a slice
@@ -43,10 +124,131 @@ func ParseGoBenchOutput(r io.Reader) ([]model.BenchmarkResult, error) {
 // both the benchmark results and any metadata extracted from the output headers
 // (such as the CPU model from the "cpu: ..." line).
 func ParseGoBenchOutputWithMeta(r io.Reader) ([]model.BenchmarkResult, OutputMetadata, error) {
+	results, meta, _, err := ParseGoBenchOutputDetailed(r)
+	return results, meta, err
+}
+
+// SkipReason describes why the parser ignored a line or value.
+type SkipReason string
+
+const (
+	// SkipMalformedLine marks a line that starts like a benchmark result
+	// but does not have the "Name iterations value unit" shape.
+	SkipMalformedLine SkipReason = "malformed benchmark line"
+	// SkipOddFields marks a result line whose metrics do not come in
+	// value/unit pairs.
+	SkipOddFields SkipReason = "odd number of value/unit fields"
+	// SkipBadValue marks a result line with a metric value that is not a
+	// number. None of the metrics of the line are kept.
+	SkipBadValue SkipReason = "unparseable metric value"
+	// SkipUnknownUnit marks a single metric whose unit is not in
+	// ParseOptions.AllowedUnits.
+	SkipUnknownUnit SkipReason = "unit not in allow-list"
+)
+
+// maxSkippedSamples caps how many skipped lines ParseResult keeps verbatim.
+const maxSkippedSamples = 10
+
+// SkippedLine is one line (or metric) the parser ignored.
+type SkippedLine struct {
+	Line   int        `json:"line"`
+	Text   string     `json:"text"`
+	Reason SkipReason `json:"reason"`
+}
+
+// ParseResult reports what the parser skipped. Skipped counts every skip;
+// Samples holds only the first few so that noisy logs stay bounded.
+// UnknownUnits counts the skips due to ParseOptions.AllowedUnits.
+type ParseResult struct {
+	Skipped      int           `json:"skipped"`
+	UnknownUnits int           `json:"unknownUnits,omitempty"`
+	Samples      []SkippedLine `json:"samples,omitempty"`
+}
+
+func (p *ParseResult) skip(line int, text string, reason SkipReason) {
+	p.Skipped++
+	if reason == SkipUnknownUnit {
+		p.UnknownUnits++
+	}
+	if len(p.Samples) < maxSkippedSamples {
+		p.Samples = append(p.Samples, SkippedLine{Line: line, Text: text, Reason: reason})
+	}
+}
+
+// Summary returns a one-line human-readable description of the skipped
+// lines, or "" if nothing was skipped.
+func (p ParseResult) Summary() string {
+	if p.Skipped == 0 {
+		return ""
+	}
+	first := p.Samples[0]
+	return fmt.Sprintf("skipped %d malformed line(s); first: line %d (%s): %s",
+		p.Skipped, first.Line, first.Reason, first.Text)
+}
+
+// ParseOptions adjusts how ParseGoBenchOutputWithOptions treats the output.
+// The zero value parses like ParseGoBenchOutputDetailed.
+type ParseOptions struct {
+	// AllowedUnits, if non-empty, is the fixed vocabulary of metric units.
+	// Metrics in any other unit, e.g. a typo'd "ns/opp" from
+	// b.ReportMetric, are skipped with SkipUnknownUnit instead of creating
+	// a new series.
+	AllowedUnits []string
+
+	// Aggregate collapses repeated (package, name, procs, unit) results, as
+	// printed by go test -count=N, into one result per metric with the
+	// mean, sample standard deviation and sample count; see
+	// AggregateSamples. DiscardFirst also drops the first sample of each
+	// benchmark and implies Aggregate.
+	Aggregate    bool
+	DiscardFirst bool
+
+	// Packages, if non-empty, keeps only results of packages whose import
+	// path matches one of these path.Match globs, e.g.
+	// "example.com/m/internal/*". Other packages are dropped silently: their
+	// results and malformed lines are not reported as skipped, and their
+	// "cpu: ..." lines set neither CPU nor PackageCPUs.
+	Packages []string
+
+	// InlineMemMetrics attaches the B/op and allocs/op metrics of a line
+	// to its ns/op result as BytesPerOp and AllocsPerOp instead of
+	// emitting them as separate "Name - B/op" and "Name - allocs/op"
+	// results. Lines without ns/op are unaffected.
+	InlineMemMetrics bool
+}
+
+// allowsUnit reports whether unit passes the AllowedUnits allow-list.
+func (o ParseOptions) allowsUnit(unit string) bool {
+	return len(o.AllowedUnits) == 0 || slices.Contains(o.AllowedUnits, unit)
+}
+
+// keepPackage reports whether results of pkg pass the Packages filter.
+func (o ParseOptions) keepPackage(pkg string) bool {
+	if len(o.Packages) == 0 {
+		return true
+	}
+	for _, pattern := range o.Packages {
+		if ok, _ := path.Match(pattern, pkg); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseGoBenchOutputDetailed is like ParseGoBenchOutputWithMeta but also
+// reports the lines and values it had to skip.
+func ParseGoBenchOutputDetailed(r io.Reader) ([]model.BenchmarkResult, OutputMetadata, ParseResult, error) {
+	return ParseGoBenchOutputWithOptions(r, ParseOptions{})
+}
+
+// ParseGoBenchOutputWithOptions is ParseGoBenchOutputDetailed with opts
+// applied.
+func ParseGoBenchOutputWithOptions(r io.Reader, opts ParseOptions) ([]model.BenchmarkResult, OutputMetadata, ParseResult, error) {
 	scanner := bufio.NewScanner(r)
 
 	var results []model.BenchmarkResult
 	var meta OutputMetadata
+	var pr ParseResult
 	var currentPkg string
 
 	// First pass: collect all lines.
@@ -56,29 +258,78 @@ func ParseGoBenchOutputWithMeta(r io.Reader) ([]model.BenchmarkResult, OutputMet
 		lines = append(lines, line)
 	}
 	if err := scanner.Err(); err != nil {
-		return nil, meta, fmt.Errorf("reading benchmark output: %w", err)
+		return nil, meta, pr, fmt.Errorf("reading benchmark output: %w", err)
 	}
 
-	for _, line := range lines {
+	for i, line := range lines {
+		lineNo := i + 1
+
 		// Strip Windows-style carriage returns.
 		line = strings.TrimRight(line, "\r")
 
 		// Track current package.
 		if m := rePkgLine.FindStringSubmatch(line); m != nil {
 			currentPkg = m[1]
+			meta.Complete = false
+			continue
+		}
+
+		if m := reOkLine.FindStringSubmatch(line); m != nil {
+			if secs, err := strconv.ParseFloat(m[2], 64); err == nil {
+				if meta.PackageDurations == nil {
+					meta.PackageDurations = make(map[string]time.Duration)
+				}
+				meta.PackageDurations[m[1]] += time.Duration(secs * float64(time.Second))
+			}
+		}
+
+		if reTerminalLine.MatchString(line) {
+			meta.Complete = true
+			continue
+		}
+
+		if m := reGOOSLine.FindStringSubmatch(line); m != nil {
+			if meta.GOOS == "" {
+				meta.GOOS = m[1]
+			}
+			continue
+		}
+		if m := reGOARCHLine.FindStringSubmatch(line); m != nil {
+			if meta.GOARCH == "" {
+				meta.GOARCH = m[1]
+			}
 			continue
 		}
 
 		// Extract CPU metadata from the "cpu: ..." header line.
 		if m := reCPULine.FindStringSubmatch(line); m != nil {
+			if !opts.keepPackage(currentPkg) {
+				continue
+			}
+			cpu := strings.TrimSpace(m[1])
 			if meta.CPU == "" {
-				meta.CPU = strings.TrimSpace(m[1])
+				meta.CPU = cpu
 			}
+			if meta.PackageCPUs == nil {
+				meta.PackageCPUs = make(map[string]string)
+			}
+			meta.PackageCPUs[currentPkg] = cpu
 			continue
 		}
 
 		m := reGoBench.FindStringSubmatch(line)
 		if m == nil {
+			// A bare benchmark name on its own line is normal in -v output,
+			// and so are log lines that merely start with "Benchmark";
+			// anything longer that starts with a name is worth reporting.
+			if fields := strings.Fields(line); len(fields) > 1 && reBenchName.MatchString(fields[0]) && opts.keepPackage(currentPkg) {
+				pr.skip(lineNo, line, SkipMalformedLine)
+			}
+			continue
+		}
+
+		meta.Complete = false
+		if !opts.keepPackage(currentPkg) {
 			continue
 		}
 
@@ -101,23 +352,66 @@ func ParseGoBenchOutputWithMeta(r io.Reader) ([]model.BenchmarkResult, OutputMet
 		// The remainder looks like: "41653 ns/op  128 B/op  2 allocs/op"
 		fields := strings.Fields(rest)
 		if len(fields) < 2 || len(fields)%2 != 0 {
-			continue // malformed line, skip
+			pr.skip(lineNo, line, SkipOddFields)
+			continue
 		}
 
-		pairs := make([][2]string, 0, len(fields)/2)
+		// Every value must be a number; otherwise the line is output that
+		// merely looks like a result (e.g. "BenchmarkFoo 3 items in 5 s")
+		// and none of its pairs is kept.
+		type pair struct {
+			value float64
+			unit  string
+		}
+		pairs := make([]pair, 0, len(fields)/2)
 		for i := 0; i < len(fields); i += 2 {
-			pairs = append(pairs, [2]string{fields[i], fields[i+1]})
+			val, ok := parseValue(fields[i])
+			if !ok {
+				break
+			}
+			pairs = append(pairs, pair{val, fields[i+1]})
+		}
+		if len(pairs) != len(fields)/2 {
+			pr.skip(lineNo, line, SkipBadValue)
+			continue
 		}
 
-		for i, pair := range pairs {
-			val, err := strconv.ParseFloat(pair[0], 64)
-			if err != nil {
-				continue // skip unparseable values
+		// The ns/op metric is the primary one and keeps the bare benchmark
+		// name. Benchmarks that only report custom metrics (b.ReportMetric
+		// without ns/op) have no primary metric: every metric is named
+		// "Name - unit" so that none of them silently poses as the timing.
+		primary := -1
+		for i, p := range pairs {
+			if p.unit == "ns/op" {
+				primary = i
+				break
+			}
+		}
+
+		inline := opts.InlineMemMetrics && primary >= 0 && opts.allowsUnit("ns/op")
+		primaryIdx := -1
+		var bytesPerOp, allocsPerOp *float64
+
+		for i, p := range pairs {
+			val, unit := p.value, p.unit
+			if !opts.allowsUnit(unit) {
+				pr.skip(lineNo, line, SkipUnknownUnit)
+				continue
+			}
+			if inline && unit == "B/op" {
+				bytesPerOp = &val
+				continue
+			}
+			if inline && unit == "allocs/op" {
+				allocsPerOp = &val
+				continue
+			}
+			if i == primary {
+				primaryIdx = len(results)
 			}
-			unit := pair[1]
 
 			resultName := name
-			if i > 0 {
+			if i != primary {
 				resultName = name + " - " + unit
 			}
 
@@ -128,13 +422,33 @@ func ParseGoBenchOutputWithMeta(r io.Reader) ([]model.BenchmarkResult, OutputMet
 				Extra:   extra,
 				Package: currentPkg,
 				Procs:   procs,
+				Custom:  !model.IsStandardUnit(unit),
 			})
 		}
+		if primaryIdx >= 0 {
+			results[primaryIdx].BytesPerOp = bytesPerOp
+			results[primaryIdx].AllocsPerOp = allocsPerOp
+		}
 	}
 
 	if len(results) == 0 {
-		return nil, meta, fmt.Errorf("no benchmark results found in output")
+		return nil, meta, pr, ErrNoResults
 	}
+	if opts.Aggregate || opts.DiscardFirst {
+		results = AggregateSamples(results, opts.DiscardFirst)
+	}
+
+	return results, meta, pr, nil
+}
 
-	return results, meta, nil
+// parseValue parses a metric value token, see reValue.
+func parseValue(s string) (float64, bool) {
+	if !reValue.MatchString(s) {
+		return 0, false
+	}
+	val, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return val, true
 }
@@ -1,6 +1,10 @@
 package parse
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 
@@ -235,6 +239,72 @@ PASS
 	})
 }
 
+func TestParseGoBenchOutput_SubNanosecondAndScientific(t *testing.T) {
+	input := `pkg: example.com/m
+BenchmarkNoop-8         1000000000               0.3456 ns/op
+BenchmarkSlow-8                1            1.2e+03 ns/op      5E-7 ratio
+BenchmarkTiny-8         1000000000               .25 ns/op          2.5e-01 B/op
+PASS
+`
+
+	results, err := ParseGoBenchOutput(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		name  string
+		value float64
+		unit  string
+	}{
+		{"BenchmarkNoop", 0.3456, "ns/op"},
+		{"BenchmarkSlow", 1200, "ns/op"},
+		{"BenchmarkSlow - ratio", 5e-7, "ratio"},
+		{"BenchmarkTiny", 0.25, "ns/op"},
+		{"BenchmarkTiny - B/op", 0.25, "B/op"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %d: %+v", len(want), len(results), results)
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Name != w.name || r.Value != w.value || r.Unit != w.unit {
+			t.Errorf("result %d: got %s = %v %s, want %s = %v %s", i, r.Name, r.Value, r.Unit, w.name, w.value, w.unit)
+		}
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"41653", 41653, true},
+		{"0.3456", 0.3456, true},
+		{"1.2e+03", 1200, true},
+		{"1.2E-03", 0.0012, true},
+		{"5e7", 5e7, true},
+		{"3.", 3, true},
+		{"-0.5", -0.5, true},
+		{"1.2e+", 0, false},
+		{"e+03", 0, false},
+		{"NaN", 0, false},
+		{"Inf", 0, false},
+		{"0x1p-2", 0, false},
+		{"1_000", 0, false},
+		{"1e999", 0, false},
+		{"ns/op", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseValue(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseValue(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
 func TestParseGoBenchOutput_MBPerSec(t *testing.T) {
 	input := `goos: linux
 goarch: amd64
@@ -472,6 +542,41 @@ PASS
 	})
 }
 
+func TestParseGoBenchOutput_ConflictingCPULines(t *testing.T) {
+	input := `goos: linux
+goarch: amd64
+pkg: github.com/user/repo/a
+cpu: AMD Ryzen 9 5950X 16-Core Processor
+BenchmarkA-32      2000000               350 ns/op
+ok  	github.com/user/repo/a	1.0s
+pkg: github.com/user/repo/vendored
+cpu: Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz
+BenchmarkB-32      1000000               700 ns/op
+ok  	github.com/user/repo/vendored	1.0s
+`
+
+	_, meta, err := ParseGoBenchOutputWithMeta(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if meta.CPU != "AMD Ryzen 9 5950X 16-Core Processor" {
+		t.Errorf("CPU: got %q, want the first cpu: line", meta.CPU)
+	}
+	if got := meta.PackageCPUs["github.com/user/repo/vendored"]; got != "Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz" {
+		t.Errorf("PackageCPUs[vendored]: got %q", got)
+	}
+	want := []string{"AMD Ryzen 9 5950X 16-Core Processor", "Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz"}
+	if got := meta.CPUConflicts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CPUConflicts(): got %v, want %v", got, want)
+	}
+
+	// Identical cpu: lines in every package are no conflict.
+	same := strings.ReplaceAll(input, "Intel(R) Xeon(R) Platinum 8375C CPU @ 2.90GHz", "AMD Ryzen 9 5950X 16-Core Processor")
+	if _, meta, _ = ParseGoBenchOutputWithMeta(strings.NewReader(same)); meta.CPUConflicts() != nil {
+		t.Errorf("CPUConflicts() with identical lines: got %v, want nil", meta.CPUConflicts())
+	}
+}
+
 func TestParseGoBenchOutput_DifferentProcsValues(t *testing.T) {
 	// Benchmarks run with different GOMAXPROCS values
 	input := `goos: linux
@@ -513,6 +618,345 @@ PASS
 	}
 }
 
+func TestParseGoBenchOutput_NoNsPerOp(t *testing.T) {
+	// A benchmark reporting only custom metrics has no primary metric, so
+	// every metric carries its unit in the name.
+	input := `pkg: github.com/user/repo
+BenchmarkQueue-8      5000        1200 items/op        3 allocs/op
+PASS
+`
+
+	results, err := ParseGoBenchOutput(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	assertResult(t, results[0], model.BenchmarkResult{
+		Name:    "BenchmarkQueue - items/op",
+		Value:   1200,
+		Unit:    "items/op",
+		Extra:   "5000 times\n8 procs",
+		Package: "github.com/user/repo",
+		Procs:   8,
+		Custom:  true,
+	})
+
+	assertResult(t, results[1], model.BenchmarkResult{
+		Name:    "BenchmarkQueue - allocs/op",
+		Value:   3,
+		Unit:    "allocs/op",
+		Extra:   "5000 times\n8 procs",
+		Package: "github.com/user/repo",
+		Procs:   8,
+	})
+}
+
+func TestParseGoBenchOutput_NsPerOpNotFirst(t *testing.T) {
+	// ns/op stays the primary metric even when a custom metric precedes it.
+	input := `pkg: github.com/user/repo
+BenchmarkCustom-8      5000        42 items/op        1500 ns/op
+PASS
+`
+
+	results, err := ParseGoBenchOutput(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	if results[0].Name != "BenchmarkCustom - items/op" {
+		t.Errorf("custom metric name: got %q", results[0].Name)
+	}
+	if results[1].Name != "BenchmarkCustom" || results[1].Unit != "ns/op" {
+		t.Errorf("primary metric: got %q (%s)", results[1].Name, results[1].Unit)
+	}
+}
+
+func TestParseGoBenchOutputDetailed_SkipReasons(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		reason SkipReason
+	}{
+		{"missing iterations", "BenchmarkFoo-8   fast   123 ns/op", SkipMalformedLine},
+		{"odd fields", "BenchmarkFoo-8   1000   123 ns/op   64", SkipOddFields},
+		{"bad value", "BenchmarkFoo-8   1000   abc ns/op", SkipBadValue},
+		{"NaN value", "BenchmarkFoo-8   1000   NaN ns/op", SkipBadValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "pkg: example.com/m\n" + tt.line + "\nBenchmarkOK-8   10   5 ns/op\n"
+			results, _, pr, err := ParseGoBenchOutputDetailed(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(results) != 1 {
+				t.Errorf("expected only the valid result, got %d", len(results))
+			}
+			if pr.Skipped != 1 || len(pr.Samples) != 1 {
+				t.Fatalf("expected 1 skipped line, got %+v", pr)
+			}
+			got := pr.Samples[0]
+			if got.Reason != tt.reason || got.Line != 2 || got.Text != tt.line {
+				t.Errorf("got %+v, want reason %q on line 2", got, tt.reason)
+			}
+		})
+	}
+}
+
+func TestParseGoBenchOutputDetailed_VerboseOutput(t *testing.T) {
+	// go test -v -bench=. interleaves test and benchmark logs with results.
+	input := `=== RUN   TestEncode
+    codec_test.go:12: encoding 3 items
+--- PASS: TestEncode (0.00s)
+=== RUN   TestDecode
+--- PASS: TestDecode (0.01s)
+goos: linux
+goarch: amd64
+pkg: example.com/m/codec
+cpu: Intel(R) Xeon(R) CPU
+BenchmarkEncode
+BenchmarkEncode: warming up the encoder
+BenchmarkEncode: 3 buffers in 5 pools
+BenchmarkEncode-8         	 1000000	      1042 ns/op	     128 B/op	       2 allocs/op
+--- BENCH: BenchmarkEncode-8
+    codec_test.go:40: BenchmarkEncode 12 iterations
+Benchmarking the decoder 10 times
+BenchmarkDecode
+BenchmarkDecode-8 3 runs over 5 files
+BenchmarkDecode-8         	  500000	      2100 ns/op
+Benchmark
+Benchmark-8               	     100	        50 ns/op
+PASS
+ok  	example.com/m/codec	3.210s
+`
+	results, _, pr, err := ParseGoBenchOutputDetailed(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, r := range results {
+		got = append(got, fmt.Sprintf("%s=%v", r.Name, r.Value))
+	}
+	want := []string{
+		"BenchmarkEncode=1042", "BenchmarkEncode - B/op=128", "BenchmarkEncode - allocs/op=2",
+		"BenchmarkDecode=2100",
+		"Benchmark=50",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Only the line that starts with a real benchmark name and iterations
+	// but has non-numeric values is reported; log lines are not.
+	if pr.Skipped != 1 || len(pr.Samples) != 1 {
+		t.Fatalf("got %+v, want 1 skipped line", pr)
+	}
+	if s := pr.Samples[0]; s.Reason != SkipBadValue || s.Text != "BenchmarkDecode-8 3 runs over 5 files" {
+		t.Errorf("got %+v, want the BenchmarkDecode log line as a bad value", s)
+	}
+}
+
+func TestParseGoBenchOutputWithOptions_InlineMemMetrics(t *testing.T) {
+	input := `pkg: example.com/m
+BenchmarkAlloc-8     1000   100 ns/op   64 B/op   0 allocs/op   10.5 MB/s
+BenchmarkCustom-8    1000   3 items/op   16 B/op
+PASS
+`
+	results, _, _, err := ParseGoBenchOutputWithOptions(strings.NewReader(input), ParseOptions{InlineMemMetrics: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Name)
+	}
+	// Without an ns/op result to attach to, B/op stays a result of its own.
+	want := []string{"BenchmarkAlloc", "BenchmarkAlloc - MB/s", "BenchmarkCustom - items/op", "BenchmarkCustom - B/op"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+
+	alloc := results[0]
+	if alloc.BytesPerOp == nil || *alloc.BytesPerOp != 64 {
+		t.Errorf("BytesPerOp: got %v, want 64", alloc.BytesPerOp)
+	}
+	if alloc.AllocsPerOp == nil || *alloc.AllocsPerOp != 0 {
+		t.Errorf("AllocsPerOp: got %v, want 0", alloc.AllocsPerOp)
+	}
+	for _, r := range results[1:] {
+		if r.BytesPerOp != nil || r.AllocsPerOp != nil {
+			t.Errorf("%s: got inline metrics %v/%v, want none", r.Name, r.BytesPerOp, r.AllocsPerOp)
+		}
+	}
+
+	// The default keeps separate results.
+	results, _, _, err = ParseGoBenchOutputWithOptions(strings.NewReader(input), ParseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 6 || results[0].BytesPerOp != nil {
+		t.Errorf("default mode: got %+v, want 6 separate results", results)
+	}
+}
+
+func TestParseGoBenchOutputDetailed_BareNameNotSkipped(t *testing.T) {
+	// go test -v prints the benchmark name alone before its result line.
+	input := "BenchmarkFoo\nBenchmarkFoo-8   1000   123 ns/op\n"
+	_, _, pr, err := ParseGoBenchOutputDetailed(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Skipped != 0 {
+		t.Errorf("expected no skipped lines, got %+v", pr)
+	}
+	if pr.Summary() != "" {
+		t.Errorf("expected empty summary, got %q", pr.Summary())
+	}
+}
+
+func TestParseGoBenchOutputDetailed_SamplesCapped(t *testing.T) {
+	var sb strings.Builder
+	for i := range 25 {
+		fmt.Fprintf(&sb, "BenchmarkBad%d-8   1000   x ns/op\n", i)
+	}
+	sb.WriteString("BenchmarkOK-8   10   5 ns/op\n")
+
+	_, _, pr, err := ParseGoBenchOutputDetailed(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Skipped != 25 {
+		t.Errorf("Skipped: got %d, want 25", pr.Skipped)
+	}
+	if len(pr.Samples) != maxSkippedSamples {
+		t.Errorf("Samples: got %d, want %d", len(pr.Samples), maxSkippedSamples)
+	}
+	if !strings.HasPrefix(pr.Summary(), "skipped 25 malformed line(s); first: line 1") {
+		t.Errorf("unexpected summary: %q", pr.Summary())
+	}
+}
+
+func TestParseGoBenchOutputWithOptions_AllowedUnits(t *testing.T) {
+	input := `BenchmarkFoo-8   1000   123 ns/op   64 B/op   2 allocs/op
+BenchmarkBar-8   1000   5 ns/opp   10 items/op
+`
+	opts := ParseOptions{AllowedUnits: []string{"ns/op", "B/op", "allocs/op", "items/op"}}
+	results, _, pr, err := ParseGoBenchOutputWithOptions(strings.NewReader(input), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Name)
+	}
+	want := []string{"BenchmarkFoo", "BenchmarkFoo - B/op", "BenchmarkFoo - allocs/op", "BenchmarkBar - items/op"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+	if pr.UnknownUnits != 1 || pr.Skipped != 1 {
+		t.Errorf("got UnknownUnits=%d Skipped=%d, want 1 and 1", pr.UnknownUnits, pr.Skipped)
+	}
+	if len(pr.Samples) != 1 || pr.Samples[0].Reason != SkipUnknownUnit || pr.Samples[0].Line != 2 {
+		t.Errorf("got samples %+v, want the typo'd unit on line 2", pr.Samples)
+	}
+}
+
+func TestParseGoBenchOutputWithOptions_Packages(t *testing.T) {
+	input := `pkg: example.com/m/internal/codec
+cpu: Test CPU
+BenchmarkEncode-8   1000   100 ns/op
+pkg: example.com/m/internal/codec/v2
+cpu: Test CPU
+BenchmarkEncodeV2-8   1000   90 ns/op
+pkg: example.com/m/cmd/tool
+cpu: Other CPU
+BenchmarkMain-8   1000   5 ns/op   not-a-number x
+BenchmarkMain-8   truncated
+pkg: example.com/m/hot
+cpu: Test CPU
+BenchmarkPath-8   1000   7 ns/op
+PASS
+`
+	tests := []struct {
+		name     string
+		packages []string
+		want     []string
+	}{
+		{"empty keeps all", nil, []string{"BenchmarkEncode", "BenchmarkEncodeV2", "BenchmarkPath"}},
+		{"glob does not cross slash", []string{"example.com/m/internal/*"}, []string{"BenchmarkEncode"}},
+		{"several globs", []string{"example.com/m/internal/codec*", "example.com/m/hot"}, []string{"BenchmarkEncode", "BenchmarkPath"}},
+		{"nothing matches", []string{"example.com/other/*"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, meta, pr, err := ParseGoBenchOutputWithOptions(strings.NewReader(input), ParseOptions{Packages: tt.packages})
+			if tt.want == nil {
+				if !errors.Is(err, ErrNoResults) {
+					t.Errorf("got error %v, want ErrNoResults", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, r := range results {
+				names = append(names, r.BaseName())
+			}
+			names = slices.Compact(names)
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("got %v, want %v", names, tt.want)
+			}
+			// Dropped packages are not reported, even if malformed, and
+			// their cpu: lines raise no conflict.
+			if len(tt.packages) > 0 && pr.Skipped != 0 {
+				t.Errorf("got %d skipped, want 0", pr.Skipped)
+			}
+			if len(tt.packages) > 0 && (meta.CPU != "Test CPU" || meta.CPUConflicts() != nil) {
+				t.Errorf("got CPU %q with conflicts %v, want Test CPU without", meta.CPU, meta.CPUConflicts())
+			}
+		})
+	}
+}
+
+func TestParseGoBenchOutput_Completeness(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		complete bool
+	}{
+		{"pass and ok", "pkg: a\nBenchmarkFoo-8 10 5 ns/op\nPASS\nok  \ta\t1.2s\n", true},
+		{"ok only", "pkg: a\nBenchmarkFoo-8 10 5 ns/op\nok  \ta\t1.2s\n", true},
+		{"fail", "pkg: a\nBenchmarkFoo-8 10 5 ns/op\nFAIL\ta\t1.2s\n", true},
+		{"truncated after result", "pkg: a\nBenchmarkFoo-8 10 5 ns/op\n", false},
+		{"truncated in second package", "pkg: a\nBenchmarkFoo-8 10 5 ns/op\nPASS\nok  \ta\t1s\npkg: b\nBenchmarkBar-8 10 5 ns/op\n", false},
+		{"truncated after pkg line", "pkg: a\nBenchmarkFoo-8 10 5 ns/op\nok  \ta\t1s\npkg: b\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, meta, err := ParseGoBenchOutputWithMeta(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if meta.Complete != tt.complete {
+				t.Errorf("Complete = %v, want %v", meta.Complete, tt.complete)
+			}
+		})
+	}
+}
+
 func assertResult(t *testing.T, got, want model.BenchmarkResult) {
 	t.Helper()
 	if got.Name != want.Name {
@@ -533,4 +977,7 @@ func assertResult(t *testing.T, got, want model.BenchmarkResult) {
 	if got.Procs != want.Procs {
 		t.Errorf("procs for %s: got %d, want %d", want.Name, got.Procs, want.Procs)
 	}
+	if got.Custom != want.Custom {
+		t.Errorf("custom for %s: got %v, want %v", want.Name, got.Custom, want.Custom)
+	}
 }
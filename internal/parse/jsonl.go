@@ -0,0 +1,86 @@
+package parse
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// jsonLine is one record of the JSON-lines input format.
+type jsonLine struct {
+	Name    string   `json:"name"`
+	Value   *float64 `json:"value"`
+	Unit    string   `json:"unit"`
+	Package string   `json:"package"`
+	Procs   int      `json:"procs"`
+	Extra   string   `json:"extra"`
+}
+
+// ParseJSONLines parses benchmark results written one JSON object per line,
+// e.g. {"name":"BenchmarkFoo","value":123,"unit":"ns/op"}, as emitted by
+// custom harnesses. "package", "procs" and "extra" are optional.
+//
+// Results are named like those of ParseGoBenchOutput: a metric in ns/op
+// keeps the bare name and any other unit is stored as "Name - unit", so the
+// two formats feed the same series. Blank lines are ignored; lines that are
+// not valid JSON or lack a name, value or unit are skipped and reported in
+// the ParseResult. It returns ErrNoResults if no line yields a result.
+func ParseJSONLines(r io.Reader) ([]model.BenchmarkResult, ParseResult, error) {
+	var (
+		results []model.BenchmarkResult
+		pr      ParseResult
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var rec jsonLine
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			pr.skip(lineNo, line, SkipMalformedLine)
+			continue
+		}
+		if rec.Name == "" || rec.Unit == "" || rec.Value == nil {
+			pr.skip(lineNo, line, SkipMalformedLine)
+			continue
+		}
+		if math.IsNaN(*rec.Value) || math.IsInf(*rec.Value, 0) {
+			pr.skip(lineNo, line, SkipBadValue)
+			continue
+		}
+
+		name := rec.Name
+		if rec.Unit != "ns/op" {
+			name += " - " + rec.Unit
+		}
+		procs := rec.Procs
+		if procs < 1 {
+			procs = 1
+		}
+		results = append(results, model.BenchmarkResult{
+			Name:    name,
+			Value:   *rec.Value,
+			Unit:    rec.Unit,
+			Extra:   rec.Extra,
+			Package: rec.Package,
+			Procs:   procs,
+			Custom:  !model.IsStandardUnit(rec.Unit),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, pr, fmt.Errorf("reading benchmark output: %w", err)
+	}
+
+	if len(results) == 0 {
+		return nil, pr, ErrNoResults
+	}
+	return results, pr, nil
+}
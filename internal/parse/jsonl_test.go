@@ -0,0 +1,60 @@
+package parse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONLines(t *testing.T) {
+	input := `{"name":"BenchmarkEncode","value":1200,"unit":"ns/op","package":"example.com/m","procs":8}
+{"name":"BenchmarkEncode","value":256,"unit":"B/op","package":"example.com/m","procs":8}
+
+not json
+{"name":"BenchmarkMissingValue","unit":"ns/op"}
+{"value":3,"unit":"ns/op"}
+{"name":"BenchmarkThroughput","value":98.5,"unit":"MB/s"}
+`
+	results, pr, err := ParseJSONLines(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		value float64
+		unit  string
+		pkg   string
+		procs int
+	}{
+		{"BenchmarkEncode", 1200, "ns/op", "example.com/m", 8},
+		{"BenchmarkEncode - B/op", 256, "B/op", "example.com/m", 8},
+		{"BenchmarkThroughput - MB/s", 98.5, "MB/s", "", 1},
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(tests), results)
+	}
+	for i, tt := range tests {
+		r := results[i]
+		if r.Name != tt.name || r.Value != tt.value || r.Unit != tt.unit || r.Package != tt.pkg || r.Procs != tt.procs {
+			t.Errorf("result %d: got %+v, want %+v", i, r, tt)
+		}
+	}
+
+	if pr.Skipped != 3 {
+		t.Errorf("skipped: got %d, want 3", pr.Skipped)
+	}
+	if len(pr.Samples) == 0 || pr.Samples[0].Line != 4 {
+		t.Errorf("first skipped sample: got %+v, want line 4", pr.Samples)
+	}
+}
+
+func TestParseJSONLines_NoResults(t *testing.T) {
+	_, pr, err := ParseJSONLines(strings.NewReader("garbage\n{}\n"))
+	if !errors.Is(err, ErrNoResults) {
+		t.Errorf("got error %v, want ErrNoResults", err)
+	}
+	if pr.Skipped != 2 {
+		t.Errorf("skipped: got %d, want 2", pr.Skipped)
+	}
+}
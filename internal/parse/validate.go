@@ -0,0 +1,61 @@
+package parse
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// Bounds on the ratio between the time accounted for by the parsed ns/op
+// results of a package (iterations × ns/op, summed) and the duration go test
+// reported for it. Benchmarks cannot have measured more time than the
+// package took to run, and the final b.N round of each benchmark normally
+// takes a sizeable share of the run, so ratios far outside these bounds
+// indicate misaligned columns rather than real measurements.
+const (
+	maxTimingRatio = 1.5
+	minTimingRatio = 1e-3
+)
+
+// ValidateTiming cross-checks the parsed ns/op results of each package
+// against the package's duration from its "ok" line and returns a warning
+// for every package whose numbers cannot both be right. Packages without a
+// duration or without ns/op results are not checked.
+func ValidateTiming(results []model.BenchmarkResult, meta OutputMetadata) []string {
+	measured := make(map[string]time.Duration)
+	for _, r := range results {
+		if r.Unit != "ns/op" {
+			continue
+		}
+		samples := max(r.Samples, 1)
+		measured[r.Package] += time.Duration(float64(r.Iterations()) * r.Value * float64(samples))
+	}
+
+	pkgs := make([]string, 0, len(measured))
+	for pkg := range measured {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+
+	var warnings []string
+	for _, pkg := range pkgs {
+		elapsed, ok := meta.PackageDurations[pkg]
+		if !ok || elapsed <= 0 {
+			continue
+		}
+		ratio := float64(measured[pkg]) / float64(elapsed)
+		switch {
+		case ratio > maxTimingRatio:
+			warnings = append(warnings, fmt.Sprintf(
+				"%s: benchmarks account for %v but the package ran for %v; iterations or ns/op are likely misparsed",
+				pkg, measured[pkg], elapsed))
+		case ratio < minTimingRatio:
+			warnings = append(warnings, fmt.Sprintf(
+				"%s: benchmarks account for only %v of the package's %v; iterations or ns/op are likely misparsed",
+				pkg, measured[pkg], elapsed))
+		}
+	}
+	return warnings
+}
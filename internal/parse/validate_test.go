@@ -0,0 +1,61 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTiming(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantWarn int
+	}{
+		{
+			name: "plausible",
+			input: `pkg: example.com/fast
+BenchmarkNoop-8   	1000000000	         0.2900 ns/op
+BenchmarkHash-8   	 3000000	       400.0 ns/op
+PASS
+ok  	example.com/fast	2.731s
+`,
+		},
+		{
+			name: "near-zero time for a million iterations",
+			input: `pkg: example.com/odd
+BenchmarkFoo-8   	1000000	         0.0001 ns/op
+PASS
+ok  	example.com/odd	1.204s
+`,
+			wantWarn: 1,
+		},
+		{
+			name: "more time measured than elapsed",
+			input: `pkg: example.com/odd
+BenchmarkFoo-8   	1000000	     50000 ns/op
+PASS
+ok  	example.com/odd	1.204s
+`,
+			wantWarn: 1,
+		},
+		{
+			name: "no ok line",
+			input: `pkg: example.com/odd
+BenchmarkFoo-8   	1000000	         0.0001 ns/op
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, meta, err := ParseGoBenchOutputWithMeta(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			warnings := ValidateTiming(results, meta)
+			if len(warnings) != tt.wantWarn {
+				t.Errorf("got %d warning(s) %q, want %d", len(warnings), warnings, tt.wantWarn)
+			}
+		})
+	}
+}
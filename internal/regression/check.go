@@ -0,0 +1,133 @@
+package regression
+
+import (
+	"path"
+	"sort"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// Result is the outcome of checking one benchmark series of a new entry.
+//
+// Suppressed is set instead of Regressed when the policy flagged the change
+// but the commit is known to change the workload (see Suppress) or falls in
+// a suppression window (see SuppressMatching); SuppressReason says which.
+// Pinned reports that Previous is a pinned baseline (see CheckEntryPinned).
+// Config is the run configuration of the checked entry (see
+// model.BenchmarkEntry.ConfigKey).
+type Result struct {
+	Config         model.EntryKeyValue
+	Series         model.SeriesKey
+	Previous       model.HistoryPoint
+	Current        model.HistoryPoint
+	Regressed      bool
+	Suppressed     bool
+	SuppressReason string
+	Pinned         bool
+	Message        string
+}
+
+// CheckEntry applies policy to every benchmark of entry, using the
+// comparable history found in data (entries with the same ConfigKey, i.e.
+// identical RunParams and experiment tags, and an older or equal commit
+// date). Points recorded for entry's own commit are
+// ignored so that re-running a commit does not compare against itself.
+//
+// Benchmarks without any prior point are skipped.
+func CheckEntry(policy Policy, data model.BranchData, entry model.BenchmarkEntry) []Result {
+	return CheckEntryPinned(policy, data, entry, nil)
+}
+
+// CheckEntryPinned is CheckEntry with per-benchmark pinned baselines. pins
+// maps path.Match globs over benchmark names (full or base name, see
+// model.BenchmarkResult.BaseName) to a commit SHA. A matching benchmark is
+// compared against its point at the pinned commit, with the history up to
+// that point, instead of against the previous point; its Result has Pinned
+// set. Globs are tried in sorted order and the first match wins. When the
+// pinned commit has no comparable point, the rolling baseline is used.
+func CheckEntryPinned(policy Policy, data model.BranchData, entry model.BenchmarkEntry, pins map[string]string) []Result {
+	patterns := make([]string, 0, len(pins))
+	for p := range pins {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+
+	config := entry.ConfigKey()
+	var results []Result
+	for _, r := range entry.Benchmarks {
+		key := r.SeriesKey()
+
+		var history []model.HistoryPoint
+		for _, p := range data.History(config, key) {
+			if p.SHA == entry.Commit.SHA || p.Date > entry.Date {
+				continue
+			}
+			history = append(history, p)
+		}
+		if len(history) == 0 {
+			continue
+		}
+
+		pinned := false
+		if sha, ok := pinnedSHA(patterns, pins, r); ok {
+			for i, p := range history {
+				if p.SHA == sha {
+					history, pinned = history[:i+1], true
+					break
+				}
+			}
+		}
+
+		prev := history[len(history)-1]
+		cur := entry.Point(r)
+		regressed, msg := policy.Check(prev, cur, history, model.UnitDirection(r.Unit))
+		results = append(results, Result{
+			Config:    config,
+			Series:    key,
+			Previous:  prev,
+			Current:   cur,
+			Regressed: regressed,
+			Pinned:    pinned,
+			Message:   msg,
+		})
+	}
+	return results
+}
+
+// pinnedSHA returns the SHA of the first of patterns matching r.
+func pinnedSHA(patterns []string, pins map[string]string, r model.BenchmarkResult) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, r.Name); ok {
+			return pins[pattern], true
+		}
+		if ok, _ := path.Match(pattern, r.BaseName()); ok {
+			return pins[pattern], true
+		}
+	}
+	return "", false
+}
+
+// Suppress clears Regressed (and sets Suppressed) on every result whose
+// current point belongs to a commit in shas, e.g. commits annotated as
+// intentional workload changes.
+func Suppress(results []Result, shas map[string]struct{}) {
+	SuppressMatching(results, func(r Result) (string, bool) {
+		_, ok := shas[r.Current.SHA]
+		return "known workload change", ok
+	})
+}
+
+// SuppressMatching clears Regressed (and sets Suppressed and
+// SuppressReason) on every regressed result for which match reports true.
+func SuppressMatching(results []Result, match func(Result) (reason string, ok bool)) {
+	for i := range results {
+		if !results[i].Regressed {
+			continue
+		}
+		if reason, ok := match(results[i]); ok {
+			results[i].Regressed = false
+			results[i].Suppressed = true
+			results[i].SuppressReason = reason
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package regression decides whether a new benchmark value is a regression
+// compared to previously stored values of the same series.
+package regression
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/stats"
+)
+
+// Policy decides whether cur regressed relative to prev, the most recent
+// comparable point, given the full comparable history (oldest first, not
+// including cur). It returns true on regression together with a short
+// human-readable explanation.
+//
+// dir is the direction of the series' unit (see model.UnitDirection): an
+// increase is a regression unless dir is model.HigherIsBetter, in which
+// case a decrease is.
+type Policy interface {
+	Check(prev, cur model.HistoryPoint, history []model.HistoryPoint, dir model.Direction) (bool, string)
+}
+
+// worse returns delta, a change from an older to a newer value, signed so
+// that a positive result is a change for the worse in direction dir.
+func worse(delta float64, dir model.Direction) float64 {
+	if dir.Higher() {
+		return -delta
+	}
+	return delta
+}
+
+// PercentPolicy flags a regression when the value got worse by more than
+// Threshold percent relative to the previous point.
+type PercentPolicy struct {
+	Threshold float64
+}
+
+// Check implements Policy.
+func (p PercentPolicy) Check(prev, cur model.HistoryPoint, _ []model.HistoryPoint, dir model.Direction) (bool, string) {
+	if prev.Value == 0 {
+		return false, "previous value is zero, percent change undefined"
+	}
+	delta := (cur.Value - prev.Value) / prev.Value * 100
+	msg := fmt.Sprintf("%+.2f%% (threshold %.2f%%)", delta, p.Threshold)
+	return worse(delta, dir) > p.Threshold, msg
+}
+
+// StdDevPolicy flags a regression when the value is worse than the mean of
+// the history by more than K standard deviations: above it for
+// lower-is-better units, below it for higher-is-better ones. Series with
+// fewer than MinHistory points are never flagged.
+type StdDevPolicy struct {
+	K          float64
+	MinHistory int
+}
+
+// Check implements Policy.
+func (p StdDevPolicy) Check(_, cur model.HistoryPoint, history []model.HistoryPoint, dir model.Direction) (bool, string) {
+	minHistory := max(p.MinHistory, 2)
+	if len(history) < minHistory {
+		return false, fmt.Sprintf("insufficient history (%d < %d points)", len(history), minHistory)
+	}
+
+	values := make([]float64, len(history))
+	for i, h := range history {
+		values[i] = h.Value
+	}
+	mean, stddev := stats.MeanStdDev(values)
+	limit := mean + worse(p.K*stddev, dir)
+	msg := fmt.Sprintf("%.4f vs mean %.4f ± %.4f (limit %.4f at %.2fσ)", cur.Value, mean, stddev, limit, p.K)
+	return worse(cur.Value-limit, dir) > 0, msg
+}
+
+// SigmaPolicy derives each series' tolerance from its own noise: it flags a
+// regression when the value is worse than the mean of the last Window
+// comparable points by more than K standard deviations. Series with fewer than
+// MinHistory points in the window fall back to Fallback, checked against
+// the previous point.
+type SigmaPolicy struct {
+	K          float64
+	Window     int
+	MinHistory int
+	Fallback   PercentPolicy
+}
+
+// Check implements Policy.
+func (p SigmaPolicy) Check(prev, cur model.HistoryPoint, history []model.HistoryPoint, dir model.Direction) (bool, string) {
+	if p.Window > 0 && len(history) > p.Window {
+		history = history[len(history)-p.Window:]
+	}
+	minHistory := max(p.MinHistory, 2)
+	if len(history) < minHistory {
+		regressed, msg := p.Fallback.Check(prev, cur, history, dir)
+		return regressed, fmt.Sprintf("%s; percent fallback, %d < %d points of history", msg, len(history), minHistory)
+	}
+	return StdDevPolicy{K: p.K, MinHistory: minHistory}.Check(prev, cur, history, dir)
+}
+
+// AbsolutePolicy flags a regression when the value got worse by more than
+// Threshold units relative to the previous point.
+type AbsolutePolicy struct {
+	Threshold float64
+}
+
+// Check implements Policy.
+func (p AbsolutePolicy) Check(prev, cur model.HistoryPoint, _ []model.HistoryPoint, dir model.Direction) (bool, string) {
+	delta := cur.Value - prev.Value
+	msg := fmt.Sprintf("%+.4f %s (threshold %.4f)", delta, cur.Unit, p.Threshold)
+	return worse(delta, dir) > p.Threshold, msg
+}
+
+// Defaults of policies constructed by name: the history length StdDevPolicy
+// and SigmaPolicy require, and SigmaPolicy's K and window.
+const (
+	defaultMinHistory = 3
+	DefaultSigma      = 3
+	DefaultWindow     = 20
+)
+
+// policyFactories maps policy names accepted on the command line to
+// constructors taking the policy's single threshold parameter.
+var policyFactories = map[string]func(threshold float64) Policy{
+	"percent": func(threshold float64) Policy {
+		return PercentPolicy{Threshold: threshold}
+	},
+	"stddev": func(threshold float64) Policy {
+		return StdDevPolicy{K: threshold, MinHistory: defaultMinHistory}
+	},
+	"absolute": func(threshold float64) Policy {
+		return AbsolutePolicy{Threshold: threshold}
+	},
+	"sigma": func(threshold float64) Policy {
+		return SigmaPolicy{
+			K:          DefaultSigma,
+			Window:     DefaultWindow,
+			MinHistory: defaultMinHistory,
+			Fallback:   PercentPolicy{Threshold: threshold},
+		}
+	},
+}
+
+// PolicyNames returns the names accepted by ByName, sorted.
+func PolicyNames() []string {
+	names := make([]string, 0, len(policyFactories))
+	for name := range policyFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ByName constructs the policy registered under name. The meaning of
+// threshold depends on the policy: a percentage for "percent", a number of
+// standard deviations for "stddev" and a value delta for "absolute". For
+// "sigma" it is the percentage of the fallback used for short histories;
+// K and Window take their defaults and may be changed on the result.
+func ByName(name string, threshold float64) (Policy, error) {
+	factory, ok := policyFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown regression policy %q (available: %v)", name, PolicyNames())
+	}
+	return factory(threshold), nil
+}
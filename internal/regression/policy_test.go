@@ -0,0 +1,237 @@
+package regression
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func pt(v float64) model.HistoryPoint {
+	return model.HistoryPoint{Value: v, Unit: "ns/op"}
+}
+
+func pts(values ...float64) []model.HistoryPoint {
+	out := make([]model.HistoryPoint, len(values))
+	for i, v := range values {
+		out[i] = pt(v)
+	}
+	return out
+}
+
+func TestPercentPolicy(t *testing.T) {
+	p := PercentPolicy{Threshold: 10}
+
+	if regressed, msg := p.Check(pt(100), pt(109), nil, model.LowerIsBetter); regressed {
+		t.Errorf("9%% increase should pass, got regression: %s", msg)
+	}
+	if regressed, msg := p.Check(pt(100), pt(111), nil, model.LowerIsBetter); !regressed {
+		t.Errorf("11%% increase should regress: %s", msg)
+	}
+	if regressed, _ := p.Check(pt(100), pt(50), nil, model.LowerIsBetter); regressed {
+		t.Error("improvement should not regress")
+	}
+	if regressed, _ := p.Check(pt(0), pt(50), nil, model.LowerIsBetter); regressed {
+		t.Error("zero baseline should never regress")
+	}
+}
+
+func TestStdDevPolicy(t *testing.T) {
+	p := StdDevPolicy{K: 2, MinHistory: 3}
+	history := pts(100, 102, 98, 100, 101, 99)
+
+	if regressed, msg := p.Check(history[len(history)-1], pt(102), history, model.LowerIsBetter); regressed {
+		t.Errorf("value within 2σ should pass: %s", msg)
+	}
+	if regressed, msg := p.Check(history[len(history)-1], pt(110), history, model.LowerIsBetter); !regressed {
+		t.Errorf("value beyond 2σ should regress: %s", msg)
+	}
+
+	short := pts(100, 100)
+	if regressed, msg := p.Check(short[1], pt(1000), short, model.LowerIsBetter); regressed {
+		t.Errorf("insufficient history should not regress: %s", msg)
+	}
+}
+
+func TestSigmaPolicy(t *testing.T) {
+	p := SigmaPolicy{K: 3, Window: 5, MinHistory: 3, Fallback: PercentPolicy{Threshold: 10}}
+	// Only the last 5 points count: mean 100, stddev ~1.58, limit ~104.7.
+	history := pts(500, 500, 98, 102, 100, 101, 99)
+
+	if regressed, msg := p.Check(history[len(history)-1], pt(104), history, model.LowerIsBetter); regressed {
+		t.Errorf("value within 3σ should pass: %s", msg)
+	}
+	if regressed, msg := p.Check(history[len(history)-1], pt(106), history, model.LowerIsBetter); !regressed {
+		t.Errorf("value beyond 3σ should regress: %s", msg)
+	}
+
+	// Too little history: the 10% fallback applies against the previous point.
+	short := pts(100, 100)
+	if regressed, msg := p.Check(short[1], pt(106), short, model.LowerIsBetter); regressed {
+		t.Errorf("6%% with short history should pass the fallback: %s", msg)
+	}
+	if regressed, msg := p.Check(short[1], pt(115), short, model.LowerIsBetter); !regressed {
+		t.Errorf("15%% with short history should trip the fallback: %s", msg)
+	}
+}
+
+func TestAbsolutePolicy(t *testing.T) {
+	p := AbsolutePolicy{Threshold: 5}
+
+	if regressed, msg := p.Check(pt(100), pt(105), nil, model.LowerIsBetter); regressed {
+		t.Errorf("delta equal to threshold should pass: %s", msg)
+	}
+	if regressed, msg := p.Check(pt(100), pt(105.5), nil, model.LowerIsBetter); !regressed {
+		t.Errorf("delta above threshold should regress: %s", msg)
+	}
+}
+
+func TestPolicies_HigherIsBetter(t *testing.T) {
+	history := pts(100, 102, 98, 100, 101, 99)
+	tests := []struct {
+		name   string
+		policy Policy
+	}{
+		{"percent", PercentPolicy{Threshold: 10}},
+		{"stddev", StdDevPolicy{K: 2, MinHistory: 3}},
+		{"sigma", SigmaPolicy{K: 2, Window: 10, MinHistory: 3, Fallback: PercentPolicy{Threshold: 10}}},
+		{"absolute", AbsolutePolicy{Threshold: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := history[len(history)-1]
+			if regressed, msg := tt.policy.Check(prev, pt(80), history, model.HigherIsBetter); !regressed {
+				t.Errorf("throughput drop should regress: %s", msg)
+			}
+			if regressed, msg := tt.policy.Check(prev, pt(130), history, model.HigherIsBetter); regressed {
+				t.Errorf("throughput gain should not regress: %s", msg)
+			}
+		})
+	}
+}
+
+func TestByName(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		want      Policy
+		wantErr   bool
+	}{
+		{"percent", 15, PercentPolicy{Threshold: 15}, false},
+		{"stddev", 3, StdDevPolicy{K: 3, MinHistory: defaultMinHistory}, false},
+		{"absolute", 20, AbsolutePolicy{Threshold: 20}, false},
+		{"sigma", 10, SigmaPolicy{K: DefaultSigma, Window: DefaultWindow, MinHistory: defaultMinHistory, Fallback: PercentPolicy{Threshold: 10}}, false},
+		{"median", 1, nil, true},
+		{"", 1, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ByName(tt.name, tt.threshold)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ByName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ByName(%q) = %#v, want %#v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckEntry(t *testing.T) {
+	params := model.RunParams{CPU: "Intel Xeon", GOOS: "linux", GOARCH: "amd64"}
+	other := model.RunParams{CPU: "AMD Ryzen", GOOS: "linux", GOARCH: "amd64"}
+
+	data := model.BranchData{
+		{Commit: model.Commit{SHA: "aaa"}, Date: 1, Params: params, Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkFoo", Value: 100, Unit: "ns/op"},
+		}},
+		{Commit: model.Commit{SHA: "bbb"}, Date: 2, Params: other, Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkFoo", Value: 10, Unit: "ns/op"},
+		}},
+		{Commit: model.Commit{SHA: "bbb"}, Date: 2, Params: params, Tags: map[string]string{"alloc": "arena"}, Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkFoo", Value: 20, Unit: "ns/op"},
+		}},
+		{Commit: model.Commit{SHA: "ccc"}, Date: 3, Params: params, Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkFoo", Value: 200, Unit: "ns/op"},
+		}},
+	}
+
+	entry := model.BenchmarkEntry{
+		Commit: model.Commit{SHA: "ccc"}, Date: 3, Params: params,
+		Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkFoo", Value: 150, Unit: "ns/op"},
+			{Name: "BenchmarkNew", Value: 1, Unit: "ns/op"},
+		},
+	}
+
+	results := CheckEntry(PercentPolicy{Threshold: 10}, data, entry)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d: %+v", len(results), results)
+	}
+
+	// The stored value for the same commit (ccc), the other CPU and the
+	// tagged run are ignored, so the baseline is aaa at 100.
+	r := results[0]
+	if r.Previous.SHA != "aaa" || r.Previous.Value != 100 {
+		t.Errorf("baseline: got %+v, want aaa@100", r.Previous)
+	}
+	if !r.Regressed {
+		t.Errorf("expected regression, got %s", r.Message)
+	}
+}
+
+func TestCheckEntryPinned(t *testing.T) {
+	params := model.RunParams{CPU: "Intel Xeon", GOOS: "linux", GOARCH: "amd64"}
+	entry := func(sha string, date int64, critical, other float64) model.BenchmarkEntry {
+		return model.BenchmarkEntry{Commit: model.Commit{SHA: sha}, Date: date, Params: params, Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkCritical/small", Value: critical, Unit: "ns/op"},
+			{Name: "BenchmarkOther", Value: other, Unit: "ns/op"},
+		}}
+	}
+	// Both benchmarks creep up 5% per commit since the v1 release at aaa.
+	data := model.BranchData{
+		entry("aaa", 1, 100, 100),
+		entry("bbb", 2, 105, 105),
+		entry("ccc", 3, 110, 110),
+	}
+	pins := map[string]string{"BenchmarkCritical/*": "aaa"}
+
+	results := CheckEntryPinned(PercentPolicy{Threshold: 10}, data, entry("ddd", 4, 115, 115), pins)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d: %+v", len(results), results)
+	}
+
+	critical, other := results[0], results[1]
+	if !critical.Pinned || critical.Previous.SHA != "aaa" || !critical.Regressed {
+		t.Errorf("pinned benchmark: got %+v, want a regression against aaa", critical)
+	}
+	if other.Pinned || other.Previous.SHA != "ccc" || other.Regressed {
+		t.Errorf("unpinned benchmark: got %+v, want no regression against ccc", other)
+	}
+
+	// A pin to a commit without a comparable point falls back to rolling.
+	results = CheckEntryPinned(PercentPolicy{Threshold: 10}, data, entry("ddd", 4, 115, 115), map[string]string{"BenchmarkCritical/*": "zzz"})
+	if results[0].Pinned || results[0].Previous.SHA != "ccc" {
+		t.Errorf("unknown pin: got %+v, want the rolling baseline ccc", results[0])
+	}
+}
+
+func TestSuppress(t *testing.T) {
+	results := []Result{
+		{Current: model.HistoryPoint{SHA: "step"}, Regressed: true},
+		{Current: model.HistoryPoint{SHA: "other"}, Regressed: true},
+		{Current: model.HistoryPoint{SHA: "step"}, Regressed: false},
+	}
+	Suppress(results, map[string]struct{}{"step": {}})
+
+	if results[0].Regressed || !results[0].Suppressed {
+		t.Errorf("annotated regression should be suppressed: %+v", results[0])
+	}
+	if !results[1].Regressed || results[1].Suppressed {
+		t.Errorf("unannotated regression must stay: %+v", results[1])
+	}
+	if results[2].Suppressed {
+		t.Errorf("non-regression must not be marked suppressed: %+v", results[2])
+	}
+}
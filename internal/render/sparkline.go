@@ -0,0 +1,111 @@
+// Package render draws benchmark history as images for places where the
+// dashboard cannot be embedded, e.g. emails and chat messages.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+var (
+	// TrendWorse colours a series that ends worse than it started in the
+	// direction of its unit: higher for ns/op, lower for MB/s.
+	TrendWorse = color.RGBA{R: 0xd7, G: 0x30, B: 0x27, A: 0xff}
+	// TrendBetter colours a series that ends better than it started.
+	TrendBetter = color.RGBA{R: 0x1a, G: 0x98, B: 0x50, A: 0xff}
+	// TrendFlat colours a series that ends where it started, or has fewer
+	// than two points.
+	TrendFlat = color.RGBA{R: 0x66, G: 0x66, B: 0x66, A: 0xff}
+)
+
+// SparklinePNG renders points as a w×h PNG sparkline: a line through the
+// values scaled to the image height, without axes or labels, on a
+// transparent background, with the last point marked. The line is coloured
+// by the overall trend in the unit direction dir (TrendWorse, TrendBetter
+// or TrendFlat). An empty series yields a blank image and a single point a
+// centred dot.
+func SparklinePNG(points []float64, dir model.Direction, w, h int) ([]byte, error) {
+	if w < 1 || h < 1 {
+		return nil, fmt.Errorf("invalid sparkline size %dx%d", w, h)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	var values []float64
+	for _, v := range points {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			values = append(values, v)
+		}
+	}
+
+	switch len(values) {
+	case 0:
+	case 1:
+		dot(img, w/2, h/2, TrendFlat)
+	default:
+		c := trendColor(values[0], values[len(values)-1], dir)
+		lo, hi := values[0], values[0]
+		for _, v := range values {
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		x := func(i int) float64 { return float64(i) * float64(w-1) / float64(len(values)-1) }
+		y := func(v float64) float64 {
+			if hi == lo {
+				return float64(h-1) / 2
+			}
+			return float64(h-1) * (hi - v) / (hi - lo)
+		}
+		for i := 1; i < len(values); i++ {
+			line(img, x(i-1), y(values[i-1]), x(i), y(values[i]), c)
+		}
+		last := len(values) - 1
+		dot(img, int(math.Round(x(last))), int(math.Round(y(values[last]))), c)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encoding sparkline: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// trendColor picks the colour for a series going from first to last in
+// unit direction dir. Changes within 0.5% count as flat.
+func trendColor(first, last float64, dir model.Direction) color.RGBA {
+	switch {
+	case math.Abs(last-first) <= math.Abs(first)*0.005:
+		return TrendFlat
+	case (last > first) != dir.Higher():
+		return TrendWorse
+	default:
+		return TrendBetter
+	}
+}
+
+// line draws a one pixel wide line by stepping along its longer axis.
+func line(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA) {
+	steps := int(math.Ceil(max(math.Abs(x1-x0), math.Abs(y1-y0))))
+	for s := 0; s <= steps; s++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(s) / float64(steps)
+		}
+		img.SetRGBA(int(math.Round(x0+(x1-x0)*t)), int(math.Round(y0+(y1-y0)*t)), c)
+	}
+}
+
+// dot draws a 3×3 marker centred on (x, y), clipped to the image.
+func dot(img *image.RGBA, x, y int, c color.RGBA) {
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			if (image.Point{X: x + dx, Y: y + dy}).In(img.Rect) {
+				img.SetRGBA(x+dx, y+dy, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestSparklinePNG(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []float64
+		w, h   int
+	}{
+		{"rising", []float64{100, 102, 101, 110, 120}, 120, 30},
+		{"falling", []float64{120, 110, 100}, 60, 20},
+		{"flat", []float64{5, 5, 5, 5}, 40, 10},
+		{"single point", []float64{42}, 20, 10},
+		{"empty", nil, 16, 8},
+		{"tiny", []float64{1, 2, 3}, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := SparklinePNG(tt.points, model.LowerIsBetter, tt.w, tt.h)
+			if err != nil {
+				t.Fatalf("SparklinePNG() error: %v", err)
+			}
+			if len(data) == 0 {
+				t.Fatal("SparklinePNG() returned no bytes")
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("output does not decode as PNG: %v", err)
+			}
+			if got := img.Bounds().Size(); got != image.Pt(tt.w, tt.h) {
+				t.Errorf("got size %v, want %dx%d", got, tt.w, tt.h)
+			}
+		})
+	}
+}
+
+func TestSparklinePNG_TrendColor(t *testing.T) {
+	for _, tt := range []struct {
+		points []float64
+		dir    model.Direction
+		want   color.RGBA
+	}{
+		{[]float64{100, 150}, model.LowerIsBetter, TrendWorse},
+		{[]float64{150, 100}, model.LowerIsBetter, TrendBetter},
+		{[]float64{100, 150}, model.HigherIsBetter, TrendBetter},
+		{[]float64{150, 100}, model.HigherIsBetter, TrendWorse},
+		{[]float64{100, 100.1}, model.DirectionUnknown, TrendFlat},
+	} {
+		data, err := SparklinePNG(tt.points, tt.dir, 10, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The last point sits on the right edge.
+		found := false
+		for y := 0; y < 10; y++ {
+			if color.RGBAModel.Convert(img.At(9, y)) == tt.want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("points %v: trend colour %v not found at the last point", tt.points, tt.want)
+		}
+	}
+}
+
+func TestSparklinePNG_InvalidSize(t *testing.T) {
+	if _, err := SparklinePNG([]float64{1, 2}, model.LowerIsBetter, 0, 10); err == nil {
+		t.Error("expected an error for zero width")
+	}
+}
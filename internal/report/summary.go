@@ -0,0 +1,100 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// JobSummary renders a GitHub-flavored Markdown summary of entry as stored
+// on branch, for $GITHUB_STEP_SUMMARY: a heading naming the commit and run
+// parameters, then one table per package of the entry's results in input
+// order. Benchmark names link to the commit's URL when it has one.
+//
+// When prev is not nil, a delta column gives each result's percent change
+// from the same series in prev ("—" if prev lacks it or had zero).
+func JobSummary(branch string, entry model.BenchmarkEntry, prev *model.BenchmarkEntry) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "### Benchmarks for `%s` at %s\n\n", branch, commitLink(entry.Commit))
+	var params []string
+	if p := entry.Params; p.GOOS != "" || p.GOARCH != "" {
+		platform := p.GOOS + "/" + p.GOARCH
+		if p.MicroArch != "" {
+			platform += "/" + p.MicroArch
+		}
+		params = append(params, platform)
+	}
+	for _, v := range []string{entry.Params.GoVersion, entry.Params.CPU, model.CanonicalTags(entry.Tags)} {
+		if v != "" {
+			params = append(params, v)
+		}
+	}
+	if len(params) > 0 {
+		fmt.Fprintf(&sb, "%s\n", strings.Join(params, " · "))
+	}
+
+	var old map[model.SeriesKey]float64
+	header := "| Benchmark | Value | Unit |"
+	rule := "| --- | ---: | --- |"
+	if prev != nil {
+		old = make(map[model.SeriesKey]float64, len(prev.Benchmarks))
+		for _, r := range prev.Benchmarks {
+			old[r.SeriesKey()] = r.Value
+		}
+		header += fmt.Sprintf(" Δ vs %s |", commitLink(prev.Commit))
+		rule += " ---: |"
+	}
+
+	byPkg := make(map[string][]model.BenchmarkResult)
+	for _, r := range entry.Benchmarks {
+		byPkg[r.Package] = append(byPkg[r.Package], r)
+	}
+	pkgs := make([]string, 0, len(byPkg))
+	for pkg := range byPkg {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+
+	for _, pkg := range pkgs {
+		if pkg != "" {
+			fmt.Fprintf(&sb, "\n#### `%s`\n", pkg)
+		}
+		fmt.Fprintf(&sb, "\n%s\n%s\n", header, rule)
+		for _, r := range byPkg[pkg] {
+			name := markdownCell(r.Name)
+			if entry.Commit.URL != "" {
+				name = fmt.Sprintf("[%s](%s)", name, entry.Commit.URL)
+			}
+			fmt.Fprintf(&sb, "| %s | %s | %s |", name, formatValue(r.Value), markdownCell(r.Unit))
+			if prev != nil {
+				delta := "—"
+				if v, ok := old[r.SeriesKey()]; ok && v != 0 {
+					delta = fmt.Sprintf("%+.2f%%", (r.Value-v)/v*100)
+				}
+				fmt.Fprintf(&sb, " %s |", delta)
+			}
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// commitLink renders c's short SHA, linked to its URL if it has one.
+func commitLink(c model.Commit) string {
+	sha := c.SHA
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	if c.URL == "" {
+		return "`" + sha + "`"
+	}
+	return fmt.Sprintf("[`%s`](%s)", sha, c.URL)
+}
+
+// markdownCell escapes the pipes that would end a table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,59 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestJobSummary(t *testing.T) {
+	entry := model.BenchmarkEntry{
+		Commit: model.Commit{SHA: "abcdef0123456789", URL: "https://github.com/o/r/commit/abcdef0123456789"},
+		Params: model.RunParams{GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.24.0", CPU: "Test CPU"},
+		Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkB", Value: 110, Unit: "ns/op", Package: "example.com/z"},
+			{Name: "BenchmarkA", Value: 50, Unit: "ns/op", Package: "example.com/a"},
+			{Name: "BenchmarkA - a|b", Value: 3, Unit: "a|b", Package: "example.com/a"},
+		},
+	}
+
+	got := JobSummary("main", entry, nil)
+	for _, want := range []string{
+		"### Benchmarks for `main` at [`abcdef0`](https://github.com/o/r/commit/abcdef0123456789)\n",
+		"linux/amd64 · go1.24.0 · Test CPU\n",
+		"| Benchmark | Value | Unit |\n| --- | ---: | --- |\n",
+		"| [BenchmarkA](https://github.com/o/r/commit/abcdef0123456789) | 50 | ns/op |\n",
+		`| [BenchmarkA - a\|b](https://github.com/o/r/commit/abcdef0123456789) | 3 | a\|b |`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary lacks %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Δ") {
+		t.Errorf("summary without a previous entry should have no delta column:\n%s", got)
+	}
+	// Packages are sorted, each with its own table.
+	if a, z := strings.Index(got, "#### `example.com/a`"), strings.Index(got, "#### `example.com/z`"); a < 0 || z < a {
+		t.Errorf("want example.com/a before example.com/z:\n%s", got)
+	}
+
+	prev := model.BenchmarkEntry{
+		Commit: model.Commit{SHA: "1234567890"},
+		Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkB", Value: 100, Unit: "ns/op", Package: "example.com/z"},
+			{Name: "BenchmarkA", Value: 0, Unit: "ns/op", Package: "example.com/a"},
+		},
+	}
+	got = JobSummary("main", entry, &prev)
+	for _, want := range []string{
+		"| Benchmark | Value | Unit | Δ vs `1234567` |\n| --- | ---: | --- | ---: |\n",
+		"| 110 | ns/op | +10.00% |\n",
+		"| 50 | ns/op | — |\n",
+		"| 3 | a\\|b | — |\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary lacks %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Package report renders parsed benchmark results for humans, e.g. as a
+// table in CI logs.
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// columns returns the header and rows of the results table. The package
+// column is left out when no result has a package.
+func columns(results []model.BenchmarkResult) (header []string, rows [][]string) {
+	withPkg := false
+	for _, r := range results {
+		if r.Package != "" {
+			withPkg = true
+			break
+		}
+	}
+
+	header = []string{"Benchmark", "Value", "Unit"}
+	if withPkg {
+		header = append([]string{"Package"}, header...)
+	}
+	for _, r := range results {
+		row := []string{r.Name, formatValue(r.Value), r.Unit}
+		if withPkg {
+			row = append([]string{r.Package}, row...)
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+// formatValue prints v with the fewest digits that represent it exactly.
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// widths returns the display width of each column.
+func widths(header []string, rows [][]string) []int {
+	w := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			w[i] = max(w[i], utf8.RuneCountInString(cell))
+		}
+	}
+	return w
+}
+
+// TextTable renders results as a plain-text table with aligned columns,
+// one row per result in input order. Values are right-aligned; all other
+// columns are left-aligned.
+func TextTable(results []model.BenchmarkResult) string {
+	header, rows := columns(results)
+	w := widths(header, rows)
+	valueCol := len(header) - 2
+
+	var sb strings.Builder
+	writeRow := func(row []string) {
+		for i, cell := range row {
+			if i > 0 {
+				sb.WriteString("  ")
+			}
+			pad := strings.Repeat(" ", w[i]-utf8.RuneCountInString(cell))
+			switch {
+			case i == valueCol:
+				sb.WriteString(pad + cell)
+			case i == len(row)-1:
+				sb.WriteString(cell)
+			default:
+				sb.WriteString(cell + pad)
+			}
+		}
+		sb.WriteByte('\n')
+	}
+
+	writeRow(header)
+	rule := make([]string, len(header))
+	for i := range rule {
+		rule[i] = strings.Repeat("-", w[i])
+	}
+	writeRow(rule)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return sb.String()
+}
+
+// MarkdownTable renders results as a GitHub-flavored Markdown table with
+// the value column right-aligned.
+func MarkdownTable(results []model.BenchmarkResult) string {
+	header, rows := columns(results)
+	valueCol := len(header) - 2
+
+	var sb strings.Builder
+	writeRow := func(row []string) {
+		sb.WriteString("|")
+		for _, cell := range row {
+			fmt.Fprintf(&sb, " %s |", markdownCell(cell))
+		}
+		sb.WriteByte('\n')
+	}
+
+	writeRow(header)
+	sb.WriteString("|")
+	for i := range header {
+		if i == valueCol {
+			sb.WriteString(" ---: |")
+		} else {
+			sb.WriteString(" --- |")
+		}
+	}
+	sb.WriteByte('\n')
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return sb.String()
+}
@@ -0,0 +1,58 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+var tableResults = []model.BenchmarkResult{
+	{Name: "BenchmarkFoo", Value: 41653.5, Unit: "ns/op", Package: "example.com/pkg"},
+	{Name: "BenchmarkFoo - B/op", Value: 128, Unit: "B/op", Package: "example.com/pkg"},
+	{Name: "BenchmarkBarLonger", Value: 3, Unit: "allocs/op", Package: "example.com/other"},
+}
+
+func TestTextTable(t *testing.T) {
+	got := TextTable(tableResults)
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 2+len(tableResults) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), 2+len(tableResults), got)
+	}
+
+	// Each value ends, and each unit starts, at the same column.
+	unitCol := strings.Index(lines[0], "Unit")
+	for i, r := range tableResults {
+		line := lines[2+i]
+		for _, want := range []string{r.Package, r.Name, r.Unit} {
+			if !strings.Contains(line, want) {
+				t.Errorf("line %q does not contain %q", line, want)
+			}
+		}
+		if got := strings.LastIndex(line, r.Unit); got != unitCol {
+			t.Errorf("unit %q at column %d, want %d", r.Unit, got, unitCol)
+		}
+		if got, want := line[:unitCol-2], formatValue(r.Value); !strings.HasSuffix(got, want) {
+			t.Errorf("value column %q does not end with %q", got, want)
+		}
+	}
+}
+
+func TestTextTable_NoPackage(t *testing.T) {
+	got := TextTable([]model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 1.5, Unit: "ns/op"}})
+	if strings.Contains(got, "Package") {
+		t.Errorf("got package column without packages:\n%s", got)
+	}
+}
+
+func TestMarkdownTable(t *testing.T) {
+	got := MarkdownTable(tableResults)
+	want := "| Package | Benchmark | Value | Unit |\n" +
+		"| --- | --- | ---: | --- |\n" +
+		"| example.com/pkg | BenchmarkFoo | 41653.5 | ns/op |\n" +
+		"| example.com/pkg | BenchmarkFoo - B/op | 128 | B/op |\n" +
+		"| example.com/other | BenchmarkBarLonger | 3 | allocs/op |\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+package stats
+
+// MovingAverage returns the trailing moving average of points over window
+// values: result[i] is the mean of points[max(0, i-window+1)..i]. At the
+// start of the series the window shrinks to the points available, so the
+// result has the same length as points. A window below 2 returns a copy of
+// points.
+func MovingAverage(points []float64, window int) []float64 {
+	out := make([]float64, len(points))
+	if window < 2 {
+		copy(out, points)
+		return out
+	}
+
+	var sum float64
+	for i, v := range points {
+		sum += v
+		if i >= window {
+			sum -= points[i-window]
+		}
+		n := min(i+1, window)
+		out[i] = sum / float64(n)
+	}
+	return out
+}
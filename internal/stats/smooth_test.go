@@ -0,0 +1,35 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMovingAverage(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []float64
+		window int
+		want   []float64
+	}{
+		{"window 3", []float64{1, 2, 3, 4, 5, 6}, 3, []float64{1, 1.5, 2, 3, 4, 5}},
+		{"window larger than series", []float64{2, 4, 6}, 10, []float64{2, 3, 4}},
+		{"window 1 is identity", []float64{5, 1, 3}, 1, []float64{5, 1, 3}},
+		{"window 0 is identity", []float64{5, 1}, 0, []float64{5, 1}},
+		{"spike is damped", []float64{10, 10, 40, 10, 10}, 2, []float64{10, 10, 25, 25, 10}},
+		{"empty", nil, 3, []float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MovingAverage(tt.points, tt.window)
+			if len(got) != len(tt.want) {
+				t.Fatalf("len: got %d, want %d", len(got), len(tt.want))
+			}
+			for i := range tt.want {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("[%d]: got %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
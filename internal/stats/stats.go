@@ -0,0 +1,32 @@
+// Package stats provides small numeric helpers shared by the parser,
+// storage and reporting code.
+package stats
+
+import "math"
+
+// MeanStdDev returns the arithmetic mean and the sample standard deviation
+// (n-1 denominator) of values. The standard deviation is zero when fewer
+// than two values are given; both results are zero for an empty slice.
+func MeanStdDev(values []float64) (mean, stddev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean = sum / float64(len(values))
+
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	var sq float64
+	for _, v := range values {
+		d := v - mean
+		sq += d * d
+	}
+	stddev = math.Sqrt(sq / float64(len(values)-1))
+	return mean, stddev
+}
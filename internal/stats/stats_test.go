@@ -0,0 +1,32 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeanStdDev(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []float64
+		wantMean   float64
+		wantStdDev float64
+	}{
+		{"empty", nil, 0, 0},
+		{"single", []float64{42}, 42, 0},
+		{"constant", []float64{5, 5, 5}, 5, 0},
+		{"simple", []float64{2, 4, 4, 4, 5, 5, 7, 9}, 5, 2.138089935299395},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, stddev := MeanStdDev(tt.values)
+			if math.Abs(mean-tt.wantMean) > 1e-9 {
+				t.Errorf("mean: got %v, want %v", mean, tt.wantMean)
+			}
+			if math.Abs(stddev-tt.wantStdDev) > 1e-9 {
+				t.Errorf("stddev: got %v, want %v", stddev, tt.wantStdDev)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package stats
+
+import "github.com/royalcat/go-continuous-benchmarking/internal/model"
+
+// msPerDay converts HistoryPoint dates (unix milliseconds) to days.
+const msPerDay = 24 * 60 * 60 * 1000
+
+// LinearTrend fits a least-squares line through points, using the point date
+// in days (relative to the first point) as x and the value as y.
+//
+// slope is the change in value per day, intercept the fitted value at the
+// first point's date and r2 the coefficient of determination. When the fit is
+// undefined (fewer than two points or all points on the same date) slope and
+// r2 are zero and intercept is the mean value.
+func LinearTrend(points []model.HistoryPoint) (slope, intercept, r2 float64) {
+	if len(points) == 0 {
+		return 0, 0, 0
+	}
+
+	n := float64(len(points))
+	origin := points[0].Date
+
+	var sumX, sumY float64
+	for _, p := range points {
+		sumX += float64(p.Date-origin) / msPerDay
+		sumY += p.Value
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var sxx, sxy, syy float64
+	for _, p := range points {
+		dx := float64(p.Date-origin)/msPerDay - meanX
+		dy := p.Value - meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return 0, meanY, 0
+	}
+
+	slope = sxy / sxx
+	intercept = meanY - slope*meanX
+	if syy == 0 {
+		// Perfectly flat series: the line fits exactly.
+		return slope, intercept, 1
+	}
+	r2 = (sxy * sxy) / (sxx * syy)
+	return slope, intercept, r2
+}
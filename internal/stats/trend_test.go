@@ -0,0 +1,50 @@
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func dayPoints(values ...float64) []model.HistoryPoint {
+	points := make([]model.HistoryPoint, len(values))
+	for i, v := range values {
+		points[i] = model.HistoryPoint{Date: int64(i) * msPerDay, Value: v}
+	}
+	return points
+}
+
+func TestLinearTrend_PerfectLine(t *testing.T) {
+	slope, intercept, r2 := LinearTrend(dayPoints(100, 102, 104, 106, 108))
+	if math.Abs(slope-2) > 1e-9 {
+		t.Errorf("slope: got %v, want 2", slope)
+	}
+	if math.Abs(intercept-100) > 1e-9 {
+		t.Errorf("intercept: got %v, want 100", intercept)
+	}
+	if math.Abs(r2-1) > 1e-9 {
+		t.Errorf("r2: got %v, want 1", r2)
+	}
+}
+
+func TestLinearTrend_Noisy(t *testing.T) {
+	slope, _, r2 := LinearTrend(dayPoints(100, 98, 103, 99, 101, 100))
+	if math.Abs(slope) > 1 {
+		t.Errorf("slope: got %v, want close to 0", slope)
+	}
+	if r2 > 0.5 {
+		t.Errorf("r2: got %v, want a poor fit", r2)
+	}
+}
+
+func TestLinearTrend_Degenerate(t *testing.T) {
+	if slope, intercept, r2 := LinearTrend(nil); slope != 0 || intercept != 0 || r2 != 0 {
+		t.Errorf("empty: got %v %v %v", slope, intercept, r2)
+	}
+
+	same := []model.HistoryPoint{{Date: 5, Value: 10}, {Date: 5, Value: 20}}
+	if slope, intercept, r2 := LinearTrend(same); slope != 0 || intercept != 15 || r2 != 0 {
+		t.Errorf("same date: got %v %v %v", slope, intercept, r2)
+	}
+}
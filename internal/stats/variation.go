@@ -0,0 +1,50 @@
+package stats
+
+import (
+	"math"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// CoefficientOfVariation returns the sample standard deviation of values
+// divided by the absolute mean, i.e. the relative spread. It is zero for
+// fewer than two values or a zero mean.
+func CoefficientOfVariation(values []float64) float64 {
+	mean, stddev := MeanStdDev(values)
+	if len(values) < 2 || mean == 0 {
+		return 0
+	}
+	return stddev / math.Abs(mean)
+}
+
+// DetrendedCV is the coefficient of variation of points after removing the
+// LinearTrend: the standard deviation of the residuals around the fitted
+// line, relative to the mean value. A series that drifts steadily has a high
+// plain CV but a low detrended CV, while a noisy series is high in both.
+func DetrendedCV(points []model.HistoryPoint) float64 {
+	if len(points) < 3 {
+		return 0
+	}
+
+	slope, intercept, _ := LinearTrend(points)
+	origin := points[0].Date
+
+	var sum float64
+	residuals := make([]float64, len(points))
+	for i, p := range points {
+		x := float64(p.Date-origin) / msPerDay
+		residuals[i] = p.Value - (intercept + slope*x)
+		sum += p.Value
+	}
+	mean := sum / float64(len(points))
+	if mean == 0 {
+		return 0
+	}
+
+	// Two degrees of freedom are spent on the fit.
+	var sq float64
+	for _, r := range residuals {
+		sq += r * r
+	}
+	return math.Sqrt(sq/float64(len(points)-2)) / math.Abs(mean)
+}
@@ -0,0 +1,46 @@
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestCoefficientOfVariation(t *testing.T) {
+	if got := CoefficientOfVariation([]float64{10, 10, 10}); got != 0 {
+		t.Errorf("constant series: got %v, want 0", got)
+	}
+	// mean 10, sample stddev 2
+	if got := CoefficientOfVariation([]float64{8, 10, 12}); math.Abs(got-0.2) > 1e-9 {
+		t.Errorf("got %v, want 0.2", got)
+	}
+	if got := CoefficientOfVariation([]float64{5}); got != 0 {
+		t.Errorf("single value: got %v, want 0", got)
+	}
+}
+
+func TestDetrendedCV_NoisyVsDrifting(t *testing.T) {
+	noisy := dayPoints(100, 140, 90, 150, 85, 145, 95, 138, 88, 142)
+	drifting := dayPoints(100, 110, 120, 130, 140, 150, 160, 170, 180, 190)
+
+	values := func(points []model.HistoryPoint) []float64 {
+		v := make([]float64, len(points))
+		for i, p := range points {
+			v[i] = p.Value
+		}
+		return v
+	}
+
+	// Both look unstable by plain CV...
+	if cv := CoefficientOfVariation(values(drifting)); cv < 0.15 {
+		t.Fatalf("drifting series plain CV unexpectedly low: %v", cv)
+	}
+	// ...but only the noisy one stays unstable once the trend is removed.
+	if cv := DetrendedCV(drifting); cv > 1e-9 {
+		t.Errorf("drifting series detrended CV: got %v, want ~0", cv)
+	}
+	if cv := DetrendedCV(noisy); cv < 0.15 {
+		t.Errorf("noisy series detrended CV: got %v, want > 0.15", cv)
+	}
+}
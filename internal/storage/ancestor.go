@@ -0,0 +1,31 @@
+package storage
+
+import "github.com/royalcat/go-continuous-benchmarking/internal/model"
+
+// NearestAncestor returns the stored entry of branch whose commit comes
+// first in shaOrder. shaOrder lists candidate commits nearest first, as
+// printed by `git rev-list`, so the result is the closest benchmarked
+// ancestor even when some commits in between were never benchmarked.
+//
+// When a commit has several entries (different run parameters) the last
+// stored one is returned. If none of the commits was benchmarked, the
+// result is nil with a nil error.
+func (s *Storage) NearestAncestor(branch string, shaOrder []string) (*model.BenchmarkEntry, error) {
+	data, err := s.ReadBranchData(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	bySHA := make(map[string]int, len(data))
+	for i, e := range data {
+		bySHA[e.Commit.SHA] = i
+	}
+
+	for _, sha := range shaOrder {
+		if i, ok := bySHA[sha]; ok {
+			entry := data[i]
+			return &entry, nil
+		}
+	}
+	return nil, nil
+}
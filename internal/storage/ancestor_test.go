@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestNearestAncestor_SkipsUnbenchmarkedCommits(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	params := model.RunParams{CPU: "TestCPU", GOOS: "linux", GOARCH: "amd64"}
+	for i, sha := range []string{"c1", "c2", "c4"} {
+		e := model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha, Date: fmt.Sprintf("2024-01-%02dT00:00:00Z", i+1)},
+			Params:     params,
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: float64(i), Unit: "ns/op"}},
+		}
+		if err := s.AppendEntry("main", e, 0); err != nil {
+			t.Fatalf("AppendEntry(%s) error: %v", sha, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		shaOrder []string
+		want     string
+	}{
+		{"merge base benchmarked", []string{"c4", "c3", "c2", "c1"}, "c4"},
+		{"gap of unbenchmarked commits", []string{"c6", "c5", "c3", "c2", "c1"}, "c2"},
+		{"nothing benchmarked", []string{"x3", "x2", "x1"}, ""},
+		{"empty order", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.NearestAncestor("main", tt.shaOrder)
+			if err != nil {
+				t.Fatalf("NearestAncestor() error: %v", err)
+			}
+			if tt.want == "" {
+				if got != nil {
+					t.Fatalf("expected nil, got %s", got.Commit.SHA)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("expected %s, got nil", tt.want)
+			}
+			if got.Commit.SHA != tt.want {
+				t.Errorf("got %s, want %s", got.Commit.SHA, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// AnnotationWorkloadChange marks a commit whose benchmark workload changed on
+// purpose, so that its step change in values is expected.
+const AnnotationWorkloadChange = "workload-change"
+
+// Annotation attaches a kind (e.g. AnnotationWorkloadChange) and optional
+// note to a commit. Annotations are stored in data/annotations.json and read
+// by the frontend.
+type Annotation struct {
+	SHA  string `json:"sha"`
+	Kind string `json:"kind"`
+	Note string `json:"note,omitempty"`
+}
+
+// annotationsPath returns the path to data/annotations.json.
+func (s *Storage) annotationsPath() string {
+	return filepath.Join(s.dataDir(), "annotations.json")
+}
+
+// ReadAnnotations returns all stored annotations. A missing file yields an
+// empty slice.
+func (s *Storage) ReadAnnotations() ([]Annotation, error) {
+	data, err := os.ReadFile(s.annotationsPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading annotations: %w", err)
+	}
+	var annotations []Annotation
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return nil, fmt.Errorf("decoding annotations: %w", err)
+	}
+	return annotations, nil
+}
+
+// AddAnnotation records a, replacing the note of an existing annotation with
+// the same SHA and kind.
+func (s *Storage) AddAnnotation(a Annotation) error {
+	annotations, err := s.ReadAnnotations()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i := range annotations {
+		if annotations[i].SHA == a.SHA && annotations[i].Kind == a.Kind {
+			annotations[i].Note = a.Note
+			found = true
+			break
+		}
+	}
+	if !found {
+		annotations = append(annotations, a)
+	}
+
+	data, err := json.MarshalIndent(annotations, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding annotations: %w", err)
+	}
+	if _, err := s.writeFile(s.annotationsPath(), data, 0o644); err != nil {
+		return fmt.Errorf("writing annotations: %w", err)
+	}
+	return nil
+}
+
+// AnnotatedSHAs returns the set of commit SHAs carrying an annotation of
+// the given kind.
+func AnnotatedSHAs(annotations []Annotation, kind string) map[string]struct{} {
+	shas := make(map[string]struct{})
+	for _, a := range annotations {
+		if a.Kind == kind {
+			shas[a.SHA] = struct{}{}
+		}
+	}
+	return shas
+}
@@ -0,0 +1,40 @@
+package storage
+
+import "testing"
+
+func TestAddAnnotation(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	if got, err := s.ReadAnnotations(); err != nil || len(got) != 0 {
+		t.Fatalf("ReadAnnotations() on empty storage = %v, %v", got, err)
+	}
+
+	for _, a := range []Annotation{
+		{SHA: "aaa", Kind: AnnotationWorkloadChange},
+		{SHA: "bbb", Kind: "other"},
+		{SHA: "aaa", Kind: AnnotationWorkloadChange, Note: "bigger input"},
+	} {
+		if err := s.AddAnnotation(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.ReadAnnotations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 annotations, got %+v", got)
+	}
+	if got[0].Note != "bigger input" {
+		t.Errorf("note not updated: %+v", got[0])
+	}
+
+	shas := AnnotatedSHAs(got, AnnotationWorkloadChange)
+	if _, ok := shas["aaa"]; !ok || len(shas) != 1 {
+		t.Errorf("AnnotatedSHAs() = %v, want only aaa", shas)
+	}
+}
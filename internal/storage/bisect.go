@@ -0,0 +1,54 @@
+package storage
+
+import "fmt"
+
+// CommitsBetween returns the commits strictly between fromSHA and toSHA on
+// branch, nearest to toSHA first, for bisecting a change between two
+// benchmarked points. The parent chain is assembled from Commit.Parents of
+// the stored entries, so commits that were never benchmarked are found as
+// long as some later entry recorded them as ancestors.
+//
+// It is an error if toSHA has no stored entry or the chain from toSHA does
+// not reach fromSHA.
+func (s *Storage) CommitsBetween(branch string, fromSHA, toSHA string) ([]string, error) {
+	data, err := s.ReadBranchData(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := make(map[string]string)
+	found := false
+	for _, e := range data {
+		if e.Commit.SHA == toSHA {
+			found = true
+		}
+		child := e.Commit.SHA
+		for _, p := range e.Commit.Parents {
+			if _, ok := parent[child]; !ok {
+				parent[child] = p
+			}
+			child = p
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("commit %s not found on branch %q", toSHA, branch)
+	}
+
+	var between []string
+	seen := map[string]struct{}{toSHA: {}}
+	for sha := toSHA; ; {
+		next, ok := parent[sha]
+		if !ok {
+			return nil, fmt.Errorf("parent chain of %s ends at %s before reaching %s", toSHA, sha, fromSHA)
+		}
+		if next == fromSHA {
+			return between, nil
+		}
+		if _, ok := seen[next]; ok {
+			return nil, fmt.Errorf("parent chain of %s loops at %s", toSHA, next)
+		}
+		seen[next] = struct{}{}
+		between = append(between, next)
+		sha = next
+	}
+}
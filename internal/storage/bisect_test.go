@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestCommitsBetween(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	// History c1..c6; only c1, c2 and c6 were benchmarked, c6 recorded its
+	// ancestors back to c2.
+	params := model.RunParams{CPU: "TestCPU", GOOS: "linux", GOARCH: "amd64"}
+	benchmarked := []model.Commit{
+		{SHA: "c1"},
+		{SHA: "c2", Parents: []string{"c1"}},
+		{SHA: "c6", Parents: []string{"c5", "c4", "c3", "c2", "c1"}},
+	}
+	for i, c := range benchmarked {
+		c.Date = fmt.Sprintf("2024-01-%02dT00:00:00Z", i+1)
+		e := model.BenchmarkEntry{
+			Commit:     c,
+			Params:     params,
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: float64(i), Unit: "ns/op"}},
+		}
+		if err := s.AppendEntry("main", e, 0); err != nil {
+			t.Fatalf("AppendEntry(%s) error: %v", c.SHA, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     []string
+		wantErr  bool
+	}{
+		{"gap of unbenchmarked commits", "c2", "c6", []string{"c5", "c4", "c3"}, false},
+		{"adjacent", "c1", "c2", nil, false},
+		{"across the gap", "c1", "c6", []string{"c5", "c4", "c3", "c2"}, false},
+		{"from not an ancestor", "x9", "c6", nil, true},
+		{"unknown to", "c1", "c9", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.CommitsBetween("main", tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CommitsBetween(%s, %s) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CommitsBetween(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
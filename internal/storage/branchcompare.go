@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/compare"
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// ErrNoComparableParams is returned, wrapped, by CompareBranchesLatest and
+// CommitEntries when the two branches or commits share no run
+// configuration. The result is still returned, computed from the newest
+// entry of each side, so callers can warn and show it.
+var ErrNoComparableParams = errors.New("no entries with matching run parameters")
+
+// CompareBranchesLatest compares the current state of branch b against
+// branch a: the newest entry of b is matched with the newest entry of a
+// that has the same run configuration, and compare.ByBenchmark rows with a
+// as base and b as head are returned. If b's newest entry has no
+// counterpart on a, older entries of b are tried in turn, so a matrix build
+// is compared on the newest configuration both branches ran.
+//
+// It is an error if either branch has no data.
+func (s *Storage) CompareBranchesLatest(a, b string) ([]compare.Row, error) {
+	base, head, err := s.latestComparable(a, b)
+	if err != nil && !errors.Is(err, ErrNoComparableParams) {
+		return nil, err
+	}
+	return compare.ByBenchmark(base, head), err
+}
+
+// latestComparable returns the entries CompareBranchesLatest compares.
+func (s *Storage) latestComparable(a, b string) (base, head model.BenchmarkEntry, err error) {
+	dataA, err := s.ReadBranchData(a)
+	if err != nil {
+		return base, head, err
+	}
+	dataB, err := s.ReadBranchData(b)
+	if err != nil {
+		return base, head, err
+	}
+	if len(dataA) == 0 {
+		return base, head, fmt.Errorf("branch %q has no data", a)
+	}
+	if len(dataB) == 0 {
+		return base, head, fmt.Errorf("branch %q has no data", b)
+	}
+	base, head, ok := newestComparable(dataA, dataB)
+	if !ok {
+		err = fmt.Errorf("comparing %q with %q: %w", a, b, ErrNoComparableParams)
+	}
+	return base, head, err
+}
+
+// CommitPair is one run configuration measured on both commits compared by
+// CommitEntries.
+type CommitPair struct {
+	Base model.BenchmarkEntry
+	Head model.BenchmarkEntry
+}
+
+// CommitEntries returns the entries of commits baseSHA and headSHA on
+// branch to compare: one pair per run configuration (see
+// model.BenchmarkEntry.ConfigKey) both commits were measured on, in the
+// order of headSHA's entries, so that every platform of a matrix build is
+// compared. If the commits share no configuration, the newest entry of each
+// is returned as the only pair with a wrapped ErrNoComparableParams.
+//
+// baseSHA and headSHA may be abbreviated to a prefix naming a single stored
+// commit; a prefix of several commits is a wrapped ErrAmbiguousSHA. It is
+// an error if either commit has no entry on branch.
+func (s *Storage) CommitEntries(branch, baseSHA, headSHA string) ([]CommitPair, error) {
+	data, err := s.ReadBranchData(branch)
+	if err != nil {
+		return nil, err
+	}
+	if baseSHA, err = resolveSHA(data, branch, baseSHA); err != nil {
+		return nil, err
+	}
+	if headSHA, err = resolveSHA(data, branch, headSHA); err != nil {
+		return nil, err
+	}
+
+	baseByConfig := make(map[model.EntryKeyValue]model.BenchmarkEntry)
+	var baseData, headData model.BranchData
+	for _, e := range data {
+		switch e.Commit.SHA {
+		case baseSHA:
+			baseData = append(baseData, e)
+			baseByConfig[e.ConfigKey()] = e
+		case headSHA:
+			headData = append(headData, e)
+		}
+	}
+
+	var pairs []CommitPair
+	paired := make(map[model.EntryKeyValue]bool)
+	for _, e := range headData {
+		config := e.ConfigKey()
+		base, ok := baseByConfig[config]
+		if !ok || paired[config] {
+			continue
+		}
+		paired[config] = true
+		pairs = append(pairs, CommitPair{Base: base, Head: e})
+	}
+	if len(pairs) == 0 {
+		pair := CommitPair{Base: baseData[len(baseData)-1], Head: headData[len(headData)-1]}
+		return []CommitPair{pair}, fmt.Errorf("comparing %s with %s: %w", baseSHA, headSHA, ErrNoComparableParams)
+	}
+	return pairs, nil
+}
+
+// ErrAmbiguousSHA is returned, wrapped, when an abbreviated commit SHA
+// matches several stored commits.
+var ErrAmbiguousSHA = errors.New("ambiguous commit SHA")
+
+// ResolveSHA returns the full SHA of the stored commit of branch that sha
+// names, which may be abbreviated to a unique prefix. A prefix of several
+// commits is a wrapped ErrAmbiguousSHA.
+func (s *Storage) ResolveSHA(branch, sha string) (string, error) {
+	data, err := s.ReadBranchData(branch)
+	if err != nil {
+		return "", err
+	}
+	return resolveSHA(data, branch, sha)
+}
+
+// resolveSHA returns the full SHA of the commit of data that sha names: a
+// stored SHA equal to it or, failing that, the only stored SHA it is a
+// prefix of. It is an error if no commit or several commits match.
+func resolveSHA(data model.BranchData, branch, sha string) (string, error) {
+	var matches []string
+	for _, e := range data {
+		switch {
+		case e.Commit.SHA == sha:
+			return sha, nil
+		case sha != "" && strings.HasPrefix(e.Commit.SHA, sha) && !slices.Contains(matches, e.Commit.SHA):
+			matches = append(matches, e.Commit.SHA)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("commit %s not found on branch %q", sha, branch)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%w %s on branch %q: matches %s", ErrAmbiguousSHA, sha, branch, strings.Join(matches, ", "))
+	}
+}
+
+// newestComparable returns the newest entry of b whose run configuration
+// (see model.BenchmarkEntry.ConfigKey) a also has, with the newest such
+// entry of a. Both must be non-empty and sorted by date. ok is false, and
+// the newest entry of each is returned, if they share no configuration.
+func newestComparable(a, b model.BranchData) (base, head model.BenchmarkEntry, ok bool) {
+	newestA := make(map[model.EntryKeyValue]model.BenchmarkEntry)
+	for _, e := range a {
+		newestA[e.ConfigKey()] = e
+	}
+	for i := len(b) - 1; i >= 0; i-- {
+		if e, ok := newestA[b[i].ConfigKey()]; ok {
+			return e, b[i], true
+		}
+	}
+	return a[len(a)-1], b[len(b)-1], false
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestCompareBranchesLatest(t *testing.T) {
+	intel := model.RunParams{CPU: "Intel", GOOS: "linux", GOARCH: "amd64"}
+	amd := model.RunParams{CPU: "AMD", GOOS: "linux", GOARCH: "amd64"}
+	entry := func(sha string, date int64, params model.RunParams, value float64) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha},
+			Date:       date,
+			Params:     params,
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: value, Unit: "ns/op"}},
+		}
+	}
+
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	write := func(branch string, data model.BranchData) {
+		t.Helper()
+		if err := s.WriteBranchData(branch, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main", model.BranchData{
+		entry("m1", 1, intel, 100),
+		entry("m2", 2, intel, 110),
+		entry("m2", 2, amd, 90),
+	})
+	// The newest feature entry ran on a runner main never used.
+	write("feature/x", model.BranchData{
+		entry("f1", 3, intel, 99),
+		entry("f1", 3, model.RunParams{CPU: "ARM", GOOS: "linux", GOARCH: "arm64"}, 500),
+	})
+	write("feature/arm", model.BranchData{
+		entry("a1", 3, model.RunParams{CPU: "ARM", GOOS: "linux", GOARCH: "arm64"}, 500),
+	})
+
+	rows, err := s.CompareBranchesLatest("main", "feature/x")
+	if err != nil {
+		t.Fatalf("CompareBranchesLatest() error: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Time.Base != 110 || rows[0].Time.Head != 99 {
+		t.Fatalf("got %+v, want main@m2 intel (110) vs feature/x@f1 intel (99)", rows)
+	}
+
+	rows, err = s.CompareBranchesLatest("main", "feature/arm")
+	if !errors.Is(err, ErrNoComparableParams) {
+		t.Fatalf("mismatched params: got error %v, want ErrNoComparableParams", err)
+	}
+	if len(rows) != 1 || rows[0].Time.Base != 90 || rows[0].Time.Head != 500 {
+		t.Errorf("mismatched params: got %+v, want the newest entries compared anyway", rows)
+	}
+
+	if _, err := s.CompareBranchesLatest("main", "missing"); err == nil || errors.Is(err, ErrNoComparableParams) {
+		t.Errorf("missing branch: got error %v, want a hard error", err)
+	}
+}
+
+func TestCommitEntries(t *testing.T) {
+	intel := model.RunParams{CPU: "Intel", GOOS: "linux", GOARCH: "amd64"}
+	amd := model.RunParams{CPU: "AMD", GOOS: "linux", GOARCH: "amd64"}
+	entry := func(sha string, date int64, params model.RunParams, value float64) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha},
+			Date:       date,
+			Params:     params,
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: value, Unit: "ns/op"}},
+		}
+	}
+
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := s.WriteBranchData("main", model.BranchData{
+		entry("aaa111", 1, intel, 100),
+		entry("aaa111", 1, amd, 80),
+		entry("aab222", 2, intel, 110),
+		entry("ccc333", 3, intel, 120),
+		entry("ccc333", 3, amd, 90),
+		entry("ddd444", 4, model.RunParams{GOOS: "darwin"}, 50),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every configuration both commits ran is compared, with SHA prefixes
+	// resolved.
+	pairs, err := s.CommitEntries("main", "aaa1", "ccc")
+	if err != nil {
+		t.Fatalf("CommitEntries() error: %v", err)
+	}
+	if len(pairs) != 2 {
+		t.Fatalf("got %d pairs, want one per shared configuration: %+v", len(pairs), pairs)
+	}
+	for i, want := range []model.RunParams{intel, amd} {
+		p := pairs[i]
+		if p.Base.Commit.SHA != "aaa111" || p.Head.Commit.SHA != "ccc333" || p.Base.Params != want || p.Head.Params != want {
+			t.Errorf("pair %d: got base %s %v, head %s %v, want aaa111 and ccc333 on %v",
+				i, p.Base.Commit.SHA, p.Base.Params, p.Head.Commit.SHA, p.Head.Params, want)
+		}
+	}
+
+	pairs, err = s.CommitEntries("main", "aab222", "ddd444")
+	if !errors.Is(err, ErrNoComparableParams) || len(pairs) != 1 {
+		t.Errorf("no shared configuration: got %d pairs, %v; want the newest entries and ErrNoComparableParams", len(pairs), err)
+	}
+	if _, err := s.CommitEntries("main", "aa", "ccc333"); !errors.Is(err, ErrAmbiguousSHA) {
+		t.Errorf("ambiguous prefix: got %v, want ErrAmbiguousSHA", err)
+	}
+	if _, err := s.CommitEntries("main", "aaa111", "missing"); err == nil {
+		t.Error("unknown head commit: want an error")
+	}
+}
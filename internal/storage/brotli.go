@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/andybalholm/brotli"
+)
+
+// syncBrotli keeps path+".br" in step with the data file at path: with
+// WithBrotli it is (re)written from content, see writeBrotli; without, a copy
+// left by an earlier run is removed, since serve and CDNs would prefer it
+// over the current file.
+func (s *Storage) syncBrotli(path string, content []byte, sourceChanged bool) error {
+	if !s.brotli {
+		return removeIfExists(path + ".br")
+	}
+	return s.writeBrotli(path+".br", content, sourceChanged)
+}
+
+// writeBrotli writes the brotli-compressed form of content to path. The
+// compression is skipped when the source is unchanged and path exists,
+// since compressing at the best level is comparatively slow.
+func (s *Storage) writeBrotli(path string, content []byte, sourceChanged bool) error {
+	if !sourceChanged {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+	}
+
+	var buf bytes.Buffer
+	w := brotli.NewWriterLevel(&buf, brotli.BestCompression)
+	if _, err := w.Write(content); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	_, err := s.writeFile(path, buf.Bytes(), 0o644)
+	return err
+}
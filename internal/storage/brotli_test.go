@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestWriteBranchData_Brotli(t *testing.T) {
+	s, err := New(t.TempDir(), WithBrotli())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	entries := model.BranchData{{
+		Commit:     model.Commit{SHA: "abc"},
+		Date:       1000,
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 1, Unit: "ns/op"}},
+	}}
+	if err := s.WriteBranchData("main", entries); err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := os.ReadFile(s.branchDataPath("main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := os.ReadFile(s.branchDataPath("main") + ".br")
+	if err != nil {
+		t.Fatalf("expected .br variant: %v", err)
+	}
+	decoded, err := io.ReadAll(brotli.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, plain) {
+		t.Error("decompressed .br does not match the JSON file")
+	}
+
+	// A later write without brotli removes the now stale copy.
+	s.brotli = false
+	entries[0].Benchmarks[0].Value = 2
+	if err := s.WriteBranchData("main", entries); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(s.branchDataPath("main") + ".br"); !os.IsNotExist(err) {
+		t.Errorf("stale .br should be removed, stat error: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// The *Context variants below bound a write by ctx. They return as soon as
+// ctx is done, with an error wrapping its cause, even if the filesystem
+// call underneath never returns. Work that was not started yet is skipped;
+// a write already in flight may still complete in the background.
+
+// AppendBranchesWithPolicyContext is AppendBranches bounded by ctx,
+// trimming each branch by policy instead of a plain entry count.
+func (s *Storage) AppendBranchesWithPolicyContext(ctx context.Context, batches map[string][]model.BenchmarkEntry, policy RetentionPolicy, concurrency int) error {
+	return runContext(ctx, func() error {
+		return s.appendBranches(ctx, batches, policy, concurrency)
+	})
+}
+
+// WriteMetadataContext is WriteMetadata bounded by ctx.
+func (s *Storage) WriteMetadataContext(ctx context.Context, repoURL, goModule string, aliases map[string]string) error {
+	return runContext(ctx, func() error {
+		return s.WriteMetadata(repoURL, goModule, aliases)
+	})
+}
+
+// WriteManifestContext is WriteManifest bounded by ctx.
+func (s *Storage) WriteManifestContext(ctx context.Context) error {
+	return runContext(ctx, s.WriteManifest)
+}
+
+// AddAnnotationContext is AddAnnotation bounded by ctx.
+func (s *Storage) AddAnnotationContext(ctx context.Context, a Annotation) error {
+	return runContext(ctx, func() error {
+		return s.AddAnnotation(a)
+	})
+}
+
+// WriteFileContext writes content to path, which need not lie inside the
+// storage directory, the way the storage writes its own files (creating the
+// directory, atomically and only if changed, unless WithWriteFile says
+// otherwise), bounded by ctx. It reports whether the file changed.
+func (s *Storage) WriteFileContext(ctx context.Context, path string, content []byte) (bool, error) {
+	var changed bool
+	err := runContext(ctx, func() error {
+		var err error
+		changed, err = s.writeFile(path, content, 0o644)
+		return err
+	})
+	if err != nil {
+		// On timeout the write may still be running and set changed.
+		return false, err
+	}
+	return changed, nil
+}
+
+// runContext runs op and waits for it or for ctx, whichever is first.
+func runContext(ctx context.Context, op func() error) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("storage operation aborted: %w", context.Cause(ctx))
+	}
+	done := make(chan error, 1)
+	go func() { done <- op() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("storage operation aborted: %w", context.Cause(ctx))
+	}
+}
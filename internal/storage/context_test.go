@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestContext_BlockedWriteTimesOut(t *testing.T) {
+	batches := map[string][]model.BenchmarkEntry{"main": {makeEntry("abc", 1)}}
+	tests := []struct {
+		name string
+		op   func(t *testing.T, ctx context.Context, s *Storage) error
+	}{
+		{"AppendBranchesWithPolicyContext", func(t *testing.T, ctx context.Context, s *Storage) error {
+			return s.AppendBranchesWithPolicyContext(ctx, batches, RetentionPolicy{}, 1)
+		}},
+		{"WriteFileContext", func(t *testing.T, ctx context.Context, s *Storage) error {
+			changed, err := s.WriteFileContext(ctx, filepath.Join(t.TempDir(), "out.txt"), []byte("x"))
+			if changed {
+				t.Error("WriteFileContext reported a change for a write that timed out")
+			}
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A filesystem whose writes hang well past the deadline, like a
+			// stale network mount, and then report a change. The abandoned
+			// write finishes while the test still runs, unsynchronized with
+			// it, so that -race sees what it touches afterwards.
+			const hang = 200 * time.Millisecond
+			defer time.Sleep(2 * hang)
+			s, err := New(t.TempDir(), WithWriteFile(func(string, []byte, os.FileMode) (bool, error) {
+				time.Sleep(hang)
+				return true, nil
+			}))
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() { done <- tt.op(t, ctx, s) }()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("got error %v, want one wrapping context.DeadlineExceeded", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("%s did not return after its deadline", tt.name)
+			}
+		})
+	}
+}
+
+func TestAppendBranchesWithPolicyContext_Canceled(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	batches := map[string][]model.BenchmarkEntry{"main": {makeEntry("abc", 1)}}
+	if err := s.AppendBranchesWithPolicyContext(ctx, batches, RetentionPolicy{}, 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want context.Canceled", err)
+	}
+	if data, _ := s.ReadBranchData("main"); len(data) != 0 {
+		t.Errorf("got %d stored entries after cancellation, want 0", len(data))
+	}
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// DeleteBenchmark removes every result whose name matches pattern from all
+// entries of branch and rewrites the data file. pattern is either an exact
+// name or a path.Match glob; it is matched against both the full result name
+// and its BaseName, so deleting "BenchmarkFoo" also drops its B/op and
+// allocs/op metrics. Note that '*' does not cross the '/' of sub-benchmarks.
+//
+// Entries left without results are kept, as they still carry commit
+// metadata; use PruneEmptyEntries to drop them.
+func (s *Storage) DeleteBenchmark(branch, pattern string) (removed int, err error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	entries, err := s.ReadBranchData(branch)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range entries {
+		kept := entries[i].Benchmarks[:0]
+		for _, r := range entries[i].Benchmarks {
+			if matchesBenchmark(pattern, r) {
+				removed++
+				continue
+			}
+			kept = append(kept, r)
+		}
+		entries[i].Benchmarks = kept
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.WriteBranchData(branch, entries)
+}
+
+// PruneEmptyEntries drops entries that hold no benchmark results from branch
+// and returns how many were removed.
+func (s *Storage) PruneEmptyEntries(branch string) (removed int, err error) {
+	entries, err := s.ReadBranchData(branch)
+	if err != nil {
+		return 0, err
+	}
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if len(e.Benchmarks) == 0 {
+			removed++
+			continue
+		}
+		kept = append(kept, e)
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.WriteBranchData(branch, kept)
+}
+
+func matchesBenchmark(pattern string, r model.BenchmarkResult) bool {
+	for _, name := range []string{r.Name, r.BaseName()} {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func seedDeleteData(t *testing.T) *Storage {
+	t.Helper()
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	results := []model.BenchmarkResult{
+		{Name: "BenchmarkOld", Value: 1, Unit: "ns/op"},
+		{Name: "BenchmarkOld - B/op", Value: 2, Unit: "B/op"},
+		{Name: "BenchmarkKeep", Value: 3, Unit: "ns/op"},
+		{Name: "BenchmarkOldest", Value: 4, Unit: "ns/op"},
+	}
+	entries := []model.BenchmarkEntry{
+		{Commit: model.Commit{SHA: "aaa"}, Date: 1000, Benchmarks: append([]model.BenchmarkResult(nil), results...)},
+		{Commit: model.Commit{SHA: "bbb"}, Date: 2000, Benchmarks: append([]model.BenchmarkResult(nil), results...)},
+		{Commit: model.Commit{SHA: "ccc"}, Date: 3000, Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkOld", Value: 1, Unit: "ns/op"}}},
+	}
+	if err := s.AppendEntries("main", entries, 0); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestDeleteBenchmark_ExactAcrossEntries(t *testing.T) {
+	s := seedDeleteData(t)
+
+	removed, err := s.DeleteBenchmark("main", "BenchmarkOld")
+	if err != nil {
+		t.Fatalf("DeleteBenchmark() error: %v", err)
+	}
+	// ns/op and B/op in two entries, plus ns/op in the third.
+	if removed != 5 {
+		t.Errorf("removed: got %d, want 5", removed)
+	}
+
+	data, err := s.ReadBranchData("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 3 {
+		t.Fatalf("expected empty entry to be kept, got %d entries", len(data))
+	}
+	for _, e := range data[:2] {
+		if len(e.Benchmarks) != 2 || e.Benchmarks[0].Name != "BenchmarkKeep" || e.Benchmarks[1].Name != "BenchmarkOldest" {
+			t.Errorf("entry %s: unexpected benchmarks %+v", e.Commit.SHA, e.Benchmarks)
+		}
+	}
+	if len(data[2].Benchmarks) != 0 {
+		t.Errorf("entry ccc: expected no benchmarks, got %+v", data[2].Benchmarks)
+	}
+
+	pruned, err := s.PruneEmptyEntries("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 1 {
+		t.Errorf("pruned: got %d, want 1", pruned)
+	}
+}
+
+func TestDeleteBenchmark_Glob(t *testing.T) {
+	s := seedDeleteData(t)
+
+	removed, err := s.DeleteBenchmark("main", "BenchmarkOld*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 7 {
+		t.Errorf("removed: got %d, want 7", removed)
+	}
+
+	data, err := s.ReadBranchData("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range data {
+		for _, r := range e.Benchmarks {
+			if r.Name != "BenchmarkKeep" {
+				t.Errorf("entry %s: %q should have been deleted", e.Commit.SHA, r.Name)
+			}
+		}
+	}
+}
+
+func TestDeleteBenchmark_InvalidPattern(t *testing.T) {
+	s := seedDeleteData(t)
+	if _, err := s.DeleteBenchmark("main", "Benchmark["); err == nil {
+		t.Error("expected error for malformed pattern")
+	}
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// EncodingDelta is the Encoding of delta-encoded branch data files.
+const EncodingDelta = "delta"
+
+// defaultAnchorEvery is how often a delta-encoded series stores an absolute
+// value when WithDeltaEncoding is given a non-positive interval.
+const defaultAnchorEvery = 50
+
+// deltaPrecision is the number of decimal places kept of each stored
+// percent change.
+const deltaPrecision = 6
+
+// deltaFile is the on-disk form of a delta-encoded branch data file. Plain
+// files are a bare JSON array of entries instead.
+//
+// Within each series (same run configuration and SeriesKey, in file order)
+// every anchorEvery-th point, and every point following a zero value, holds
+// its absolute value. All other points hold the percent change from the
+// previous reconstructed value of the series.
+//
+// Zero values, i.e. points unchanged from their predecessor, are omitted,
+// and the file is not indented since it is not meant to be read by hand.
+type deltaFile struct {
+	Encoding    string       `json:"encoding"`
+	AnchorEvery int          `json:"anchorEvery"`
+	Entries     []deltaEntry `json:"entries"`
+}
+
+// deltaEntry and deltaResult shadow the entry's benchmarks and the result's
+// value so that zero values can be omitted from delta files.
+type deltaEntry struct {
+	model.BenchmarkEntry
+	Benchmarks []deltaResult `json:"benchmarks"`
+}
+
+type deltaResult struct {
+	model.BenchmarkResult
+	Value float64 `json:"value,omitempty"`
+}
+
+// WithDeltaEncoding makes WriteBranchData store values as percent changes
+// from the previous point of the same series, with an absolute anchor every
+// anchorEvery points (a non-positive value selects the default of 50). This
+// shrinks files of long, near-flat histories. ReadBranchData decodes both
+// encodings regardless of this option.
+//
+// Experimental: values are reconstructed to within about 1e-8 relative
+// error per point.
+func WithDeltaEncoding(anchorEvery int) Option {
+	return func(s *Storage) {
+		if anchorEvery <= 0 {
+			anchorEvery = defaultAnchorEvery
+		}
+		s.deltaAnchorEvery = anchorEvery
+	}
+}
+
+// deltaSeriesKey identifies a series for delta encoding.
+type deltaSeriesKey struct {
+	run    model.EntryKeyValue
+	series model.SeriesKey
+}
+
+// deltaSeriesConfig selects every dimension but the commit SHA, so that all
+// points of a run configuration form one series.
+var deltaSeriesConfig = model.KeyConfig{
+	CPU: true, GOOS: true, GOARCH: true, MicroArch: true, GoVersion: true, CGO: true, Dataset: true, Tags: true, Env: true,
+}
+
+// deltaState tracks, per series, how many points were seen and the last
+// reconstructed value.
+type deltaState struct {
+	count int
+	prev  float64
+}
+
+// walkDeltaSeries calls fn for every result of entries in file order with
+// the state of its series before the result. fn returns the reconstructed
+// absolute value of the result, which becomes the series' previous value.
+func walkDeltaSeries(entries model.BranchData, fn func(r *model.BenchmarkResult, st deltaState, anchor bool) float64, anchorEvery int) {
+	states := make(map[deltaSeriesKey]*deltaState)
+	for i := range entries {
+		run := entries[i].KeyWith(deltaSeriesConfig)
+		for j := range entries[i].Benchmarks {
+			r := &entries[i].Benchmarks[j]
+			key := deltaSeriesKey{run: run, series: r.SeriesKey()}
+			st, ok := states[key]
+			if !ok {
+				st = &deltaState{}
+				states[key] = st
+			}
+			anchor := st.count%anchorEvery == 0 || st.prev == 0
+			st.prev = fn(r, *st, anchor)
+			st.count++
+		}
+	}
+}
+
+// encodeDelta returns a copy of entries with values delta-encoded.
+func encodeDelta(entries model.BranchData, anchorEvery int) model.BranchData {
+	out := make(model.BranchData, len(entries))
+	for i, e := range entries {
+		out[i] = e
+		out[i].Benchmarks = append([]model.BenchmarkResult(nil), e.Benchmarks...)
+	}
+
+	scale := math.Pow(10, deltaPrecision)
+	walkDeltaSeries(out, func(r *model.BenchmarkResult, st deltaState, anchor bool) float64 {
+		if anchor {
+			return r.Value
+		}
+		pct := math.Round((r.Value/st.prev-1)*100*scale) / scale
+		r.Value = pct
+		return st.prev * (1 + pct/100)
+	}, anchorEvery)
+	return out
+}
+
+// decodeDelta reconstructs absolute values of delta-encoded entries in place.
+func decodeDelta(entries model.BranchData, anchorEvery int) {
+	walkDeltaSeries(entries, func(r *model.BenchmarkResult, st deltaState, anchor bool) float64 {
+		if !anchor {
+			r.Value = st.prev * (1 + r.Value/100)
+		}
+		return r.Value
+	}, anchorEvery)
+}
+
+// decodeBranchData decodes a branch data file in either encoding.
+func decodeBranchData(data []byte) (model.BranchData, error) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		var entries model.BranchData
+		err := json.Unmarshal(data, &entries)
+		return entries, err
+	}
+
+	var f deltaFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Encoding != EncodingDelta || f.AnchorEvery <= 0 {
+		return nil, fmt.Errorf("unsupported branch data encoding %q (anchorEvery %d)", f.Encoding, f.AnchorEvery)
+	}
+	entries := make(model.BranchData, len(f.Entries))
+	for i, de := range f.Entries {
+		entries[i] = de.BenchmarkEntry
+		entries[i].Benchmarks = make([]model.BenchmarkResult, len(de.Benchmarks))
+		for j, dr := range de.Benchmarks {
+			entries[i].Benchmarks[j] = dr.BenchmarkResult
+			entries[i].Benchmarks[j].Value = dr.Value
+		}
+	}
+	decodeDelta(entries, f.AnchorEvery)
+	return entries, nil
+}
+
+// encodeBranchData encodes entries in the storage's configured encoding.
+func (s *Storage) encodeBranchData(entries model.BranchData) ([]byte, error) {
+	if s.deltaAnchorEvery == 0 {
+		return json.MarshalIndent(entries, "", "  ")
+	}
+	encoded := encodeDelta(entries, s.deltaAnchorEvery)
+	f := deltaFile{
+		Encoding:    EncodingDelta,
+		AnchorEvery: s.deltaAnchorEvery,
+		Entries:     make([]deltaEntry, len(encoded)),
+	}
+	for i, e := range encoded {
+		f.Entries[i].BenchmarkEntry = e
+		f.Entries[i].Benchmarks = make([]deltaResult, len(e.Benchmarks))
+		for j, r := range e.Benchmarks {
+			f.Entries[i].Benchmarks[j] = deltaResult{BenchmarkResult: r, Value: r.Value}
+		}
+	}
+	return json.Marshal(f)
+}
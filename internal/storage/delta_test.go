@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// nearFlatSeries returns n entries of one benchmark whose timing moves
+// slightly every few commits, with a step change halfway through, and whose
+// memory metrics stay constant.
+func nearFlatSeries(n int) model.BranchData {
+	data := make(model.BranchData, n)
+	for i := range data {
+		base := 1234.56
+		if i >= n/2 {
+			base = 1500
+		}
+		data[i] = model.BenchmarkEntry{
+			Commit: model.Commit{SHA: fmt.Sprintf("sha%04d", i)},
+			Date:   int64(1000 + i),
+			Params: model.RunParams{CPU: "Test CPU", GOOS: "linux", GOARCH: "amd64"},
+			Benchmarks: []model.BenchmarkResult{
+				{Name: "BenchmarkFoo", Value: base + float64(i/5%3)*0.37, Unit: "ns/op", Package: "example.com/pkg"},
+				{Name: "BenchmarkFoo - B/op", Value: 128, Unit: "B/op", Package: "example.com/pkg"},
+				{Name: "BenchmarkFoo - allocs/op", Value: 2, Unit: "allocs/op", Package: "example.com/pkg"},
+			},
+		}
+	}
+	return data
+}
+
+func TestDeltaEncoding_RoundTrip(t *testing.T) {
+	for _, anchorEvery := range []int{1, 7, 50} {
+		t.Run(fmt.Sprintf("anchor%d", anchorEvery), func(t *testing.T) {
+			s, err := New(t.TempDir(), WithDeltaEncoding(anchorEvery))
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+
+			want := nearFlatSeries(200)
+			// A second configuration interleaved with the first must be
+			// tracked as its own series.
+			other := nearFlatSeries(10)
+			for i := range other {
+				other[i].Params.GOARCH = "arm64"
+				other[i].Benchmarks[0].Value *= 3
+			}
+			want = append(want, other...)
+
+			if err := s.WriteBranchData("main", want); err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.ReadBranchData("main")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("got %d entries, want %d", len(got), len(want))
+			}
+			for i := range want {
+				for j, w := range want[i].Benchmarks {
+					g := got[i].Benchmarks[j]
+					if g.Name != w.Name || g.Unit != w.Unit {
+						t.Fatalf("entry %d result %d: got %s %s, want %s %s", i, j, g.Name, g.Unit, w.Name, w.Unit)
+					}
+					if diff := math.Abs(g.Value - w.Value); diff > 1e-6*math.Max(1, math.Abs(w.Value)) {
+						t.Errorf("entry %d %s: got %v, want %v", i, w.Name, g.Value, w.Value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDeltaEncoding_DoesNotMutateInput(t *testing.T) {
+	s, err := New(t.TempDir(), WithDeltaEncoding(10))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	data := nearFlatSeries(5)
+	if err := s.WriteBranchData("main", data); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := data[1].Benchmarks[0].Value, nearFlatSeries(5)[1].Benchmarks[0].Value; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestReadBranchData_PlainAfterDelta(t *testing.T) {
+	dir := t.TempDir()
+	delta, err := New(dir, WithDeltaEncoding(10))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := delta.WriteBranchData("main", nearFlatSeries(20)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A store without the option still reads delta files and rewrites them
+	// as plain JSON.
+	plain, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	data, err := plain.ReadBranchData("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.WriteBranchData("main", data); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(plain.branchDataPath("main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw[0] != '[' {
+		t.Errorf("got file starting with %q, want a plain JSON array", raw[0])
+	}
+}
+
+// BenchmarkDeltaEncoding_FileSize reports the size of a near-flat
+// 1000-entry branch data file in plain and delta encoding.
+func BenchmarkDeltaEncoding_FileSize(b *testing.B) {
+	data := nearFlatSeries(1000)
+	for _, tc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"json", nil},
+		{"delta", []Option{WithDeltaEncoding(50)}},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			s, err := New(b.TempDir(), tc.opts...)
+			if err != nil {
+				b.Fatal(err)
+			}
+			var size int
+			for b.Loop() {
+				encoded, err := s.encodeBranchData(data)
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(encoded)
+			}
+			b.ReportMetric(float64(size), "bytes/file")
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// PatchOp is a single RFC 6902 JSON Patch operation against a branch data
+// file, whose root is the array of entries.
+type PatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// DiffBranch returns the JSON Patch that turns old into new. Entries are
+// matched by the storage's entry key (see WithKeyConfig): entries missing
+// from new are removed, new keys are added and changed entries with the same
+// key are replaced. Entries left over once new is matched, e.g. stored
+// duplicates of one key, are removed from the end. Applying the ops in order
+// to old yields new.
+func (s *Storage) DiffBranch(old, new model.BranchData) []PatchOp {
+	var ops []PatchOp
+
+	newKeys := make(map[model.EntryKeyValue]struct{}, len(new))
+	for _, e := range new {
+		newKeys[s.entryKey(e)] = struct{}{}
+	}
+
+	// Remove from the back so earlier indices stay valid.
+	var current []model.BenchmarkEntry
+	for i := len(old) - 1; i >= 0; i-- {
+		if _, ok := newKeys[s.entryKey(old[i])]; !ok {
+			ops = append(ops, PatchOp{Op: "remove", Path: pointer(i)})
+		}
+	}
+	for _, e := range old {
+		if _, ok := newKeys[s.entryKey(e)]; ok {
+			current = append(current, e)
+		}
+	}
+
+	for i, e := range new {
+		key := s.entryKey(e)
+		if i < len(current) && s.entryKey(current[i]) == key {
+			if !reflect.DeepEqual(current[i], e) {
+				ops = append(ops, PatchOp{Op: "replace", Path: pointer(i), Value: e})
+				current[i] = e
+			}
+			continue
+		}
+
+		// A replaced entry whose date changed may now sit elsewhere.
+		if j := s.indexOfKey(current, key, i+1); j >= 0 {
+			ops = append(ops, PatchOp{Op: "move", From: pointer(j), Path: pointer(i)})
+			moved := current[j]
+			current = append(current[:j], current[j+1:]...)
+			current = append(current[:i], append([]model.BenchmarkEntry{moved}, current[i:]...)...)
+			if !reflect.DeepEqual(moved, e) {
+				ops = append(ops, PatchOp{Op: "replace", Path: pointer(i), Value: e})
+				current[i] = e
+			}
+			continue
+		}
+
+		ops = append(ops, PatchOp{Op: "add", Path: pointer(i), Value: e})
+		current = append(current[:i], append([]model.BenchmarkEntry{e}, current[i:]...)...)
+	}
+
+	// current now starts with new; drop what remains after it.
+	for i := len(current) - 1; i >= len(new); i-- {
+		ops = append(ops, PatchOp{Op: "remove", Path: pointer(i)})
+	}
+	return ops
+}
+
+func pointer(i int) string {
+	return "/" + strconv.Itoa(i)
+}
+
+func (s *Storage) indexOfKey(entries []model.BenchmarkEntry, key model.EntryKeyValue, from int) int {
+	for j := from; j < len(entries); j++ {
+		if s.entryKey(entries[j]) == key {
+			return j
+		}
+	}
+	return -1
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func diffEntry(sha string, date int64, value float64) model.BenchmarkEntry {
+	return model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: sha},
+		Date:       date,
+		Params:     model.RunParams{GOOS: "linux", GOARCH: "amd64"},
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: value, Unit: "ns/op"}},
+	}
+}
+
+func TestDiffBranch(t *testing.T) {
+	a := diffEntry("aaa", 1000, 10)
+	b := diffEntry("bbb", 2000, 20)
+	c := diffEntry("ccc", 3000, 30)
+	b2 := diffEntry("bbb", 2000, 25)
+	bMoved := diffEntry("bbb", 4000, 20)
+
+	tests := []struct {
+		name     string
+		old, new model.BranchData
+		want     []PatchOp
+	}{
+		{
+			name: "no change",
+			old:  model.BranchData{a, b},
+			new:  model.BranchData{a, b},
+			want: nil,
+		},
+		{
+			name: "add one entry",
+			old:  model.BranchData{a, b},
+			new:  model.BranchData{a, b, c},
+			want: []PatchOp{{Op: "add", Path: "/2", Value: c}},
+		},
+		{
+			name: "add into the middle",
+			old:  model.BranchData{a, c},
+			new:  model.BranchData{a, b, c},
+			want: []PatchOp{{Op: "add", Path: "/1", Value: b}},
+		},
+		{
+			name: "replace one entry",
+			old:  model.BranchData{a, b, c},
+			new:  model.BranchData{a, b2, c},
+			want: []PatchOp{{Op: "replace", Path: "/1", Value: b2}},
+		},
+		{
+			name: "trimmed by max-items",
+			old:  model.BranchData{a, b},
+			new:  model.BranchData{b, c},
+			want: []PatchOp{
+				{Op: "remove", Path: "/0"},
+				{Op: "add", Path: "/1", Value: c},
+			},
+		},
+		{
+			name: "replacement moves to a new date",
+			old:  model.BranchData{a, b, c},
+			new:  model.BranchData{a, c, bMoved},
+			want: []PatchOp{
+				{Op: "move", From: "/2", Path: "/1"},
+				{Op: "replace", Path: "/2", Value: bMoved},
+			},
+		},
+	}
+
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.DiffBranch(tt.old, tt.new)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffBranch_KeyConfigAndSurplus(t *testing.T) {
+	x := diffEntry("aaa", 1000, 10)
+	x.Environment = map[string]string{"RUNNER": "one"}
+	y := diffEntry("aaa", 1000, 12)
+	y.Environment = map[string]string{"RUNNER": "two"}
+	z := diffEntry("ccc", 3000, 30)
+
+	// Without env in the key, x and y are one run stored twice.
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.DiffBranch(model.BranchData{x, y}, model.BranchData{y, z})
+	want := []PatchOp{
+		{Op: "replace", Path: "/0", Value: y},
+		{Op: "add", Path: "/1", Value: z},
+		{Op: "remove", Path: "/2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	// With env in the key, x is a separate run that goes away.
+	s, err = New(t.TempDir(), WithEnvDedup())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = s.DiffBranch(model.BranchData{x, y}, model.BranchData{y, z})
+	want = []PatchOp{
+		{Op: "remove", Path: "/0"},
+		{Op: "add", Path: "/1", Value: z},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("env in key: got %+v\nwant %+v", got, want)
+	}
+}
@@ -0,0 +1,42 @@
+package storage
+
+import "github.com/royalcat/go-continuous-benchmarking/internal/model"
+
+// ByGoVersion returns the results stored on branch for commit sha, keyed by
+// the Go version of their entry. Results of several platforms with the same
+// Go version are concatenated in file order; use ByGoVersionMatching to
+// tell them apart.
+func (s *Storage) ByGoVersion(branch, sha string) (map[string][]model.BenchmarkResult, error) {
+	byVersion, err := s.ByGoVersionMatching(branch, sha, nil)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string][]model.BenchmarkResult, len(byVersion))
+	for v, entries := range byVersion {
+		for _, e := range entries {
+			results[v] = append(results[v], e.Benchmarks...)
+		}
+	}
+	return results, nil
+}
+
+// ByGoVersionMatching returns the entries stored on branch for commit sha
+// whose run parameters satisfy match (nil matches all), keyed by their Go
+// version, e.g. to compare Go versions on one OS/architecture of a larger
+// matrix. Entries of other platforms with the same Go version are kept
+// apart, in file order.
+func (s *Storage) ByGoVersionMatching(branch, sha string, match func(model.RunParams) bool) (map[string][]model.BenchmarkEntry, error) {
+	data, err := s.ReadBranchData(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[string][]model.BenchmarkEntry)
+	for _, e := range data {
+		if e.Commit.SHA != sha || (match != nil && !match(e.Params)) {
+			continue
+		}
+		byVersion[e.Params.GoVersion] = append(byVersion[e.Params.GoVersion], e)
+	}
+	return byVersion, nil
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestByGoVersion(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	entry := func(sha, goVersion, goos string, value float64) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha},
+			Date:       1000,
+			Params:     model.RunParams{GOOS: goos, GOARCH: "amd64", GoVersion: goVersion},
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: value, Unit: "ns/op"}},
+		}
+	}
+	if err := s.AppendEntries("main", []model.BenchmarkEntry{
+		entry("abc123", "go1.22.0", "linux", 100),
+		entry("abc123", "go1.23.0", "linux", 90),
+		entry("abc123", "go1.23.0", "darwin", 80),
+		entry("def456", "go1.22.0", "linux", 1000),
+	}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.ByGoVersionMatching("main", "abc123", func(p model.RunParams) bool { return p.GOOS == "linux" })
+	if err != nil {
+		t.Fatalf("ByGoVersionMatching() error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d Go versions, want 2: %v", len(got), got)
+	}
+	if v := got["go1.22.0"]; len(v) != 1 || v[0].Benchmarks[0].Value != 100 {
+		t.Errorf("go1.22.0: got %+v, want one entry of 100", v)
+	}
+	if v := got["go1.23.0"]; len(v) != 1 || v[0].Benchmarks[0].Value != 90 {
+		t.Errorf("go1.23.0: got %+v, want one entry of 90", v)
+	}
+
+	all, err := s.ByGoVersion("main", "abc123")
+	if err != nil {
+		t.Fatalf("ByGoVersion() error: %v", err)
+	}
+	if v := all["go1.23.0"]; len(v) != 2 || v[0].Value != 90 || v[1].Value != 80 {
+		t.Errorf("go1.23.0 without filter: got %+v, want the results 90 and 80", v)
+	}
+}
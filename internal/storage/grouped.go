@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// groupedDirName holds the grouped data files; see derivedPath.
+const groupedDirName = "grouped"
+
+// GroupedPoint is one value of a series in a grouped data file.
+type GroupedPoint struct {
+	Date  int64   `json:"date"`
+	SHA   string  `json:"sha"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+// GroupedSeries is one series of a benchmark in a grouped data file: the
+// values of one metric under one run configuration, in entry order.
+type GroupedSeries struct {
+	Params  model.RunParams `json:"params"`
+	Tags    string          `json:"tags,omitempty"`
+	Package string          `json:"package,omitempty"`
+	Unit    string          `json:"unit"`
+	Procs   int             `json:"procs,omitempty"`
+	Points  []GroupedPoint  `json:"points"`
+}
+
+// GroupedData maps each stored benchmark name to its series. Secondary
+// metrics keep their " - unit" suffixed names, and results of different run
+// configurations, packages or GOMAXPROCS are separate series, so every
+// series is one line of a chart.
+type GroupedData map[string][]GroupedSeries
+
+// Group builds the grouped form of data: the series a reader gets by
+// scanning the entries and bucketing results by run configuration and
+// series key (see model.BranchData.History), in order of first appearance.
+func Group(data model.BranchData) GroupedData {
+	g := make(GroupedData)
+	index := make(map[model.SeriesID]int)
+	for _, e := range data {
+		config := e.ConfigKey()
+		seen := make(map[model.SeriesKey]bool, len(e.Benchmarks))
+		for _, r := range e.Benchmarks {
+			// Like History, an entry contributes one point per series.
+			if seen[r.SeriesKey()] {
+				continue
+			}
+			seen[r.SeriesKey()] = true
+			id := model.SeriesID{Config: config, Key: r.SeriesKey()}
+			i, ok := index[id]
+			if !ok {
+				i = len(g[r.Name])
+				index[id] = i
+				g[r.Name] = append(g[r.Name], GroupedSeries{
+					Params:  e.Params,
+					Tags:    config.Tags,
+					Package: r.Package,
+					Unit:    r.Unit,
+					Procs:   r.Procs,
+				})
+			}
+			series := &g[r.Name][i]
+			series.Points = append(series.Points, GroupedPoint{Date: e.Date, SHA: e.Commit.SHA, Value: r.Value, Unit: r.Unit})
+		}
+	}
+	return g
+}
+
+// groupedPath returns the path to grouped/<branch>.json (grouped/<suite>/
+// for a named suite).
+func (s *Storage) groupedPath(branch string) string {
+	return s.derivedPath(groupedDirName, branch)
+}
+
+// WriteGrouped writes grouped/<branch>.json, the branch data grouped by
+// benchmark name, for clients that only need the series. It is derived
+// from the canonical per-entry file and rewritten in full; once it exists,
+// every later write of the branch data rewrites it as well.
+func (s *Storage) WriteGrouped(branch string) error {
+	data, err := s.ReadBranchData(branch)
+	if err != nil {
+		return err
+	}
+	return s.writeGrouped(branch, data)
+}
+
+// writeGrouped writes grouped/<branch>.json for data, the branch's entries.
+func (s *Storage) writeGrouped(branch string, data model.BranchData) error {
+	encoded, err := json.Marshal(Group(data))
+	if err != nil {
+		return fmt.Errorf("encoding grouped data for %q: %w", branch, err)
+	}
+	if _, err := s.writeFile(s.groupedPath(branch), encoded, 0o644); err != nil {
+		return fmt.Errorf("writing grouped data for %q: %w", branch, err)
+	}
+	return nil
+}
+
+// writeDerived writes the files derived from data, the entries of branch:
+// its summary and, once WriteGrouped created it, its grouped data, so that
+// neither falls behind the branch data.
+func (s *Storage) writeDerived(branch string, data model.BranchData) error {
+	if err := s.WriteBranchSummary(branch, data); err != nil {
+		return err
+	}
+	if _, err := os.Stat(s.groupedPath(branch)); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("checking grouped data for %q: %w", branch, err)
+	}
+	return s.writeGrouped(branch, data)
+}
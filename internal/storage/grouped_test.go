@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestWriteGrouped_MatchesScan(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	intel := model.RunParams{CPU: "Intel", GOOS: "linux", GOARCH: "amd64"}
+	amd := model.RunParams{CPU: "AMD", GOOS: "linux", GOARCH: "amd64"}
+	data := model.BranchData{
+		{Commit: model.Commit{SHA: "a"}, Date: 1, Params: intel, Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkFoo", Value: 100, Unit: "ns/op", Procs: 8},
+			{Name: "BenchmarkFoo - B/op", Value: 64, Unit: "B/op", Procs: 8},
+			{Name: "BenchmarkFoo - allocs/op", Value: 2, Unit: "allocs/op", Procs: 8},
+			{Name: "BenchmarkFoo", Value: 150, Unit: "ns/op", Procs: 1},
+		}},
+		{Commit: model.Commit{SHA: "a"}, Date: 1, Params: amd, Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkFoo", Value: 90, Unit: "ns/op", Procs: 8},
+		}},
+		{Commit: model.Commit{SHA: "b"}, Date: 2, Params: intel, Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkFoo", Value: 110, Unit: "ns/op", Procs: 8},
+			{Name: "BenchmarkFoo - B/op", Value: 80, Unit: "B/op", Procs: 8},
+			{Name: "BenchmarkFoo - allocs/op", Value: 3, Unit: "allocs/op", Procs: 8},
+			{Name: "BenchmarkBar", Value: 5, Unit: "ns/op", Procs: 8},
+		}},
+		{Commit: model.Commit{SHA: "b"}, Date: 2, Params: intel, Tags: map[string]string{"gc": "off"}, Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkFoo", Value: 70, Unit: "ns/op", Procs: 8},
+		}},
+	}
+	if err := s.WriteBranchData("feature/x", data); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteGrouped("feature/x"); err != nil {
+		t.Fatalf("WriteGrouped() error: %v", err)
+	}
+
+	raw, err := os.ReadFile(s.groupedPath("feature/x"))
+	if err != nil {
+		t.Fatalf("grouped file not written: %v", err)
+	}
+	var got GroupedData
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("decoding grouped file: %v", err)
+	}
+
+	// Every series found by scanning the entry array is one grouped series
+	// with the same points.
+	ids := data.SeriesIDs()
+	total := 0
+	for _, series := range got {
+		total += len(series)
+	}
+	if total != len(ids) {
+		t.Errorf("got %d grouped series, want %d", total, len(ids))
+	}
+	for _, id := range ids {
+		var match *GroupedSeries
+		for i, series := range got[id.Key.Name] {
+			if series.Params == id.Config.Params && series.Tags == id.Config.Tags &&
+				series.Package == id.Key.Package && series.Unit == id.Key.Unit && series.Procs == id.Key.Procs {
+				match = &got[id.Key.Name][i]
+			}
+		}
+		if match == nil {
+			t.Errorf("no grouped series for %+v", id)
+			continue
+		}
+		want := data.History(id.Config, id.Key)
+		if len(match.Points) != len(want) {
+			t.Errorf("%+v: got %d points, want %d", id, len(match.Points), len(want))
+			continue
+		}
+		for i, p := range want {
+			gp := match.Points[i]
+			if gp.Date != p.Date || gp.SHA != p.SHA || gp.Value != p.Value || gp.Unit != p.Unit {
+				t.Errorf("%+v point %d: got %+v, want %+v", id, i, gp, p)
+			}
+		}
+	}
+	if n := len(got["BenchmarkFoo"]); n != 4 {
+		t.Errorf("got %d BenchmarkFoo series, want one per CPU, GOMAXPROCS and tags", n)
+	}
+	if series := got["BenchmarkFoo - allocs/op"]; len(series) != 1 || len(series[0].Points) != 2 {
+		t.Errorf("allocs/op: got %+v, want one series of 2 points", series)
+	}
+
+	// Later writes of the branch data keep the grouped file current.
+	if err := s.AppendEntries("feature/x", []model.BenchmarkEntry{{
+		Commit: model.Commit{SHA: "c"}, Date: 3, Params: amd,
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 95, Unit: "ns/op", Procs: 8}},
+	}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if raw, err = os.ReadFile(s.groupedPath("feature/x")); err != nil {
+		t.Fatal(err)
+	}
+	var after GroupedData
+	if err := json.Unmarshal(raw, &after); err != nil {
+		t.Fatalf("decoding grouped file: %v", err)
+	}
+	points := 0
+	for _, series := range after["BenchmarkFoo"] {
+		points += len(series.Points)
+	}
+	if points != 6 {
+		t.Errorf("after appending: got %d BenchmarkFoo points, want 6", points)
+	}
+
+	// The grouped file is not mistaken for branch data.
+	entries, err := s.ReadBranchData("feature/x")
+	if err != nil || len(entries) != len(data)+1 {
+		t.Errorf("ReadBranchData after WriteGrouped: got %d entries (err %v), want %d", len(entries), err, len(data)+1)
+	}
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// gzipSuffix is appended to the data file name of gzip-compressed branch
+// data.
+const gzipSuffix = ".gz"
+
+// WithGzip makes WriteBranchData store branch data gzip-compressed as
+// data/<branch>.json.gz instead of data/<branch>.json. A plain file left by
+// an earlier run is still read and is replaced by the compressed one on the
+// next write. ReadBranchData reads both forms regardless of this option.
+func WithGzip() Option {
+	return func(s *Storage) {
+		s.gzip = true
+	}
+}
+
+// BranchFileName returns the file name (without directory) of branch's
+// data file under s's options, i.e. with a .gz suffix when WithGzip is set
+// and the .ndjson extension with WithNDJSON.
+func (s *Storage) BranchFileName(branch string) string {
+	if s.ndjson {
+		return sanitizeBranchName(branch) + ndjsonSuffix
+	}
+	if s.gzip {
+		return BranchFileName(branch) + gzipSuffix
+	}
+	return BranchFileName(branch)
+}
+
+// readBranchFile returns the raw branch data of branch, decompressed if
+// needed, and the path it was read from. The file of the current mode is
+// preferred; the other forms are a fallback for data written before the mode
+// was switched. It returns fs.ErrNotExist if none exists.
+func (s *Storage) readBranchFile(branch string) ([]byte, string, error) {
+	plain := s.branchDataPath(branch)
+	paths := []string{plain, plain + gzipSuffix, s.ndjsonPath(branch)}
+	switch {
+	case s.ndjson:
+		paths[0], paths[2] = paths[2], paths[0]
+	case s.gzip:
+		paths[0], paths[1] = paths[1], paths[0]
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if strings.HasSuffix(path, gzipSuffix) {
+			data, err = gunzip(data)
+		}
+		return data, path, err
+	}
+	return nil, "", fs.ErrNotExist
+}
+
+// gzipBytes compresses data. The header carries no name or modification
+// time, so equal input yields equal output and WriteIfChanged can skip
+// unchanged files.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing: %w", err)
+	}
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing: %w", err)
+	}
+	return out, nil
+}
+
+// removeIfExists deletes path, ignoring a missing file.
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestWithGzip_MigratesPlainFile(t *testing.T) {
+	dir := t.TempDir()
+	data := model.BranchData{{
+		Commit:     model.Commit{SHA: "abc", Date: "2024-01-01T00:00:00Z"},
+		Date:       1,
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 10, Unit: "ns/op"}},
+	}}
+
+	plain, err := New(dir, WithBrotli())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := plain.WriteBranchData("feature/x", data); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := plain.branchDataPath("feature/x")
+	gzPath := jsonPath + gzipSuffix
+
+	gz, err := New(dir, WithGzip())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if got := gz.BranchFileName("feature/x"); got != "feature_x.json.gz" {
+		t.Errorf("BranchFileName: got %q, want feature_x.json.gz", got)
+	}
+
+	// The existing plain file is read with compression enabled...
+	got, err := gz.ReadBranchData("feature/x")
+	if err != nil {
+		t.Fatalf("ReadBranchData() error: %v", err)
+	}
+	if !reflect.DeepEqual(got, data) {
+		t.Fatalf("reading plain file: got %+v, want %+v", got, data)
+	}
+
+	// ...and replaced by the compressed one on the next write.
+	if err := gz.WriteBranchData("feature/x", got); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{jsonPath, jsonPath + ".br"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed after migration, stat error: %v", p, err)
+		}
+	}
+	raw, err := os.ReadFile(gzPath)
+	if err != nil {
+		t.Fatalf("compressed file missing: %v", err)
+	}
+	if len(raw) < 2 || raw[0] != 0x1f || raw[1] != 0x8b {
+		t.Errorf("%s is not gzip data", gzPath)
+	}
+
+	// Both modes read the compressed file.
+	for name, s := range map[string]*Storage{"gzip": gz, "plain": plain} {
+		got, err := s.ReadBranchData("feature/x")
+		if err != nil {
+			t.Fatalf("%s: ReadBranchData() error: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, data) {
+			t.Errorf("%s: got %+v, want %+v", name, got, data)
+		}
+	}
+
+	// Rewriting unchanged data leaves the compressed file untouched.
+	before, _ := os.Stat(gzPath)
+	if err := gz.WriteBranchData("feature/x", data); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.Stat(gzPath)
+	if !after.ModTime().Equal(before.ModTime()) {
+		t.Error("compressed output should be deterministic so unchanged data is not rewritten")
+	}
+
+	// Switching back to plain files removes the compressed one.
+	if err := plain.WriteBranchData("feature/x", data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(gzPath); !os.IsNotExist(err) {
+		t.Errorf("compressed file should be removed in plain mode, stat error: %v", err)
+	}
+}
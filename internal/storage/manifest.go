@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// manifestFileName is the name of the manifest written next to branches.json.
+const manifestFileName = "manifest.json"
+
+// ManifestFile describes one file listed in manifest.json.
+type ManifestFile struct {
+	// Path is relative to the storage base directory, using forward slashes.
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest lists the data files managed by the storage together with their
+// size and content hash, so that cache/CDN tooling can diff two runs.
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// manifestPath returns the path to manifest.json.
+func (s *Storage) manifestPath() string {
+	return filepath.Join(s.baseDir, manifestFileName)
+}
+
+// BuildManifest hashes branches.json, suites.json, metadata.json, the
+// branch lists of the named suites, the branch summaries and grouped data
+// files and every data/*.json, data/*.json.gz and data/*.ndjson file
+// currently on disk, including those of every suite under data/<suite>/,
+// summaries/<suite>/ and grouped/<suite>/, whichever suite s belongs to.
+// Files that do not exist are omitted. Entries are sorted by path.
+func (s *Storage) BuildManifest() (Manifest, error) {
+	paths := []string{filepath.Join(s.baseDir, "branches.json"), s.suitesPath(), s.metadataPath()}
+	suiteBranches, err := filepath.Glob(filepath.Join(s.baseDir, suiteBranchesDirName, "*.json"))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("listing suite branch lists: %w", err)
+	}
+	paths = append(paths, suiteBranches...)
+	for _, derived := range []string{summariesDirName, groupedDirName} {
+		for _, dir := range []string{derived, filepath.Join(derived, "*")} {
+			files, err := filepath.Glob(filepath.Join(s.baseDir, dir, "*.json"))
+			if err != nil {
+				return Manifest{}, fmt.Errorf("listing %s: %w", derived, err)
+			}
+			paths = append(paths, files...)
+		}
+	}
+	for _, dir := range []string{"data", filepath.Join("data", "*")} {
+		for _, pattern := range []string{"*.json", "*.json" + gzipSuffix, "*" + ndjsonSuffix} {
+			files, err := filepath.Glob(filepath.Join(s.baseDir, dir, pattern))
+			if err != nil {
+				return Manifest{}, fmt.Errorf("listing data files: %w", err)
+			}
+			paths = append(paths, files...)
+		}
+	}
+
+	var m Manifest
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return Manifest{}, fmt.Errorf("reading %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(s.baseDir, path)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("relativizing %s: %w", path, err)
+		}
+		sum := sha256.Sum256(content)
+		m.Files = append(m.Files, ManifestFile{
+			Path:   filepath.ToSlash(rel),
+			Size:   int64(len(content)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+	return m, nil
+}
+
+// WriteManifest builds the manifest of all data files and writes it to
+// manifest.json in the base directory.
+func (s *Storage) WriteManifest() error {
+	m, err := s.BuildManifest()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	if _, err := s.writeFile(s.manifestPath(), data, 0o644); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestWriteManifest(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	entry := model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: "aaa", Date: "2024-01-01T00:00:00Z"},
+		Params:     model.RunParams{CPU: "TestCPU", GOOS: "linux", GOARCH: "amd64"},
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 1, Unit: "ns/op"}},
+	}
+	if err := s.AppendEntry("feature/x", entry, 0); err != nil {
+		t.Fatalf("AppendEntry() error: %v", err)
+	}
+	if err := s.AppendEntry("v1.0.0", entry, 0); err != nil {
+		t.Fatalf("AppendEntry() error: %v", err)
+	}
+	if err := s.WriteMetadata("https://github.com/test/repo", "", nil); err != nil {
+		t.Fatalf("WriteMetadata() error: %v", err)
+	}
+
+	if err := s.WriteManifest(); err != nil {
+		t.Fatalf("WriteManifest() error: %v", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		t.Fatalf("reading manifest: %v", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("decoding manifest: %v", err)
+	}
+
+	wantPaths := []string{
+		"branches.json",
+		"data/feature_x.json",
+		"data/release_tags.json",
+		"data/releases.json",
+		"data/v1.0.0.json",
+		"metadata.json",
+		"summaries/feature_x.json",
+		"summaries/releases.json",
+		"summaries/v1.0.0.json",
+	}
+	if len(m.Files) != len(wantPaths) {
+		t.Fatalf("manifest lists %d files, want %d: %+v", len(m.Files), len(wantPaths), m.Files)
+	}
+
+	for i, f := range m.Files {
+		if f.Path != wantPaths[i] {
+			t.Errorf("file[%d] path = %q, want %q", i, f.Path, wantPaths[i])
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			t.Fatalf("reading %s: %v", f.Path, err)
+		}
+		sum := sha256.Sum256(content)
+		if f.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("%s: hash mismatch", f.Path)
+		}
+		if f.Size != int64(len(content)) {
+			t.Errorf("%s: size = %d, want %d", f.Path, f.Size, len(content))
+		}
+	}
+}
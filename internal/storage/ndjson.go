@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// ndjsonSuffix replaces the .json extension of branch data stored as
+// newline-delimited JSON.
+const ndjsonSuffix = ".ndjson"
+
+// WithNDJSON makes WriteBranchData store branch data as newline-delimited
+// JSON, one entry per line, in data/<branch>.ndjson. Merging entries that are
+// all newer than the stored ones, replace none of them and trim nothing then
+// appends their lines to the file instead of rewriting it. Files in another
+// format are migrated on the next write; ReadBranchData reads every format
+// regardless of this option.
+//
+// The option takes precedence over WithGzip and WithDeltaEncoding. With
+// WithBrotli, the compressed copy is rewritten on every merge, so appends
+// fall back to rewriting the file as well.
+func WithNDJSON() Option {
+	return func(s *Storage) {
+		s.ndjson = true
+	}
+}
+
+// ndjsonPath returns the path to data/<branch>.ndjson.
+func (s *Storage) ndjsonPath(branch string) string {
+	return filepath.Join(s.dataDir(), sanitizeBranchName(branch)+ndjsonSuffix)
+}
+
+// encodeNDJSON encodes entries as one compact JSON object per line.
+func encodeNDJSON(entries model.BranchData) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// completeNDJSON returns the length of the part of data that holds complete
+// lines. An append cut short by a crash leaves a last line without its
+// newline that does not decode; it is left out. A last line that only lacks
+// the newline but decodes is kept.
+func completeNDJSON(data []byte) int {
+	i := bytes.LastIndexByte(data, '\n') + 1
+	tail := bytes.TrimSpace(data[i:])
+	if len(tail) == 0 || json.Valid(tail) {
+		return len(data)
+	}
+	return i
+}
+
+// decodeNDJSON decodes newline-delimited branch data. Blank lines are
+// ignored, and so is a partial last line (see completeNDJSON).
+func decodeNDJSON(data []byte) (model.BranchData, error) {
+	data = data[:completeNDJSON(data)]
+	var entries model.BranchData
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, len(data)+1)
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e model.BenchmarkEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+// writeNDJSONBranchData is WriteBranchData under WithNDJSON. Data files in
+// the other formats are removed.
+func (s *Storage) writeNDJSONBranchData(branch string, entries model.BranchData) error {
+	data, err := encodeNDJSON(entries)
+	if err != nil {
+		return fmt.Errorf("encoding branch data: %w", err)
+	}
+	plain := s.branchDataPath(branch)
+	for _, p := range []string{plain, plain + gzipSuffix, plain + ".br", plain + gzipSuffix + ".br"} {
+		if err := removeIfExists(p); err != nil {
+			return fmt.Errorf("removing %s: %w", filepath.Base(p), err)
+		}
+	}
+	path := s.ndjsonPath(branch)
+	changed, err := s.writeFile(path, data, 0o644)
+	if err != nil {
+		return fmt.Errorf("writing branch data for %q: %w", branch, err)
+	}
+	if err := s.syncBrotli(path, data, changed); err != nil {
+		return fmt.Errorf("writing compressed branch data for %q: %w", branch, err)
+	}
+	return s.writeDerived(branch, entries)
+}
+
+// appendNDJSON appends entries to the newline-delimited data file at path.
+// Unlike the other writes it is not atomic: a crash midway can leave a
+// partial last line. Readers skip it (see decodeNDJSON), and the next append
+// truncates it before writing, so the file never stays corrupt.
+func appendNDJSON(path string, entries model.BranchData) error {
+	data, err := encodeNDJSON(entries)
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	n := completeNDJSON(existing)
+	if n < len(existing) {
+		if err := os.Truncate(path, int64(n)); err != nil {
+			return err
+		}
+	}
+	if n > 0 && existing[n-1] != '\n' {
+		data = append([]byte{'\n'}, data...)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// appendsInOrder reports whether newEntries can follow entries unchanged:
+// no key of entries is replaced and the combined slice is already in the
+// order sortByCommitDate gives.
+func (s *Storage) appendsInOrder(entries, newEntries model.BranchData) bool {
+	if len(entries) == 0 {
+		return false
+	}
+	newKeys := make(map[model.EntryKeyValue]struct{}, len(newEntries))
+	for _, e := range newEntries {
+		newKeys[s.entryKey(e)] = struct{}{}
+	}
+	for _, e := range entries {
+		if _, dup := newKeys[s.entryKey(e)]; dup {
+			return false
+		}
+	}
+	prev := entries[len(entries)-1]
+	for _, e := range newEntries {
+		c := compareCommitDates(prev, e)
+		if c > 0 || c == 0 && prev.Commit.SHA > e.Commit.SHA {
+			return false
+		}
+		prev = e
+	}
+	return true
+}
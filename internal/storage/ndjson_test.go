@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func ndjsonEntry(sha, date string) model.BenchmarkEntry {
+	return model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: sha, Date: date},
+		Params:     model.RunParams{CPU: "cpu"},
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 10, Unit: "ns/op"}},
+	}
+}
+
+func TestWithNDJSON_Migrates(t *testing.T) {
+	dir := t.TempDir()
+	data := model.BranchData{
+		ndjsonEntry("a", "2024-01-01T00:00:00Z"),
+		ndjsonEntry("b", "2024-01-02T00:00:00Z"),
+	}
+
+	plain, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := plain.WriteBranchData("feature/x", data); err != nil {
+		t.Fatal(err)
+	}
+	nd, err := New(dir, WithNDJSON())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if got := nd.BranchFileName("feature/x"); got != "feature_x.ndjson" {
+		t.Errorf("BranchFileName: got %q, want feature_x.ndjson", got)
+	}
+
+	// The plain file is read and replaced by the newline-delimited one.
+	got, err := nd.ReadBranchData("feature/x")
+	if err != nil || !reflect.DeepEqual(got, data) {
+		t.Fatalf("reading plain file: got %+v, %v; want %+v", got, err, data)
+	}
+	if err := nd.WriteBranchData("feature/x", got); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(plain.branchDataPath("feature/x")); !os.IsNotExist(err) {
+		t.Errorf("plain file should be removed after migration, stat error: %v", err)
+	}
+	raw, err := os.ReadFile(nd.ndjsonPath("feature/x"))
+	if err != nil {
+		t.Fatalf("newline-delimited file missing: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n"); len(lines) != 2 {
+		t.Errorf("got %d lines, want one per entry:\n%s", len(lines), raw)
+	}
+
+	// Both modes read it, and the plain mode migrates back on write.
+	for name, s := range map[string]*Storage{"ndjson": nd, "plain": plain} {
+		got, err := s.ReadBranchData("feature/x")
+		if err != nil || !reflect.DeepEqual(got, data) {
+			t.Errorf("%s: got %+v, %v; want %+v", name, got, err, data)
+		}
+	}
+	if err := plain.WriteBranchData("feature/x", data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(nd.ndjsonPath("feature/x")); !os.IsNotExist(err) {
+		t.Errorf("newline-delimited file should be removed, stat error: %v", err)
+	}
+}
+
+func TestWithNDJSON_AppendsInPlace(t *testing.T) {
+	var path string
+	rewrites := 0
+	s, err := New(t.TempDir(), WithNDJSON(), WithWriteFile(func(p string, content []byte, perm os.FileMode) (bool, error) {
+		if p == path {
+			rewrites++
+		}
+		return WriteIfChanged(p, content, perm)
+	}))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	path = s.ndjsonPath("main")
+
+	seed := []model.BenchmarkEntry{
+		ndjsonEntry("a", "2024-01-01T00:00:00Z"),
+		ndjsonEntry("b", "2024-01-02T00:00:00Z"),
+	}
+	if err := s.AppendEntries("main", seed, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		entries []model.BenchmarkEntry
+		policy  RetentionPolicy
+		rewrite bool
+		want    []string
+	}{
+		{"newer", []model.BenchmarkEntry{ndjsonEntry("c", "2024-01-03T00:00:00Z"), ndjsonEntry("d", "2024-01-03T00:00:00Z")}, RetentionPolicy{}, false, []string{"a", "b", "c", "d"}},
+		{"out of order", []model.BenchmarkEntry{ndjsonEntry("e", "2023-12-31T00:00:00Z")}, RetentionPolicy{}, true, []string{"e", "a", "b", "c", "d"}},
+		{"same date, lower SHA", []model.BenchmarkEntry{ndjsonEntry("0", "2024-01-03T00:00:00Z")}, RetentionPolicy{}, true, []string{"e", "a", "b", "0", "c", "d"}},
+		{"replaces", []model.BenchmarkEntry{ndjsonEntry("d", "2024-01-04T00:00:00Z")}, RetentionPolicy{}, true, []string{"e", "a", "b", "0", "c", "d"}},
+		{"trims", []model.BenchmarkEntry{ndjsonEntry("f", "2024-01-05T00:00:00Z")}, RetentionPolicy{MaxItems: 3}, true, []string{"c", "d", "f"}},
+		{"fits policy", []model.BenchmarkEntry{ndjsonEntry("g", "2024-01-06T00:00:00Z")}, RetentionPolicy{MaxItems: 4}, false, []string{"c", "d", "f", "g"}},
+	}
+	for _, tt := range tests {
+		before, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rewrites = 0
+		if err := s.AppendEntriesWithPolicy("main", tt.entries, tt.policy); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if (rewrites > 0) != tt.rewrite {
+			t.Errorf("%s: got %d rewrites, want rewrite %v", tt.name, rewrites, tt.rewrite)
+		}
+		after, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tt.rewrite && !bytes.HasPrefix(after, before) {
+			t.Errorf("%s: appending should keep the existing lines", tt.name)
+		}
+
+		got, err := s.ReadBranchData("main")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var shas []string
+		for _, e := range got {
+			shas = append(shas, e.Commit.SHA)
+		}
+		if !reflect.DeepEqual(shas, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, shas, tt.want)
+		}
+		summary, err := os.ReadFile(s.summaryPath("main"))
+		if err != nil || !strings.Contains(string(summary), `"latestSha": "`+tt.want[len(tt.want)-1]+`"`) {
+			t.Errorf("%s: summary not updated:\n%s", tt.name, summary)
+		}
+	}
+}
+
+func TestDecodeNDJSON_BadLine(t *testing.T) {
+	_, err := decodeNDJSON([]byte("{\"commit\":{\"id\":\"a\"}}\n\n{\"commit\":\n{}\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("got error %v, want one for line 3", err)
+	}
+}
+
+func TestWithNDJSON_PartialLastLine(t *testing.T) {
+	s, err := New(t.TempDir(), WithNDJSON())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	seed := []model.BenchmarkEntry{ndjsonEntry("a", "2024-01-01T00:00:00Z")}
+	if err := s.AppendEntries("main", seed, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash in the middle of appending the next line.
+	path := s.ndjsonPath("main")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"commit":{"id":"b","times`)
+	f.Close()
+
+	got, err := s.ReadBranchData("main")
+	if err != nil || len(got) != 1 || got[0].Commit.SHA != "a" {
+		t.Fatalf("got %+v, %v; want the complete entry only", got, err)
+	}
+
+	if err := s.AppendEntries("main", []model.BenchmarkEntry{ndjsonEntry("c", "2024-01-03T00:00:00Z")}, 0); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), `"times`) {
+		t.Errorf("partial line should be truncated by the next append:\n%s", raw)
+	}
+	got, err = s.ReadBranchData("main")
+	if err != nil || len(got) != 2 || got[1].Commit.SHA != "c" {
+		t.Errorf("got %+v, %v; want a and c", got, err)
+	}
+}
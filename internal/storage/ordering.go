@@ -0,0 +1,64 @@
+package storage
+
+import "fmt"
+
+// OrderingViolation is an adjacent pair of entries of a branch data file
+// whose commit dates go backwards: the entry at Index is dated before the
+// one at Index-1.
+type OrderingViolation struct {
+	Index    int    `json:"index"`
+	PrevSHA  string `json:"prevSha"`
+	PrevDate string `json:"prevDate"`
+	SHA      string `json:"sha"`
+	Date     string `json:"date"`
+}
+
+func (v OrderingViolation) String() string {
+	return fmt.Sprintf("entry %d (%s, %s) is dated before entry %d (%s, %s)",
+		v.Index, v.SHA, v.Date, v.Index-1, v.PrevSHA, v.PrevDate)
+}
+
+// CheckOrdering reports every adjacent pair of entries of branch that is
+// out of chronological order, comparing commit dates as sortByCommitDate
+// does. Entries with equal dates are never a violation. The data file is
+// not modified; see FixOrdering.
+func (s *Storage) CheckOrdering(branch string) ([]OrderingViolation, error) {
+	entries, err := s.ReadBranchData(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []OrderingViolation
+	for i := 1; i < len(entries); i++ {
+		prev, cur := entries[i-1], entries[i]
+		if compareCommitDates(cur, prev) < 0 {
+			violations = append(violations, OrderingViolation{
+				Index:    i,
+				PrevSHA:  prev.Commit.SHA,
+				PrevDate: prev.Commit.Date,
+				SHA:      cur.Commit.SHA,
+				Date:     cur.Commit.Date,
+			})
+		}
+	}
+	return violations, nil
+}
+
+// FixOrdering re-sorts the entries of branch by commit date and rewrites
+// the data file if CheckOrdering finds any violation. It reports whether
+// the file was rewritten.
+func (s *Storage) FixOrdering(branch string) (bool, error) {
+	violations, err := s.CheckOrdering(branch)
+	if err != nil || len(violations) == 0 {
+		return false, err
+	}
+	entries, err := s.ReadBranchData(branch)
+	if err != nil {
+		return false, err
+	}
+	sortByCommitDate(entries)
+	if err := s.WriteBranchData(branch, entries); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestCheckOrdering_OutOfOrderFixture(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	fixture, err := os.ReadFile("testdata/out_of_order.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.writeFile(s.branchDataPath("main"), fixture, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	violations, err := s.CheckOrdering("main")
+	if err != nil {
+		t.Fatalf("CheckOrdering() error: %v", err)
+	}
+	if len(violations) != 1 {
+		t.Fatalf("got %d violations, want 1: %v", len(violations), violations)
+	}
+	v := violations[0]
+	if v.Index != 2 || v.PrevSHA != "ccc" || v.SHA != "bbb" {
+		t.Errorf("got %+v, want bbb at index 2 dated before ccc", v)
+	}
+
+	fixed, err := s.FixOrdering("main")
+	if err != nil || !fixed {
+		t.Fatalf("FixOrdering() = %v, %v; want true, nil", fixed, err)
+	}
+	if violations, _ := s.CheckOrdering("main"); len(violations) != 0 {
+		t.Errorf("after FixOrdering: got violations %v", violations)
+	}
+	entries, err := s.ReadBranchData("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, e := range entries {
+		order = append(order, e.Commit.SHA)
+	}
+	if want := []string{"aaa", "bbb", "ccc", "ddd"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("got order %v after fix, want %v", order, want)
+	}
+
+	if fixed, err := s.FixOrdering("main"); err != nil || fixed {
+		t.Errorf("FixOrdering() on ordered data = %v, %v; want false, nil", fixed, err)
+	}
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// pinnedBaselinesPath returns the path to data/pinned_baselines.json.
+func (s *Storage) pinnedBaselinesPath() string {
+	return filepath.Join(s.dataDir(), "pinned_baselines.json")
+}
+
+// ReadPinnedBaselines returns the pinned regression baselines: benchmark
+// name globs mapped to the commit SHA each matching benchmark is compared
+// against (see regression.CheckEntryPinned). A missing file yields an empty
+// map.
+func (s *Storage) ReadPinnedBaselines() (map[string]string, error) {
+	data, err := os.ReadFile(s.pinnedBaselinesPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("reading pinned baselines: %w", err)
+	}
+	pins := map[string]string{}
+	if err := json.Unmarshal(data, &pins); err != nil {
+		return nil, fmt.Errorf("decoding pinned baselines: %w", err)
+	}
+	return pins, nil
+}
+
+// PinBaseline pins benchmarks matching pattern to the commit sha of branch,
+// replacing any previous pin of the same pattern, and returns the full SHA
+// pinned. sha may be abbreviated as for ResolveSHA; it is an error if it
+// names no stored commit of branch, since the regression check would
+// silently fall back to the rolling baseline. An empty sha removes the pin.
+func (s *Storage) PinBaseline(branch, pattern, sha string) (string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return "", fmt.Errorf("invalid benchmark pattern %q: %w", pattern, err)
+	}
+
+	pins, err := s.ReadPinnedBaselines()
+	if err != nil {
+		return "", err
+	}
+	if sha == "" {
+		delete(pins, pattern)
+	} else {
+		if sha, err = s.ResolveSHA(branch, sha); err != nil {
+			return "", err
+		}
+		pins[pattern] = sha
+	}
+
+	data, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding pinned baselines: %w", err)
+	}
+	if _, err := s.writeFile(s.pinnedBaselinesPath(), data, 0o644); err != nil {
+		return "", fmt.Errorf("writing pinned baselines: %w", err)
+	}
+	return sha, nil
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestPinBaseline(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	got, err := s.ReadPinnedBaselines()
+	if err != nil || len(got) != 0 {
+		t.Fatalf("ReadPinnedBaselines() on a new store = %v, %v; want empty", got, err)
+	}
+
+	if err := s.AppendEntries("main", []model.BenchmarkEntry{
+		makeEntry("aaa111", 1), makeEntry("bbb222", 1), makeEntry("ccc333", 1), makeEntry("ccc444", 1),
+	}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.PinBaseline("main", "BenchmarkParse*", "aaa111"); err != nil {
+		t.Fatal(err)
+	}
+	if full, err := s.PinBaseline("main", "BenchmarkEncode", "bbb"); err != nil || full != "bbb222" {
+		t.Fatalf("pinning a prefix: got %q, %v; want bbb222", full, err)
+	}
+	if _, err := s.PinBaseline("main", "BenchmarkParse*", "ccc333"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.PinBaseline("main", "Benchmark[", "aaa111"); err == nil {
+		t.Error("expected error for a malformed glob")
+	}
+	if _, err := s.PinBaseline("main", "BenchmarkDecode", "ddd"); err == nil {
+		t.Error("expected error for a SHA not stored on the branch")
+	}
+	if _, err := s.PinBaseline("main", "BenchmarkDecode", "ccc"); !errors.Is(err, ErrAmbiguousSHA) {
+		t.Errorf("got error %v for an ambiguous prefix, want ErrAmbiguousSHA", err)
+	}
+
+	got, err = s.ReadPinnedBaselines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"BenchmarkParse*": "ccc333", "BenchmarkEncode": "bbb222"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := s.PinBaseline("", "BenchmarkEncode", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = s.ReadPinnedBaselines(); !reflect.DeepEqual(got, map[string]string{"BenchmarkParse*": "ccc333"}) {
+		t.Errorf("after unpin: got %v", got)
+	}
+}
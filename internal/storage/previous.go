@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// PreviousEntry returns the most recent entry stored on branch that has
+// the same key as entry apart from the commit (run parameters and tags, see
+// model.EntryKey) and is not dated after it. Entries for entry's own commit
+// are ignored, so re-storing a commit does not find itself. It returns nil
+// if there is no such entry.
+func (s *Storage) PreviousEntry(branch string, entry model.BenchmarkEntry) (*model.BenchmarkEntry, error) {
+	data, err := s.ReadBranchData(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := model.DefaultKeyConfig
+	cfg.SHA = false
+	key := entry.KeyWith(cfg)
+
+	var prev *model.BenchmarkEntry
+	for i := range data {
+		e := &data[i]
+		if e.Commit.SHA == entry.Commit.SHA || e.Date > entry.Date || e.KeyWith(cfg) != key {
+			continue
+		}
+		if prev == nil || e.Date >= prev.Date {
+			prev = e
+		}
+	}
+	return prev, nil
+}
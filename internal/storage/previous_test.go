@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestPreviousEntry(t *testing.T) {
+	intel := model.RunParams{CPU: "Intel", GOOS: "linux", GOARCH: "amd64"}
+	amd := model.RunParams{CPU: "AMD", GOOS: "linux", GOARCH: "amd64"}
+	entry := func(sha string, date int64, params model.RunParams, ns float64) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha},
+			Date:       date,
+			Params:     params,
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: ns, Unit: "ns/op"}},
+		}
+	}
+
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := s.WriteBranchData("main", model.BranchData{
+		entry("a", 1, intel, 50),
+		entry("b", 2, intel, 100),
+		entry("b", 2, amd, 10),
+		entry("d", 4, intel, 1),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		entry model.BenchmarkEntry
+		want  string // SHA of the previous entry, "" for none
+		value float64
+	}{
+		{"newest earlier entry of the same params", entry("c", 3, intel, 120), "b", 100},
+		{"other params", entry("c", 3, amd, 12), "b", 10},
+		{"own commit ignored", entry("b", 2, intel, 100), "a", 50},
+		{"no comparable entry", entry("c", 3, model.RunParams{CPU: "ARM"}, 1), "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev, err := s.PreviousEntry("main", tt.entry)
+			if err != nil {
+				t.Fatalf("PreviousEntry() error: %v", err)
+			}
+			if tt.want == "" {
+				if prev != nil {
+					t.Errorf("got %+v, want nil", prev)
+				}
+				return
+			}
+			if prev == nil || prev.Commit.SHA != tt.want || prev.Benchmarks[0].Value != tt.value {
+				t.Errorf("got %+v, want %s with %v", prev, tt.want, tt.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// PruneBranches removes every branch listed in branches.json that is not in
+// keep, together with its data files (data/<branch>.json or .ndjson, their
+// .gz and .br companions) and the files derived from it (its summary and
+// grouped data), and returns the removed branches in list order. The
+// "releases" virtual branch and the per-tag files behind it are never
+// pruned. With dryRun nothing is changed on disk.
+func (s *Storage) PruneBranches(keep []string, dryRun bool) ([]string, error) {
+	branches, err := s.ReadBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	var kept, removed []string
+	for _, b := range branches {
+		if b == ReleasesVirtualBranch || slices.Contains(keep, b) {
+			kept = append(kept, b)
+			continue
+		}
+		removed = append(removed, b)
+	}
+	if dryRun || len(removed) == 0 {
+		return removed, nil
+	}
+
+	for _, b := range removed {
+		path := s.branchDataPath(b)
+		ndjson := s.ndjsonPath(b)
+		for _, p := range []string{path, path + gzipSuffix, path + ".br", ndjson, ndjson + ".br", s.summaryPath(b), s.groupedPath(b)} {
+			if err := removeIfExists(p); err != nil {
+				return nil, fmt.Errorf("removing %s: %w", filepath.Base(p), err)
+			}
+		}
+	}
+	if err := s.WriteBranches(kept); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
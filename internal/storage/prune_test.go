@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestPruneBranches(t *testing.T) {
+	s, err := New(t.TempDir(), WithBrotli())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	entry := model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: "abc", Date: "2024-01-01T00:00:00Z"},
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 1, Unit: "ns/op"}},
+	}
+	for _, b := range []string{"main", "feature/gone", "fix/old", "v1.0.0"} {
+		if err := s.AppendEntries(b, []model.BenchmarkEntry{entry}, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.WriteGrouped("fix/old"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"feature/gone", "fix/old"}
+
+	// A dry run reports without touching disk.
+	removed, err := s.PruneBranches([]string{"main"}, true)
+	if err != nil {
+		t.Fatalf("PruneBranches(dry run) error: %v", err)
+	}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("dry run: got %v, want %v", removed, want)
+	}
+	if _, err := os.Stat(s.branchDataPath("fix/old")); err != nil {
+		t.Errorf("dry run removed a data file: %v", err)
+	}
+
+	removed, err = s.PruneBranches([]string{"main"}, false)
+	if err != nil {
+		t.Fatalf("PruneBranches() error: %v", err)
+	}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("got %v, want %v", removed, want)
+	}
+
+	branches, err := s.ReadBranches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{ReleasesVirtualBranch, "main"}; !reflect.DeepEqual(branches, want) {
+		t.Errorf("branches.json: got %v, want %v", branches, want)
+	}
+	for _, b := range want {
+		path := s.branchDataPath(b)
+		for _, p := range []string{path, path + ".br", s.summaryPath(b), s.groupedPath(b)} {
+			if _, err := os.Stat(p); !os.IsNotExist(err) {
+				t.Errorf("%s should be removed, stat error: %v", p, err)
+			}
+		}
+	}
+	for _, b := range []string{"main", ReleasesVirtualBranch, "v1.0.0"} {
+		if _, err := os.Stat(s.branchDataPath(b)); err != nil {
+			t.Errorf("data of %s should be kept: %v", b, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// ReadAllBranches reads the data file of every branch in branches.json,
+// with up to concurrency files read in parallel. The result is keyed by
+// branch name, so it does not depend on the order in which reads finish;
+// a branch without data maps to nil. A concurrency below 1 reads serially.
+func (s *Storage) ReadAllBranches(concurrency int) (map[string]model.BranchData, error) {
+	branches, err := s.ReadBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	out := make(map[string]model.BranchData, len(branches))
+	sem := make(chan struct{}, concurrency)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, branch := range branches {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			data, err := s.ReadBranchData(branch)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("reading %q: %w", branch, err))
+				return
+			}
+			out[branch] = data
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
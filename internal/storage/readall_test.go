@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// seedBranches registers n branches, each holding entries entries.
+func seedBranches(tb testing.TB, s *Storage, n, entries int) {
+	tb.Helper()
+	for i := 0; i < n; i++ {
+		branch := fmt.Sprintf("feature-%02d", i)
+		data := make(model.BranchData, entries)
+		for j := range data {
+			data[j] = makeEntry(fmt.Sprintf("%038x%02d", j, i), 20)
+			data[j].Date += int64(j)
+		}
+		if err := s.WriteBranchData(branch, data); err != nil {
+			tb.Fatal(err)
+		}
+		if _, err := s.EnsureBranch(branch); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+func TestReadAllBranches_MatchesSerial(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	seedBranches(t, s, 12, 5)
+	// A registered branch without a data file maps to nil.
+	if _, err := s.EnsureBranch("empty"); err != nil {
+		t.Fatal(err)
+	}
+
+	branches, err := s.ReadBranches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[string]model.BranchData, len(branches))
+	for _, b := range branches {
+		if want[b], err = s.ReadBranchData(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, concurrency := range []int{0, 1, 4, 32} {
+		got, err := s.ReadAllBranches(concurrency)
+		if err != nil {
+			t.Fatalf("ReadAllBranches(%d) error: %v", concurrency, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ReadAllBranches(%d) differs from the serial reads", concurrency)
+		}
+	}
+}
+
+func TestReadAllBranches_Error(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	seedBranches(t, s, 3, 1)
+	if err := os.WriteFile(s.branchDataPath("feature-01"), []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ReadAllBranches(2); err == nil {
+		t.Fatal("expected an error for a corrupt data file")
+	}
+}
+
+func BenchmarkReadAllBranches_50(b *testing.B) {
+	dir := b.TempDir()
+	s, err := New(dir)
+	if err != nil {
+		b.Fatal(err)
+	}
+	seedBranches(b, s, 50, 100)
+
+	for _, concurrency := range []int{1, 8} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := s.ReadAllBranches(concurrency); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// RecomputeOptions selects the per-entry derivations applied by store and
+// by RecomputeAll.
+type RecomputeOptions struct {
+	// CanonicalizeNames sorts the key=value segments of benchmark names
+	// (see model.CanonicalName).
+	CanonicalizeNames bool
+
+	// SortBenchmarks orders each entry's results (see model.SortBenchmarks).
+	SortBenchmarks bool
+
+	// Precision rounds values per unit (see model.RoundByUnit). Nil
+	// disables rounding.
+	Precision map[string]int
+
+	// ThroughputFromSize adds a bytes/sec metric to ns/op results whose
+	// name has a size=N dimension (see model.DeriveThroughput).
+	ThroughputFromSize bool
+}
+
+// Apply runs the selected derivations on e in place. Every result is also
+// (re)marked Custom by its unit, so that data parsed before the flag
+// existed gets it on recompute.
+func (o RecomputeOptions) Apply(e *model.BenchmarkEntry) {
+	if o.ThroughputFromSize {
+		e.Benchmarks = model.DeriveThroughput(e.Benchmarks)
+	}
+	for i := range e.Benchmarks {
+		e.Benchmarks[i].Custom = !model.IsStandardUnit(e.Benchmarks[i].Unit)
+		if o.CanonicalizeNames {
+			e.Benchmarks[i].Name = model.CanonicalName(e.Benchmarks[i].Name)
+		}
+		if o.Precision != nil {
+			model.RoundByUnit(&e.Benchmarks[i], o.Precision)
+		}
+	}
+	if o.SortBenchmarks {
+		model.SortBenchmarks(e.Benchmarks)
+	}
+}
+
+// RecomputeAll reprocesses every stored data file: each registered branch
+// and each release tag file is reloaded, the derivations in opts are applied
+// to every entry, duplicates (by entry key) are collapsed keeping the last
+// one, entries are re-sorted by commit date and the file is rewritten. The
+// "releases" aggregate is then rebuilt from the per-tag files, honouring
+// WithStableReleasesOnly.
+//
+// It is the batch counterpart of the derivations store applies to new
+// entries, for backfilling existing datasets.
+func (s *Storage) RecomputeAll(opts RecomputeOptions) error {
+	branches, err := s.ReadBranches()
+	if err != nil {
+		return err
+	}
+	tags, err := s.releaseTagNames()
+	if err != nil {
+		return err
+	}
+
+	for _, branch := range branches {
+		if branch == ReleasesVirtualBranch && len(tags) > 0 {
+			continue // rebuilt from the tag files below
+		}
+		if _, err := s.recomputeFile(branch, opts); err != nil {
+			return err
+		}
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var releases model.BranchData
+	for _, tag := range tags {
+		entries, err := s.recomputeFile(tag, opts)
+		if err != nil {
+			return err
+		}
+		if s.aggregatesIntoReleases(tag) {
+			releases = append(releases, entries...)
+		}
+	}
+	releases = s.dedupe(releases)
+	sortByCommitDate(releases)
+	return s.WriteBranchData(ReleasesVirtualBranch, releases)
+}
+
+// recomputeFile applies opts to every entry of branch, dedupes, sorts and
+// rewrites the data file. It returns the resulting entries.
+func (s *Storage) recomputeFile(branch string, opts RecomputeOptions) (model.BranchData, error) {
+	entries, err := s.ReadBranchData(branch)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		return nil, nil
+	}
+	for i := range entries {
+		opts.Apply(&entries[i])
+	}
+	entries = s.dedupe(entries)
+	sortByCommitDate(entries)
+	if err := s.WriteBranchData(branch, entries); err != nil {
+		return nil, fmt.Errorf("recomputing %q: %w", branch, err)
+	}
+	return entries, nil
+}
+
+// dedupe keeps the last entry for every entry key, preserving the order of
+// those last occurrences.
+func (s *Storage) dedupe(entries model.BranchData) model.BranchData {
+	last := make(map[model.EntryKeyValue]int, len(entries))
+	for i, e := range entries {
+		last[s.entryKey(e)] = i
+	}
+	out := entries[:0]
+	for i, e := range entries {
+		if last[s.entryKey(e)] == i {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// releaseTagNames returns the distinct tags recorded in release_tags.json,
+// sorted.
+func (s *Storage) releaseTagNames() ([]string, error) {
+	bySHA, err := s.readReleaseTags()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	var tags []string
+	for _, tag := range bySHA {
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
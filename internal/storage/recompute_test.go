@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestRecomputeAll(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	raw := func(sha string, date int64) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit: model.Commit{SHA: sha},
+			Date:   date,
+			Benchmarks: []model.BenchmarkResult{
+				{Name: "BenchmarkZ", Value: 3.0001, Unit: "allocs/op"},
+				{Name: "BenchmarkA/b=2/a=1", Value: 456.789, Unit: "ns/op"},
+			},
+		}
+	}
+	if err := s.AppendEntries("main", []model.BenchmarkEntry{raw("aaa", 1000), raw("bbb", 2000)}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AppendEntries("v1.0.0", []model.BenchmarkEntry{raw("aaa", 1000)}, 0); err != nil {
+		t.Fatal(err)
+	}
+	// Lose the releases aggregate to check it is rebuilt from the tag file.
+	if err := os.Remove(s.branchDataPath(ReleasesVirtualBranch)); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := RecomputeOptions{CanonicalizeNames: true, SortBenchmarks: true, Precision: model.DefaultPrecision}
+	if err := s.RecomputeAll(opts); err != nil {
+		t.Fatalf("RecomputeAll() error: %v", err)
+	}
+
+	want := []model.BenchmarkResult{
+		{Name: "BenchmarkA/a=1/b=2", Value: 456.79, Unit: "ns/op"},
+		{Name: "BenchmarkZ", Value: 3, Unit: "allocs/op"},
+	}
+	for _, branch := range []string{"main", "v1.0.0", ReleasesVirtualBranch} {
+		data, err := s.ReadBranchData(branch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) == 0 {
+			t.Fatalf("%s: no entries after recompute", branch)
+		}
+		for _, e := range data {
+			if len(e.Benchmarks) != len(want) {
+				t.Fatalf("%s/%s: got %+v", branch, e.Commit.SHA, e.Benchmarks)
+			}
+			for i := range want {
+				if e.Benchmarks[i] != want[i] {
+					t.Errorf("%s/%s result %d: got %+v, want %+v", branch, e.Commit.SHA, i, e.Benchmarks[i], want[i])
+				}
+			}
+		}
+	}
+}
+
+func TestRecomputeOptionsApply_MarksCustom(t *testing.T) {
+	e := model.BenchmarkEntry{Benchmarks: []model.BenchmarkResult{
+		{Name: "BenchmarkRender", Value: 10, Unit: "ns/op"},
+		{Name: "BenchmarkRender - frames/sec", Value: 60, Unit: "frames/sec"},
+		{Name: "BenchmarkRender - MB/s", Value: 5, Unit: "MB/s", Custom: true},
+	}}
+	RecomputeOptions{}.Apply(&e)
+
+	for i, want := range []bool{false, true, false} {
+		if got := e.Benchmarks[i].Custom; got != want {
+			t.Errorf("%s: custom got %v, want %v", e.Benchmarks[i].Unit, got, want)
+		}
+	}
+}
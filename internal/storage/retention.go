@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// RetentionPolicy bounds the entries a branch keeps after a merge. Zero
+// fields impose no limit.
+type RetentionPolicy struct {
+	// MaxAge drops entries whose commit date is more than MaxAge before
+	// the storage clock (see WithClock). Entries whose commit date does
+	// not parse as RFC 3339 are kept rather than risk deleting them.
+	MaxAge time.Duration
+	// MaxItems keeps at most this many of the newest entries. It is
+	// applied after MaxAge.
+	MaxItems int
+}
+
+// apply returns the entries of data, sorted by commit date, that p keeps at
+// time now.
+func (p RetentionPolicy) apply(data model.BranchData, now time.Time) model.BranchData {
+	if p.MaxAge > 0 {
+		cutoff := now.Add(-p.MaxAge)
+		kept := data[:0]
+		for _, e := range data {
+			t, err := time.Parse(time.RFC3339, e.Commit.Date)
+			if err != nil || !t.Before(cutoff) {
+				kept = append(kept, e)
+			}
+		}
+		data = kept
+	}
+	if p.MaxItems > 0 && len(data) > p.MaxItems {
+		data = data[len(data)-p.MaxItems:]
+	}
+	return data
+}
+
+// AppendEntriesWithPolicy is AppendEntries trimming the branch by policy
+// instead of a plain entry count.
+func (s *Storage) AppendEntriesWithPolicy(branch string, newEntries []model.BenchmarkEntry, policy RetentionPolicy) error {
+	return s.appendBranches(context.Background(), map[string][]model.BenchmarkEntry{branch: newEntries}, policy, 1)
+}
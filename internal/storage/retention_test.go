@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestAppendEntriesWithPolicy(t *testing.T) {
+	now := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	entry := func(sha, date string) model.BenchmarkEntry {
+		e := model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha, Date: date},
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 1, Unit: "ns/op"}},
+		}
+		if t, err := time.Parse(time.RFC3339, date); err == nil {
+			e.Date = t.UnixMilli()
+		}
+		return e
+	}
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		want   []string
+	}{
+		{"no limit", RetentionPolicy{}, []string{"bad", "old", "mid", "new", "head"}},
+		{"max age", RetentionPolicy{MaxAge: 30 * 24 * time.Hour}, []string{"bad", "mid", "new", "head"}},
+		{"max items", RetentionPolicy{MaxItems: 2}, []string{"new", "head"}},
+		{"age then items", RetentionPolicy{MaxAge: 30 * 24 * time.Hour, MaxItems: 3}, []string{"mid", "new", "head"}},
+		{"short max age keeps only undated", RetentionPolicy{MaxAge: time.Hour}, []string{"bad"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(t.TempDir(), WithClock(func() time.Time { return now }))
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			if err := s.WriteBranchData("main", model.BranchData{
+				entry("bad", "last tuesday"),
+				entry("old", "2026-01-01T00:00:00Z"),
+				entry("mid", "2026-03-15T00:00:00Z"),
+				entry("new", "2026-03-30T00:00:00Z"),
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := s.AppendEntriesWithPolicy("main", []model.BenchmarkEntry{entry("head", "2026-03-31T00:00:00Z")}, tt.policy); err != nil {
+				t.Fatalf("AppendEntriesWithPolicy() error: %v", err)
+			}
+
+			data, err := s.ReadBranchData("main")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range data {
+				got = append(got, e.Commit.SHA)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
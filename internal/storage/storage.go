@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,7 +10,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/royalcat/go-continuous-benchmarking/internal/model"
@@ -33,20 +38,43 @@ func IsSemanticVersionTag(name string) bool {
 	return semverRe.MatchString(name)
 }
 
+// preReleaseRe matches semver tags carrying a pre-release suffix
+// (e.g. "v1.0.0-rc.1"). Build metadata ("+build.5") is not a pre-release.
+var preReleaseRe = regexp.MustCompile(`^v?\d+\.\d+\.\d+-`)
+
+// IsPreRelease reports whether name is a semver tag with a pre-release
+// suffix (e.g. "v1.0.0-rc.1", "2.0.0-beta"). Stable releases such as
+// "v1.0.0" and non-semver names return false.
+func IsPreRelease(name string) bool {
+	return preReleaseRe.MatchString(name)
+}
+
 // sortByCommitDate sorts entries by their Commit.Date field (RFC 3339 string).
 // Entries with unparseable dates are placed at the beginning.
+//
+// Entries with identical dates are ordered by commit SHA so that the result
+// is deterministic regardless of the order in which they were appended.
 func sortByCommitDate(entries model.BranchData) {
 	sort.SliceStable(entries, func(i, j int) bool {
-		ti, erri := time.Parse(time.RFC3339, entries[i].Commit.Date)
-		tj, errj := time.Parse(time.RFC3339, entries[j].Commit.Date)
-		if erri != nil || errj != nil {
-			// Fall back to the Date (unix millis) field when parsing fails.
-			return entries[i].Date < entries[j].Date
+		if c := compareCommitDates(entries[i], entries[j]); c != 0 {
+			return c < 0
 		}
-		return ti.Before(tj)
+		return entries[i].Commit.SHA < entries[j].Commit.SHA
 	})
 }
 
+// compareCommitDates orders a and b by their RFC 3339 commit dates,
+// falling back to the Date (unix millis) field when either fails to parse.
+// It returns -1, 0 or +1 like cmp.Compare.
+func compareCommitDates(a, b model.BenchmarkEntry) int {
+	ta, erra := time.Parse(time.RFC3339, a.Commit.Date)
+	tb, errb := time.Parse(time.RFC3339, b.Commit.Date)
+	if erra != nil || errb != nil {
+		return cmp.Compare(a.Date, b.Date)
+	}
+	return ta.Compare(tb)
+}
+
 // Storage manages benchmark data files on disk.
 // The layout on disk is:
 //
@@ -54,36 +82,246 @@ func sortByCommitDate(entries model.BranchData) {
 //	  branches.json          – JSON array of branch name strings
 //	  data/
 //	    <branch>.json        – JSON array of BenchmarkEntry per branch
+//	    <branch>.ndjson      – the same, one entry per line (WithNDJSON)
+//	    <suite>/             – branches.json and data files of a named
+//	                           suite (WithSuite), listed in suites.json
 type Storage struct {
 	baseDir string
+
+	// stableReleasesOnly excludes pre-release tags from the releases
+	// aggregate. They still get their own per-tag data file.
+	stableReleasesOnly bool
+
+	// force skips the check that refuses to initialize a non-empty
+	// directory not created by this tool.
+	force bool
+
+	// keyConfig selects the dimensions of the key used to replace existing
+	// entries. Defaults to model.DefaultKeyConfig.
+	keyConfig model.KeyConfig
+
+	// brotli writes a precompressed <file>.br next to every branch data file.
+	brotli bool
+
+	// gzip stores branch data files gzip-compressed; see WithGzip.
+	gzip bool
+
+	// ndjson stores branch data files as newline-delimited JSON; see
+	// WithNDJSON.
+	ndjson bool
+
+	// suite namespaces the branch list and data files; see WithSuite.
+	suite string
+
+	// clock returns the current time for timestamps written to disk.
+	// Defaults to time.Now.
+	clock func() time.Time
+
+	// writer persists every file the storage writes; see WithWriteFile
+	// and writeFile.
+	writer func(path string, content []byte, perm os.FileMode) (bool, error)
+
+	// initOnce guards the creation of the storage layout on the first
+	// write; see initialize.
+	initOnce sync.Once
+	initErr  error
+
+	// deltaAnchorEvery enables delta encoding of branch data files when
+	// non-zero; see WithDeltaEncoding.
+	deltaAnchorEvery int
+}
+
+// Option configures optional Storage behaviour.
+type Option func(*Storage)
+
+// WithStableReleasesOnly keeps pre-release tags (e.g. "v1.0.0-rc.1") out of
+// the aggregated releases data. Their entries are still written to the
+// per-tag data file.
+func WithStableReleasesOnly() Option {
+	return func(s *Storage) {
+		s.stableReleasesOnly = true
+	}
+}
+
+// WithForce allows New to initialize a non-empty directory that does not
+// look like benchmark storage.
+func WithForce() Option {
+	return func(s *Storage) {
+		s.force = true
+	}
+}
+
+// WithEnvDedup treats entries that differ only in their captured Environment
+// as distinct runs instead of replacing one with the other.
+func WithEnvDedup() Option {
+	return func(s *Storage) {
+		s.keyConfig.Env = true
+	}
+}
+
+// WithBrotli makes WriteBranchData also write a brotli-compressed copy of
+// each data file (data/<branch>.json.br) for servers that negotiate
+// Content-Encoding.
+func WithBrotli() Option {
+	return func(s *Storage) {
+		s.brotli = true
+	}
+}
+
+// WithClock makes the storage use now instead of time.Now for every
+// timestamp it writes (e.g. metadata.json's lastUpdate), so that output is
+// reproducible.
+func WithClock(now func() time.Time) Option {
+	return func(s *Storage) {
+		s.clock = now
+	}
+}
+
+// WithWriteFile makes the storage persist every file through write instead
+// of WriteIfChanged, e.g. to simulate a slow or hung filesystem in tests.
+// write reports whether the file's content changed.
+func WithWriteFile(write func(path string, content []byte, perm os.FileMode) (changed bool, err error)) Option {
+	return func(s *Storage) {
+		s.writer = write
+	}
+}
+
+// Now returns the current time by the storage's clock; see WithClock.
+func (s *Storage) Now() time.Time {
+	return s.clock()
+}
+
+// WithKeyConfig sets the dimensions that make up the key used to replace
+// existing entries. Combined with WithEnvDedup, the environment is added on
+// top of cfg regardless of the option order.
+func WithKeyConfig(cfg model.KeyConfig) Option {
+	return func(s *Storage) {
+		env := s.keyConfig.Env
+		s.keyConfig = cfg
+		s.keyConfig.Env = cfg.Env || env
+	}
+}
+
+// entryKey returns the deduplication key for e under s's options.
+func (s *Storage) entryKey(e model.BenchmarkEntry) model.EntryKeyValue {
+	return e.KeyWith(s.keyConfig)
+}
+
+// markerFileName is written into every storage directory on first use so
+// that later runs can tell it apart from an unrelated directory.
+const markerFileName = ".gobenchdata"
+
+// ErrForeignDirectory is returned by New when baseDir is non-empty and does
+// not contain benchmark storage.
+var ErrForeignDirectory = errors.New("directory is not empty and was not created by gobenchdata")
+
+// New creates a Storage rooted at baseDir. It does not touch the disk:
+// baseDir, the data/ subdirectory and the storage marker are created by the
+// first write, so read-only commands leave a missing directory missing.
+//
+// To avoid scattering files into e.g. a source tree by accident, New refuses
+// a non-empty baseDir that has no storage marker, branches.json or
+// metadata.json unless WithForce is given.
+func New(baseDir string, opts ...Option) (*Storage, error) {
+	s := &Storage{baseDir: baseDir, clock: time.Now, keyConfig: model.DefaultKeyConfig, writer: WriteIfChanged}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.suite != "" && !ValidSuiteName(s.suite) {
+		return nil, fmt.Errorf("invalid suite name %q (want letters, digits, '-' and '_')", s.suite)
+	}
+
+	if !s.force {
+		if err := assertInitializable(baseDir); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// initialize creates the data directory and writes the storage marker,
+// once per Storage. Every write goes through it; see writeFile.
+func (s *Storage) initialize() error {
+	s.initOnce.Do(func() {
+		if err := os.MkdirAll(s.dataDir(), 0o755); err != nil {
+			s.initErr = fmt.Errorf("creating data directory: %w", err)
+			return
+		}
+		markerPath := filepath.Join(s.baseDir, markerFileName)
+		if _, err := os.Stat(markerPath); errors.Is(err, fs.ErrNotExist) {
+			if _, err := s.writer(markerPath, []byte("gobenchdata storage\n"), 0o644); err != nil {
+				s.initErr = fmt.Errorf("writing storage marker: %w", err)
+			}
+		}
+	})
+	return s.initErr
+}
+
+// writeFile persists content at path with the configured writer, after
+// initializing the storage and creating the parent directory of path.
+func (s *Storage) writeFile(path string, content []byte, perm os.FileMode) (bool, error) {
+	if err := s.initialize(); err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, err
+	}
+	return s.writer(path, content, perm)
+}
+
+// assertInitializable returns nil if dir is missing, empty, or already holds
+// benchmark storage (marker file, branches.json or metadata.json), and
+// ErrForeignDirectory otherwise.
+func assertInitializable(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("reading %s: %w", dir, err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	for _, known := range []string{markerFileName, "branches.json", "metadata.json"} {
+		if _, err := os.Stat(filepath.Join(dir, known)); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %w (use -force to override)", dir, ErrForeignDirectory)
 }
 
-// New creates a Storage rooted at baseDir.
-// It ensures the base directory and the data/ subdirectory exist.
-func New(baseDir string) (*Storage, error) {
-	dataDir := filepath.Join(baseDir, "data")
-	if err := os.MkdirAll(dataDir, 0o755); err != nil {
-		return nil, fmt.Errorf("creating data directory: %w", err)
+// aggregatesIntoReleases reports whether entries stored for branch are also
+// merged into the releases aggregate.
+func (s *Storage) aggregatesIntoReleases(branch string) bool {
+	if !IsSemanticVersionTag(branch) {
+		return false
 	}
-	return &Storage{baseDir: baseDir}, nil
+	return !s.stableReleasesOnly || !IsPreRelease(branch)
 }
 
-// branchesPath returns the path to branches.json.
+// branchesPath returns the path to branches.json, or to
+// branches/<suite>.json for a named suite.
 func (s *Storage) branchesPath() string {
+	if s.suite != "" {
+		return filepath.Join(s.baseDir, suiteBranchesDirName, s.suite+".json")
+	}
 	return filepath.Join(s.baseDir, "branches.json")
 }
 
-// branchDataPath returns the path to data/<branch>.json.
+// branchDataPath returns the path to data/<branch>.json (data/<suite>/ for
+// a named suite).
 // Branch names are sanitised so they are safe as file names: slashes are
 // replaced with double underscores.
 func (s *Storage) branchDataPath(branch string) string {
 	safe := sanitizeBranchName(branch)
-	return filepath.Join(s.baseDir, "data", safe+".json")
+	return filepath.Join(s.dataDir(), safe+".json")
 }
 
 // releaseTagsPath returns the path to data/release_tags.json.
 func (s *Storage) releaseTagsPath() string {
-	return filepath.Join(s.baseDir, "data", releaseTagsFileName)
+	return filepath.Join(s.dataDir(), releaseTagsFileName)
 }
 
 // sanitizeBranchName replaces characters that are problematic in file names.
@@ -106,8 +344,8 @@ func sanitizeBranchName(branch string) string {
 // Branch list operations
 // --------------------------------------------------------------------------
 
-// ReadBranches reads the branch list from branches.json.
-// If the file does not exist an empty slice is returned.
+// ReadBranches reads the branch list from branches.json, normalized as by
+// normalizeBranches. If the file does not exist an empty slice is returned.
 func (s *Storage) ReadBranches() ([]string, error) {
 	data, err := os.ReadFile(s.branchesPath())
 	if err != nil {
@@ -121,19 +359,20 @@ func (s *Storage) ReadBranches() ([]string, error) {
 	if err := json.Unmarshal(data, &branches); err != nil {
 		return nil, fmt.Errorf("decoding branches file: %w", err)
 	}
-	return branches, nil
+	return normalizeBranches(branches), nil
 }
 
-// WriteBranches writes the branch list to branches.json.
+// WriteBranches writes the branch list to branches.json, normalized as by
+// normalizeBranches, and registers a named suite in suites.json.
 func (s *Storage) WriteBranches(branches []string) error {
-	data, err := json.MarshalIndent(branches, "", "  ")
+	data, err := json.MarshalIndent(normalizeBranches(branches), "", "  ")
 	if err != nil {
 		return fmt.Errorf("encoding branches: %w", err)
 	}
-	if err := os.WriteFile(s.branchesPath(), data, 0o644); err != nil {
+	if _, err := s.writeFile(s.branchesPath(), data, 0o644); err != nil {
 		return fmt.Errorf("writing branches file: %w", err)
 	}
-	return nil
+	return s.registerSuite()
 }
 
 // EnsureBranch adds branch to the branch list if it is not already present.
@@ -142,31 +381,76 @@ func (s *Storage) WriteBranches(branches []string) error {
 // Semver tags (e.g. "v1.0.0") are never added individually. Instead the
 // virtual "releases" branch is registered so that all tag data is aggregated
 // under a single entry in the selector.
+//
+// The list is rewritten even if the branch was present, so that a
+// hand-edited branches.json with duplicates is cleaned up.
 func (s *Storage) EnsureBranch(branch string) (bool, error) {
-	// For semver tags, register the virtual "releases" branch instead.
-	nameToRegister := branch
-	if IsSemanticVersionTag(branch) {
-		nameToRegister = ReleasesVirtualBranch
-	}
+	nameToRegister := registeredName(branch)
 
 	branches, err := s.ReadBranches()
 	if err != nil {
 		return false, err
 	}
 
+	added := !slices.Contains(branches, nameToRegister)
+	if added {
+		branches = append(branches, nameToRegister)
+	}
+
+	if err := s.WriteBranches(branches); err != nil {
+		return false, err
+	}
+	return added, nil
+}
+
+// ensureBranches registers every branch in names with a single
+// read-modify-write of branches.json. Semver tags register the virtual
+// "releases" branch, as in EnsureBranch.
+func (s *Storage) ensureBranches(names []string) error {
+	branches, err := s.ReadBranches()
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]struct{}, len(branches))
 	for _, b := range branches {
-		if b == nameToRegister {
-			return false, nil
+		known[b] = struct{}{}
+	}
+
+	for _, name := range names {
+		name = registeredName(name)
+		if _, ok := known[name]; ok {
+			continue
 		}
+		known[name] = struct{}{}
+		branches = append(branches, name)
 	}
+	return s.WriteBranches(branches)
+}
 
-	branches = append(branches, nameToRegister)
-	sortBranches(branches)
+// registeredName returns the name under which branch appears in
+// branches.json: semver tags are aggregated under the "releases" branch.
+func registeredName(branch string) string {
+	if IsSemanticVersionTag(branch) {
+		return ReleasesVirtualBranch
+	}
+	return branch
+}
 
-	if err := s.WriteBranches(branches); err != nil {
-		return false, err
+// normalizeBranches returns branches without duplicates and without empty
+// names, sorted as by sortBranches. The input is not modified.
+func normalizeBranches(branches []string) []string {
+	out := make([]string, 0, len(branches))
+	seen := make(map[string]struct{}, len(branches))
+	for _, b := range branches {
+		if _, ok := seen[b]; ok || b == "" {
+			continue
+		}
+		seen[b] = struct{}{}
+		out = append(out, b)
 	}
-	return true, nil
+	sortBranches(out)
+	return out
 }
 
 // sortBranches sorts the branch list alphabetically but always keeps
@@ -189,32 +473,78 @@ func sortBranches(branches []string) {
 
 // ReadBranchData reads the benchmark entries for a branch.
 // If the file does not exist an empty slice is returned.
+// The format is told by the file's extension.
 func (s *Storage) ReadBranchData(branch string) (model.BranchData, error) {
-	data, err := os.ReadFile(s.branchDataPath(branch))
+	entries, _, err := s.readBranchData(branch)
+	return entries, err
+}
+
+// readBranchData implements ReadBranchData and also returns the path of the
+// file read ("" if there is none).
+func (s *Storage) readBranchData(branch string) (model.BranchData, string, error) {
+	data, path, err := s.readBranchFile(branch)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			return nil, nil
+			return nil, "", nil
 		}
-		return nil, fmt.Errorf("reading branch data for %q: %w", branch, err)
+		return nil, "", fmt.Errorf("reading branch data for %q: %w", branch, err)
 	}
 
 	var entries model.BranchData
-	if err := json.Unmarshal(data, &entries); err != nil {
-		return nil, fmt.Errorf("decoding branch data for %q: %w", branch, err)
+	if strings.HasSuffix(path, ndjsonSuffix) {
+		entries, err = decodeNDJSON(data)
+	} else {
+		entries, err = decodeBranchData(data)
 	}
-	return entries, nil
+	if err != nil {
+		return nil, "", fmt.Errorf("decoding branch data for %q: %w", branch, err)
+	}
+	return entries, path, nil
 }
 
-// WriteBranchData writes benchmark entries for a branch to disk.
+// WriteBranchData writes benchmark entries for a branch to disk, together
+// with the files derived from them (see writeDerived).
 func (s *Storage) WriteBranchData(branch string, entries model.BranchData) error {
-	data, err := json.MarshalIndent(entries, "", "  ")
+	if s.ndjson {
+		return s.writeNDJSONBranchData(branch, entries)
+	}
+	data, err := s.encodeBranchData(entries)
 	if err != nil {
 		return fmt.Errorf("encoding branch data: %w", err)
 	}
-	if err := os.WriteFile(s.branchDataPath(branch), data, 0o644); err != nil {
+	path := s.branchDataPath(branch)
+	for _, p := range []string{s.ndjsonPath(branch), s.ndjsonPath(branch) + ".br"} {
+		if err := removeIfExists(p); err != nil {
+			return fmt.Errorf("removing newline-delimited branch data for %q: %w", branch, err)
+		}
+	}
+	if s.gzip {
+		if data, err = gzipBytes(data); err != nil {
+			return fmt.Errorf("compressing branch data: %w", err)
+		}
+		// The plain file, if any, is migrated to the compressed one. Its
+		// brotli copy goes too, or serve would keep preferring it.
+		for _, p := range []string{path, path + ".br"} {
+			if err := removeIfExists(p); err != nil {
+				return fmt.Errorf("removing uncompressed branch data for %q: %w", branch, err)
+			}
+		}
+		path += gzipSuffix
+	} else {
+		for _, p := range []string{path + gzipSuffix, path + gzipSuffix + ".br"} {
+			if err := removeIfExists(p); err != nil {
+				return fmt.Errorf("removing compressed branch data for %q: %w", branch, err)
+			}
+		}
+	}
+	changed, err := s.writeFile(path, data, 0o644)
+	if err != nil {
 		return fmt.Errorf("writing branch data for %q: %w", branch, err)
 	}
-	return nil
+	if err := s.syncBrotli(path, data, changed); err != nil {
+		return fmt.Errorf("writing compressed branch data for %q: %w", branch, err)
+	}
+	return s.writeDerived(branch, entries)
 }
 
 // AppendEntry adds a new benchmark entry for the given branch, persists it,
@@ -230,38 +560,106 @@ func (s *Storage) AppendEntry(branch string, entry model.BenchmarkEntry, maxItem
 // read-modify-write cycle. This is more efficient than calling AppendEntry in a
 // loop when processing multiple output files (e.g. from a matrix build).
 //
-// Entries are keyed by (commit SHA, CPU model, CGO status). If a new entry
-// has the same key as an existing one, the old entry is replaced. After
-// merging, entries are sorted by commit date.
+// Entries are keyed by the dimensions of the Storage's KeyConfig (by
+// default the commit SHA, every run parameter and the tags; see
+// WithKeyConfig). If a new entry has the same key as an existing one, the
+// old entry is replaced. After merging, entries are sorted by commit date.
 //
 // If maxItems > 0, the oldest entries are trimmed so that at most maxItems
 // entries remain per branch after all new entries have been appended.
 //
 // When branch is a semver tag, the entries are also merged into the combined
 // "releases" data file so that all tagged releases can be compared side by
-// side. The individual tag data file is still written for reference.
+// side (unless the tag is a pre-release and the Storage was created with
+// WithStableReleasesOnly). The individual tag data file is still written for
+// reference.
 func (s *Storage) AppendEntries(branch string, newEntries []model.BenchmarkEntry, maxItems int) error {
-	if len(newEntries) == 0 {
+	return s.AppendBranches(map[string][]model.BenchmarkEntry{branch: newEntries}, maxItems, 1)
+}
+
+// AppendBranches appends entries to several branches in one call, merging
+// the distinct data files with at most concurrency writers in parallel
+// (values below 1 are treated as 1).
+//
+// Each branch is handled as in AppendEntries. The files shared between
+// branches are not written concurrently: branches.json is updated once
+// before the data files are merged, all semver tags of the batch are merged
+// into the "releases" aggregate by a single writer, and release_tags.json is
+// updated once after all data files were written.
+func (s *Storage) AppendBranches(batches map[string][]model.BenchmarkEntry, maxItems int, concurrency int) error {
+	return s.appendBranches(context.Background(), batches, RetentionPolicy{MaxItems: maxItems}, concurrency)
+}
+
+// appendBranches implements AppendBranches. Data files not yet started when
+// ctx is done are skipped and ctx's error is returned.
+func (s *Storage) appendBranches(ctx context.Context, batches map[string][]model.BenchmarkEntry, policy RetentionPolicy, concurrency int) error {
+	// Group the work per data file so no two writers share a file.
+	files := make(map[string][]model.BenchmarkEntry)
+	var branches []string
+	for branch, entries := range batches {
+		if len(entries) == 0 {
+			continue
+		}
+		branches = append(branches, branch)
+		files[branch] = append(files[branch], entries...)
+		if s.aggregatesIntoReleases(branch) {
+			files[ReleasesVirtualBranch] = append(files[ReleasesVirtualBranch], entries...)
+		}
+	}
+	if len(branches) == 0 {
 		return nil
 	}
-
-	// Register the branch (or "releases" for semver tags) in the branch list.
-	if _, err := s.EnsureBranch(branch); err != nil {
-		return fmt.Errorf("ensuring branch %q: %w", branch, err)
+	sort.Strings(branches)
+
+	// Register all branches (or "releases" for semver tags) up front.
+	if err := s.ensureBranches(branches); err != nil {
+		return fmt.Errorf("ensuring branches %v: %w", branches, err)
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for file, entries := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := ctx.Err()
+			if err == nil {
+				err = s.mergeEntries(file, entries, policy)
+			}
+			if err != nil {
+				if file == ReleasesVirtualBranch {
+					err = fmt.Errorf("updating releases data: %w", err)
+				}
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
 	}
 
-	// Write to the individual branch/tag data file.
-	if err := s.mergeEntries(branch, newEntries, maxItems); err != nil {
+	// Record the tag→SHA mapping so the frontend can show version labels.
+	if err := ctx.Err(); err != nil {
 		return err
 	}
-
-	// For semver tags, also merge entries into the combined "releases" file
-	// and record the tag→SHA mapping so the frontend can show version labels.
-	if IsSemanticVersionTag(branch) {
-		if err := s.mergeEntries(ReleasesVirtualBranch, newEntries, maxItems); err != nil {
-			return fmt.Errorf("updating releases data: %w", err)
+	for _, branch := range branches {
+		if !s.aggregatesIntoReleases(branch) {
+			continue
 		}
-		if err := s.recordReleaseTags(branch, newEntries); err != nil {
+		if err := s.recordReleaseTags(branch, batches[branch]); err != nil {
 			return fmt.Errorf("updating release tags map: %w", err)
 		}
 	}
@@ -271,24 +669,40 @@ func (s *Storage) AppendEntries(branch string, newEntries []model.BenchmarkEntry
 
 // mergeEntries performs the actual read-modify-write merge of newEntries into
 // the data file for the given branch name. It handles deduplication, sorting,
-// and trimming.
-func (s *Storage) mergeEntries(branch string, newEntries []model.BenchmarkEntry, maxItems int) error {
+// and trimming by policy.
+func (s *Storage) mergeEntries(branch string, newEntries []model.BenchmarkEntry, policy RetentionPolicy) error {
 	// Read existing data.
-	entries, err := s.ReadBranchData(branch)
+	entries, path, err := s.readBranchData(branch)
 	if err != nil {
 		return err
 	}
 
+	// New entries that only extend a newline-delimited file are appended
+	// to it instead of rewriting it, unless the policy trims the result.
+	if s.ndjson && !s.brotli && strings.HasSuffix(path, ndjsonSuffix) && s.appendsInOrder(entries, newEntries) {
+		merged := append(entries, newEntries...)
+		if len(policy.apply(slices.Clone(merged), s.clock())) == len(merged) {
+			if err := appendNDJSON(path, newEntries); err != nil {
+				return fmt.Errorf("appending branch data for %q: %w", branch, err)
+			}
+			// Appends only happen without brotli; drop a stale copy.
+			if err := removeIfExists(path + ".br"); err != nil {
+				return fmt.Errorf("removing compressed branch data for %q: %w", branch, err)
+			}
+			return s.writeDerived(branch, merged)
+		}
+	}
+
 	// Build a set of new entry keys for fast lookup.
 	newKeys := make(map[model.EntryKeyValue]struct{}, len(newEntries))
 	for _, e := range newEntries {
-		newKeys[e.EntryKey()] = struct{}{}
+		newKeys[s.entryKey(e)] = struct{}{}
 	}
 
 	// Remove existing entries whose key matches a new entry (replace semantics).
 	filtered := entries[:0]
 	for _, e := range entries {
-		if _, dup := newKeys[e.EntryKey()]; !dup {
+		if _, dup := newKeys[s.entryKey(e)]; !dup {
 			filtered = append(filtered, e)
 		}
 	}
@@ -299,10 +713,8 @@ func (s *Storage) mergeEntries(branch string, newEntries []model.BenchmarkEntry,
 	// Sort by commit date so the timeline is always chronological.
 	sortByCommitDate(filtered)
 
-	// Trim old entries if maxItems is set.
-	if maxItems > 0 && len(filtered) > maxItems {
-		filtered = filtered[len(filtered)-maxItems:]
-	}
+	// Trim old entries by age, then by count.
+	filtered = policy.apply(filtered, s.clock())
 
 	return s.WriteBranchData(branch, filtered)
 }
@@ -337,7 +749,7 @@ func (s *Storage) writeReleaseTags(tags map[string]string) error {
 	if err != nil {
 		return fmt.Errorf("encoding release tags: %w", err)
 	}
-	if err := os.WriteFile(s.releaseTagsPath(), data, 0o644); err != nil {
+	if _, err := s.writeFile(s.releaseTagsPath(), data, 0o644); err != nil {
 		return fmt.Errorf("writing release tags: %w", err)
 	}
 	return nil
@@ -364,10 +776,14 @@ func (s *Storage) recordReleaseTags(tag string, entries []model.BenchmarkEntry)
 // --------------------------------------------------------------------------
 
 // Metadata holds repository-level information displayed by the frontend.
+//
+// BranchAliases maps real branch names to the names shown in the branch
+// selector. They are display-only: data files stay keyed by the real name.
 type Metadata struct {
-	RepoURL    string `json:"repoUrl"`
-	LastUpdate int64  `json:"lastUpdate"`
-	GoModule   string `json:"goModule,omitempty"`
+	RepoURL       string            `json:"repoUrl"`
+	LastUpdate    int64             `json:"lastUpdate"`
+	GoModule      string            `json:"goModule,omitempty"`
+	BranchAliases map[string]string `json:"branchAliases,omitempty"`
 }
 
 // metadataPath returns the path to metadata.json.
@@ -392,18 +808,32 @@ func (s *Storage) ReadMetadata() (Metadata, error) {
 }
 
 // WriteMetadata writes (or updates) metadata.json with the given repo URL
-// and sets LastUpdate to the current time.
-func (s *Storage) WriteMetadata(repoURL string, goModule string) error {
+// and sets LastUpdate to the current time of the storage clock. An empty
+// repoURL or goModule keeps the stored value, and branch aliases are merged
+// into the ones already stored, so each store run only needs to pass what
+// it knows about.
+func (s *Storage) WriteMetadata(repoURL string, goModule string, aliases map[string]string) error {
+	existing, err := s.ReadMetadata()
+	if err != nil {
+		return err
+	}
 	m := Metadata{
-		RepoURL:    repoURL,
-		LastUpdate: time.Now().UnixMilli(),
-		GoModule:   goModule,
+		RepoURL:       cmp.Or(repoURL, existing.RepoURL),
+		LastUpdate:    s.clock().UnixMilli(),
+		GoModule:      cmp.Or(goModule, existing.GoModule),
+		BranchAliases: existing.BranchAliases,
+	}
+	if len(aliases) > 0 && m.BranchAliases == nil {
+		m.BranchAliases = make(map[string]string, len(aliases))
+	}
+	for branch, alias := range aliases {
+		m.BranchAliases[branch] = alias
 	}
 	data, err := json.MarshalIndent(m, "", "  ")
 	if err != nil {
 		return fmt.Errorf("encoding metadata: %w", err)
 	}
-	if err := os.WriteFile(s.metadataPath(), data, 0o644); err != nil {
+	if _, err := s.writeFile(s.metadataPath(), data, 0o644); err != nil {
 		return fmt.Errorf("writing metadata: %w", err)
 	}
 	return nil
@@ -3,6 +3,7 @@ package storage
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/royalcat/go-continuous-benchmarking/internal/model"
 )
@@ -93,6 +94,52 @@ func benchmarkAppendToExisting(b *testing.B, existingEntries int, benchesPerEntr
 	}
 }
 
+// BenchmarkAppendEntry_Latest1000 and its NDJSON variant append the newest
+// commit to a branch of 1000 entries, the common case of a store run: the
+// JSON array is rewritten, the newline-delimited file only appended to.
+func BenchmarkAppendEntry_Latest1000(b *testing.B) {
+	benchmarkAppendLatest(b, 1000, 5)
+}
+
+func BenchmarkAppendEntry_Latest1000_NDJSON(b *testing.B) {
+	benchmarkAppendLatest(b, 1000, 5, WithNDJSON())
+}
+
+func benchmarkAppendLatest(b *testing.B, existingEntries int, benchesPerEntry int, opts ...Option) {
+	b.Helper()
+	s, err := New(b.TempDir(), opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	seed := datedEntries(existingEntries, benchesPerEntry)
+	entry := datedEntries(existingEntries+1, benchesPerEntry)[existingEntries]
+
+	b.ReportAllocs()
+	for b.Loop() {
+		b.StopTimer()
+		if err := s.WriteBranchData("main", seed); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+
+		if err := s.AppendEntry("main", entry, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// datedEntries returns n entries one hour apart in commit date order.
+func datedEntries(n int, benchesPerEntry int) model.BranchData {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := make(model.BranchData, 0, n)
+	for i := 0; i < n; i++ {
+		e := makeEntry(fmt.Sprintf("%040x", i), benchesPerEntry)
+		e.Commit.Date = start.Add(time.Duration(i) * time.Hour).Format(time.RFC3339)
+		data = append(data, e)
+	}
+	return data
+}
+
 func BenchmarkAppendEntry_WithMaxItems(b *testing.B) {
 	b.ReportAllocs()
 	for b.Loop() {
@@ -120,6 +167,10 @@ func BenchmarkReadBranchData_100(b *testing.B) {
 	benchmarkReadBranchData(b, 100, 5)
 }
 
+func BenchmarkReadBranchData_1000(b *testing.B) {
+	benchmarkReadBranchData(b, 1000, 5)
+}
+
 func benchmarkReadBranchData(b *testing.B, entries int, benchesPerEntry int) {
 	b.Helper()
 	dir := b.TempDir()
@@ -127,7 +178,9 @@ func benchmarkReadBranchData(b *testing.B, entries int, benchesPerEntry int) {
 	if err != nil {
 		b.Fatal(err)
 	}
-	seedStorage(b, s, "main", entries, benchesPerEntry)
+	if err := s.WriteBranchData("main", datedEntries(entries, benchesPerEntry)); err != nil {
+		b.Fatal(err)
+	}
 
 	b.ResetTimer()
 	b.ReportAllocs()
@@ -243,7 +296,7 @@ func BenchmarkWriteMetadata(b *testing.B) {
 	b.ResetTimer()
 	b.ReportAllocs()
 	for b.Loop() {
-		if err := s.WriteMetadata("https://github.com/test/repo", "github.com/test/repo"); err != nil {
+		if err := s.WriteMetadata("https://github.com/test/repo", "github.com/test/repo", nil); err != nil {
 			b.Fatal(err)
 		}
 	}
@@ -255,7 +308,7 @@ func BenchmarkReadMetadata(b *testing.B) {
 	if err != nil {
 		b.Fatal(err)
 	}
-	if err := s.WriteMetadata("https://github.com/test/repo", "github.com/test/repo"); err != nil {
+	if err := s.WriteMetadata("https://github.com/test/repo", "github.com/test/repo", nil); err != nil {
 		b.Fatal(err)
 	}
 
@@ -2,10 +2,14 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/royalcat/go-continuous-benchmarking/internal/model"
 )
@@ -22,7 +26,18 @@ func TestNew_CreatesDirectories(t *testing.T) {
 		t.Fatal("New() returned nil storage")
 	}
 
-	// Check data/ subdirectory was created
+	// Read-only use leaves the directory alone.
+	if _, err := s.ReadBranches(); err != nil {
+		t.Fatalf("ReadBranches() error: %v", err)
+	}
+	if _, err := os.Stat(baseDir); !os.IsNotExist(err) {
+		t.Fatalf("New() should not create the base directory, stat error: %v", err)
+	}
+
+	// The first write creates it with the data/ subdirectory.
+	if err := s.WriteBranches([]string{"main"}); err != nil {
+		t.Fatalf("WriteBranches() error: %v", err)
+	}
 	dataDir := filepath.Join(baseDir, "data")
 	info, err := os.Stat(dataDir)
 	if err != nil {
@@ -33,6 +48,65 @@ func TestNew_CreatesDirectories(t *testing.T) {
 	}
 }
 
+func TestNew_EmptyDirectoryWritesMarker(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := s.WriteBranches([]string{"main"}); err != nil {
+		t.Fatalf("WriteBranches() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, markerFileName)); err != nil {
+		t.Fatalf("marker file not written: %v", err)
+	}
+
+	// A second initialization of the now marked directory succeeds.
+	if _, err := New(dir); err != nil {
+		t.Fatalf("New() on marked directory error: %v", err)
+	}
+}
+
+func TestNew_ExistingStorageWithoutMarker(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "branches.json"), []byte(`["main"]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(dir); err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+}
+
+func TestNew_RefusesForeignDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(dir); !errors.Is(err, ErrForeignDirectory) {
+		t.Fatalf("expected ErrForeignDirectory, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data")); !os.IsNotExist(err) {
+		t.Error("refused directory should not be modified")
+	}
+
+	s, err := New(dir, WithForce())
+	if err != nil {
+		t.Fatalf("New(WithForce) error: %v", err)
+	}
+	if err := s.WriteBranches([]string{"main"}); err != nil {
+		t.Fatalf("WriteBranches() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, markerFileName)); err != nil {
+		t.Errorf("forced initialization should write the marker: %v", err)
+	}
+}
+
 func TestReadBranches_EmptyWhenNoFile(t *testing.T) {
 	dir := t.TempDir()
 	s, err := New(dir)
@@ -157,6 +231,40 @@ func TestEnsureBranch_SortedOrder(t *testing.T) {
 	}
 }
 
+func TestEnsureBranch_NormalizesDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	seeded := `["main", "main", "develop", "releases", "develop"]`
+	if err := os.WriteFile(s.branchesPath(), []byte(seeded), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	added, err := s.EnsureBranch("main")
+	if err != nil {
+		t.Fatalf("EnsureBranch() error: %v", err)
+	}
+	if added {
+		t.Error("EnsureBranch() reported an existing branch as added")
+	}
+
+	raw, err := os.ReadFile(s.branchesPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var onDisk []string
+	if err := json.Unmarshal(raw, &onDisk); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"releases", "develop", "main"}
+	if !slices.Equal(onDisk, want) {
+		t.Errorf("got %v, want %v", onDisk, want)
+	}
+}
+
 func TestEnsureBranch_ReleasesAlwaysFirst(t *testing.T) {
 	dir := t.TempDir()
 	s, err := New(dir)
@@ -479,14 +587,15 @@ func TestBranchDataPath_Sanitization(t *testing.T) {
 
 func TestWriteAndReadMetadata(t *testing.T) {
 	dir := t.TempDir()
-	s, err := New(dir)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, err := New(dir, WithClock(func() time.Time { return now }))
 	if err != nil {
 		t.Fatalf("New() error: %v", err)
 	}
 
 	repoURL := "https://github.com/test/repo"
 	goModule := "github.com/test/repo"
-	if err := s.WriteMetadata(repoURL, goModule); err != nil {
+	if err := s.WriteMetadata(repoURL, goModule, nil); err != nil {
 		t.Fatalf("WriteMetadata() error: %v", err)
 	}
 
@@ -500,8 +609,46 @@ func TestWriteAndReadMetadata(t *testing.T) {
 	if meta.GoModule != goModule {
 		t.Errorf("GoModule: got %q, want %q", meta.GoModule, goModule)
 	}
-	if meta.LastUpdate <= 0 {
-		t.Errorf("LastUpdate should be positive, got %d", meta.LastUpdate)
+	if meta.LastUpdate != now.UnixMilli() {
+		t.Errorf("LastUpdate: got %d, want %d", meta.LastUpdate, now.UnixMilli())
+	}
+}
+
+func TestWriteMetadata_BranchAliases(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	if err := s.WriteMetadata("https://github.com/owner/repo", "github.com/owner/repo", map[string]string{"team/proj/main": "main", "dev": "develop"}); err != nil {
+		t.Fatalf("WriteMetadata() error: %v", err)
+	}
+	// A later run only passes some aliases; the others are kept, and so
+	// are the repository fields it leaves empty.
+	if err := s.WriteMetadata("", "", map[string]string{"dev": "Development"}); err != nil {
+		t.Fatalf("WriteMetadata() error: %v", err)
+	}
+
+	meta, err := s.ReadMetadata()
+	if err != nil {
+		t.Fatalf("ReadMetadata() error: %v", err)
+	}
+	if meta.RepoURL != "https://github.com/owner/repo" || meta.GoModule != "github.com/owner/repo" {
+		t.Errorf("got repoUrl %q and goModule %q, want the stored values kept", meta.RepoURL, meta.GoModule)
+	}
+	want := map[string]string{"team/proj/main": "main", "dev": "Development"}
+	if len(meta.BranchAliases) != len(want) {
+		t.Fatalf("BranchAliases: got %v, want %v", meta.BranchAliases, want)
+	}
+	for k, v := range want {
+		if meta.BranchAliases[k] != v {
+			t.Errorf("BranchAliases[%q]: got %q, want %q", k, meta.BranchAliases[k], v)
+		}
+	}
+
+	// Aliasing is display-only: the data file keeps the real branch name.
+	if got, want := BranchFileName("team/proj/main"), "team_proj_main.json"; got != want {
+		t.Errorf("BranchFileName: got %q, want %q", got, want)
 	}
 }
 
@@ -560,6 +707,7 @@ func TestBranchData_JSONRoundTrip(t *testing.T) {
 				GoVersion: "go1.22.0",
 				CGO:       true,
 			},
+			ProfileURL: "https://example.com/runs/deadbeef/bench-linux-amd64-go1.22.0-cgo1/cpu.pprof",
 			Benchmarks: []model.BenchmarkResult{
 				{Name: "BenchmarkA", Value: 1234.567, Unit: "ns/op", Extra: "100 times\n4 procs"},
 				{Name: "BenchmarkA - B/op", Value: 256, Unit: "B/op", Extra: "100 times\n4 procs"},
@@ -596,6 +744,9 @@ func TestBranchData_JSONRoundTrip(t *testing.T) {
 	if got.Params != want.Params {
 		t.Errorf("Params: got %+v, want %+v", got.Params, want.Params)
 	}
+	if got.ProfileURL != want.ProfileURL {
+		t.Errorf("ProfileURL: got %q, want %q", got.ProfileURL, want.ProfileURL)
+	}
 	if len(got.Benchmarks) != len(want.Benchmarks) {
 		t.Fatalf("Benchmarks length: got %d, want %d", len(got.Benchmarks), len(want.Benchmarks))
 	}
@@ -1190,6 +1341,44 @@ func TestAppendEntry_SortedInsertionOutOfOrder(t *testing.T) {
 	}
 }
 
+func TestAppendEntry_SameDateOrderedBySHA(t *testing.T) {
+	params := model.RunParams{CPU: "cpu1", GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.22.0", CGO: true}
+
+	mk := func(sha string) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha, Date: "2024-01-01T00:00:00Z"},
+			Date:       1704067200000,
+			Params:     params,
+			Benchmarks: []model.BenchmarkResult{{Name: "B", Value: 1, Unit: "ns/op"}},
+		}
+	}
+
+	// Append the same pair in both orders; the stored order must not depend
+	// on the insertion order.
+	for _, order := range [][]string{{"bbb", "aaa"}, {"aaa", "bbb"}} {
+		s, err := New(t.TempDir())
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		for _, sha := range order {
+			if err := s.AppendEntry("main", mk(sha), 0); err != nil {
+				t.Fatalf("AppendEntry(%s) error: %v", sha, err)
+			}
+		}
+
+		data, err := s.ReadBranchData("main")
+		if err != nil {
+			t.Fatalf("ReadBranchData() error: %v", err)
+		}
+		if len(data) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(data))
+		}
+		if data[0].Commit.SHA != "aaa" || data[1].Commit.SHA != "bbb" {
+			t.Errorf("insert order %v: got [%s %s], want [aaa bbb]", order, data[0].Commit.SHA, data[1].Commit.SHA)
+		}
+	}
+}
+
 func TestAppendEntry_MaxItemsAfterReplace(t *testing.T) {
 	dir := t.TempDir()
 	s, err := New(dir)
@@ -1306,6 +1495,191 @@ func TestEntryKey(t *testing.T) {
 	if e1.EntryKey() == e8.EntryKey() {
 		t.Error("different GoVersion should produce different key")
 	}
+	// Different input corpus
+	e11 := e1
+	e11.Params.DatasetHash = "sha256:feed"
+	if e1.EntryKey() == e11.EntryKey() {
+		t.Error("different DatasetHash should produce different key")
+	}
+
+	// Different experiment tags
+	e9 := e1
+	e9.Tags = map[string]string{"alloc": "arena"}
+	e10 := e1
+	e10.Tags = map[string]string{"alloc": "arena"}
+	if e1.EntryKey() == e9.EntryKey() {
+		t.Error("different tags should produce different key")
+	}
+	if e9.EntryKey() != e10.EntryKey() {
+		t.Error("equal tags should produce same key")
+	}
+}
+
+func TestAppendEntries_DifferentDatasetHashDoNotDedupe(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	params := model.RunParams{CPU: "Intel Xeon", GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.22.0", DatasetHash: "v1"}
+	old := model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: "abc123"},
+		Date:       1000,
+		Params:     params,
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkDecode", Value: 100, Unit: "ns/op"}},
+	}
+	bigger := old
+	bigger.Params.DatasetHash = "v2"
+	bigger.Benchmarks = []model.BenchmarkResult{{Name: "BenchmarkDecode", Value: 400, Unit: "ns/op"}}
+
+	if err := s.AppendEntries("main", []model.BenchmarkEntry{old, bigger}, 0); err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.ReadBranchData("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 {
+		t.Fatalf("expected 2 entries for runs on different datasets, got %d", len(data))
+	}
+	key := model.SeriesKey{Name: "BenchmarkDecode", Unit: "ns/op"}
+	if h := data.History(model.EntryKeyValue{Params: params}, key); len(h) != 1 || h[0].Value != 100 {
+		t.Errorf("history for dataset v1: got %+v, want only the v1 point", h)
+	}
+}
+
+func TestAppendEntries_DifferentTagsDoNotDedupe(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	params := model.RunParams{CPU: "Intel Xeon", GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.22.0"}
+	base := model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: "abc123"},
+		Date:       1000,
+		Params:     params,
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 100, Unit: "ns/op"}},
+	}
+	arena := base
+	arena.Tags = map[string]string{"alloc": "arena"}
+	arena.Benchmarks = []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 80, Unit: "ns/op"}}
+
+	if err := s.AppendEntries("main", []model.BenchmarkEntry{base}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AppendEntries("main", []model.BenchmarkEntry{arena}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := s.ReadBranchData("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 {
+		t.Fatalf("expected 2 entries for differently tagged runs, got %d", len(data))
+	}
+
+	// Re-storing the tagged run replaces only the tagged entry.
+	arena.Benchmarks = []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 75, Unit: "ns/op"}}
+	if err := s.AppendEntries("main", []model.BenchmarkEntry{arena}, 0); err != nil {
+		t.Fatal(err)
+	}
+	data, err = s.ReadBranchData("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 {
+		t.Fatalf("expected 2 entries after re-storing tagged run, got %d", len(data))
+	}
+}
+
+func TestAppendEntries_EnvironmentDedup(t *testing.T) {
+	params := model.RunParams{CPU: "Intel Xeon", GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.22.0"}
+	small := model.BenchmarkEntry{
+		Commit:      model.Commit{SHA: "abc123"},
+		Date:        1000,
+		Params:      params,
+		Environment: map[string]string{"INSTANCE_TYPE": "c5.large", "REGION": "eu-west-1"},
+		Benchmarks:  []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 100, Unit: "ns/op"}},
+	}
+	large := small
+	large.Environment = map[string]string{"INSTANCE_TYPE": "c5.4xlarge", "REGION": "eu-west-1"}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want int
+	}{
+		{"environment ignored by default", nil, 1},
+		{"dedup-env keeps both", []Option{WithEnvDedup()}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(t.TempDir(), tt.opts...)
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			for _, e := range []model.BenchmarkEntry{small, large} {
+				if err := s.AppendEntries("main", []model.BenchmarkEntry{e}, 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			data, err := s.ReadBranchData("main")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) != tt.want {
+				t.Fatalf("expected %d entries, got %d", tt.want, len(data))
+			}
+			last := data[len(data)-1]
+			if !reflect.DeepEqual(last.Environment, large.Environment) {
+				t.Errorf("environment did not round-trip: got %v, want %v", last.Environment, large.Environment)
+			}
+		})
+	}
+}
+
+func TestAppendEntries_KeyConfig(t *testing.T) {
+	linux := model.RunParams{CPU: "Intel Xeon", GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.22.0"}
+	darwin := model.RunParams{CPU: "Apple M2", GOOS: "darwin", GOARCH: "arm64", GoVersion: "go1.22.0"}
+	entries := []model.BenchmarkEntry{
+		{Commit: model.Commit{SHA: "abc"}, Date: 1000, Params: linux, Environment: map[string]string{"REGION": "eu"}},
+		{Commit: model.Commit{SHA: "abc"}, Date: 1000, Params: darwin},
+		{Commit: model.Commit{SHA: "abc"}, Date: 1000, Params: linux, Environment: map[string]string{"REGION": "us"}},
+	}
+
+	tests := []struct {
+		name string
+		cfg  model.KeyConfig
+		want int
+	}{
+		{"sha only", model.KeyConfig{SHA: true}, 1},
+		{"default", model.DefaultKeyConfig, 2},
+		{"maximal", model.KeyConfig{SHA: true, CPU: true, GOOS: true, GOARCH: true, GoVersion: true, CGO: true, Tags: true, Env: true}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(t.TempDir(), WithKeyConfig(tt.cfg))
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			for _, e := range entries {
+				if err := s.AppendEntries("main", []model.BenchmarkEntry{e}, 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+			data, err := s.ReadBranchData("main")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) != tt.want {
+				t.Errorf("expected %d entries, got %d", tt.want, len(data))
+			}
+		})
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -1348,6 +1722,79 @@ func TestIsSemanticVersionTag(t *testing.T) {
 // Releases virtual branch tests
 // ---------------------------------------------------------------------------
 
+func TestIsPreRelease(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"v1.0.0", false},
+		{"1.2.3", false},
+		{"v1.0.0+build.5", false},
+		{"v1.0.0-rc.1", true},
+		{"2.0.0-beta", true},
+		{"v0.1.0-alpha.1+build", true},
+		{"main", false},
+		{"release-1.0.0-rc", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPreRelease(tt.name); got != tt.want {
+			t.Errorf("IsPreRelease(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAppendEntries_StableReleasesOnly(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir, WithStableReleasesOnly())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	params := model.RunParams{CPU: "TestCPU", GOOS: "linux", GOARCH: "amd64"}
+	rc := model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: "rc1", Date: "2024-01-01T00:00:00Z"},
+		Params:     params,
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 100, Unit: "ns/op"}},
+	}
+	stable := model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: "final", Date: "2024-02-01T00:00:00Z"},
+		Params:     params,
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 90, Unit: "ns/op"}},
+	}
+
+	if err := s.AppendEntry("v1.0.0-rc.1", rc, 0); err != nil {
+		t.Fatalf("AppendEntry(v1.0.0-rc.1) error: %v", err)
+	}
+	if err := s.AppendEntry("v1.0.0", stable, 0); err != nil {
+		t.Fatalf("AppendEntry(v1.0.0) error: %v", err)
+	}
+
+	// Both tags keep their own data file.
+	for _, tag := range []string{"v1.0.0-rc.1", "v1.0.0"} {
+		data, err := s.ReadBranchData(tag)
+		if err != nil {
+			t.Fatalf("ReadBranchData(%s) error: %v", tag, err)
+		}
+		if len(data) != 1 {
+			t.Errorf("%s: got %d entries, want 1", tag, len(data))
+		}
+	}
+
+	// Only the stable release lands in the aggregate.
+	relData, err := s.ReadBranchData(ReleasesVirtualBranch)
+	if err != nil {
+		t.Fatalf("ReadBranchData(releases) error: %v", err)
+	}
+	if len(relData) != 1 || relData[0].Commit.SHA != "final" {
+		t.Errorf("releases = %+v, want only commit final", relData)
+	}
+
+	if err := s.VerifyReleases(); err != nil {
+		t.Errorf("VerifyReleases() error: %v", err)
+	}
+}
+
 func TestAppendEntries_SemverTag_CreatesReleasesData(t *testing.T) {
 	dir := t.TempDir()
 	s, err := New(dir)
@@ -1713,3 +2160,73 @@ func TestAppendEntries_SemverTag_MaxItemsApplied(t *testing.T) {
 			relData[0].Commit.SHA, relData[1].Commit.SHA)
 	}
 }
+
+func TestAppendBranches_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	params := model.RunParams{CPU: "TestCPU", GOOS: "linux", GOARCH: "amd64"}
+	batches := make(map[string][]model.BenchmarkEntry)
+	var want []string
+	for i := range 8 {
+		branch := fmt.Sprintf("feature/b%02d", i)
+		want = append(want, branch)
+		batches[branch] = []model.BenchmarkEntry{{
+			Commit:     model.Commit{SHA: fmt.Sprintf("sha%02d", i), Date: "2024-01-01T00:00:00Z"},
+			Date:       1704067200000,
+			Params:     params,
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: float64(i), Unit: "ns/op"}},
+		}}
+	}
+	// Two semver tags share the releases aggregate and the tag map.
+	for i, tag := range []string{"v1.0.0", "v1.1.0"} {
+		batches[tag] = []model.BenchmarkEntry{{
+			Commit:     model.Commit{SHA: "tag" + tag, Date: fmt.Sprintf("2024-0%d-01T00:00:00Z", i+2)},
+			Date:       int64(i + 2),
+			Params:     params,
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 1, Unit: "ns/op"}},
+		}}
+	}
+
+	if err := s.AppendBranches(batches, 0, 4); err != nil {
+		t.Fatalf("AppendBranches() error: %v", err)
+	}
+
+	for i, branch := range want {
+		data, err := s.ReadBranchData(branch)
+		if err != nil {
+			t.Fatalf("ReadBranchData(%s) error: %v", branch, err)
+		}
+		if len(data) != 1 || data[0].Benchmarks[0].Value != float64(i) {
+			t.Errorf("%s: unexpected data %+v", branch, data)
+		}
+	}
+
+	relData, err := s.ReadBranchData(ReleasesVirtualBranch)
+	if err != nil {
+		t.Fatalf("ReadBranchData(releases) error: %v", err)
+	}
+	if len(relData) != 2 {
+		t.Errorf("releases: got %d entries, want 2", len(relData))
+	}
+
+	tags, err := s.readReleaseTags()
+	if err != nil {
+		t.Fatalf("readReleaseTags() error: %v", err)
+	}
+	if tags["tagv1.0.0"] != "v1.0.0" || tags["tagv1.1.0"] != "v1.1.0" {
+		t.Errorf("release tags = %v", tags)
+	}
+
+	branches, err := s.ReadBranches()
+	if err != nil {
+		t.Fatalf("ReadBranches() error: %v", err)
+	}
+	wantBranches := append([]string{ReleasesVirtualBranch}, want...)
+	if !reflect.DeepEqual(branches, wantBranches) {
+		t.Errorf("branches = %v, want %v", branches, wantBranches)
+	}
+}
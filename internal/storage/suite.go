@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+// suitesFileName lists the named suites of a storage directory, next to
+// the default suite's branches.json.
+const suitesFileName = "suites.json"
+
+// suiteBranchesDirName holds the branch list of every named suite as
+// branches/<suite>.json, outside data/ so that no branch file can collide
+// with it.
+const suiteBranchesDirName = "branches"
+
+// suiteNameRe matches the names accepted by WithSuite.
+var suiteNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ValidSuiteName reports whether name can name a suite: ASCII letters,
+// digits, '-' and '_', starting with a letter or digit. Such names are safe
+// as a directory name and in a URL path.
+func ValidSuiteName(name string) bool {
+	return suiteNameRe.MatchString(name)
+}
+
+// WithSuite namespaces the storage under the benchmark suite name, so that
+// independent suites of one repository get separate timelines even on the
+// same branch: the data of every branch moves to data/<name>/ and the branch
+// list to branches/<name>.json. metadata.json and manifest.json stay
+// shared, and the suite is listed in suites.json when its branch list is
+// written. The empty name selects the default suite and its flat layout. New
+// rejects names for which ValidSuiteName is false.
+func WithSuite(name string) Option {
+	return func(s *Storage) {
+		s.suite = name
+	}
+}
+
+// dataDir returns the directory of the suite's data files: data/ for the
+// default suite and data/<suite>/ otherwise.
+func (s *Storage) dataDir() string {
+	if s.suite == "" {
+		return filepath.Join(s.baseDir, "data")
+	}
+	return filepath.Join(s.baseDir, "data", s.suite)
+}
+
+// suitesPath returns the path to suites.json.
+func (s *Storage) suitesPath() string {
+	return filepath.Join(s.baseDir, suitesFileName)
+}
+
+// ReadSuites returns the named suites listed in suites.json, sorted. The
+// default suite is not listed. If the file does not exist an empty slice is
+// returned.
+func (s *Storage) ReadSuites() ([]string, error) {
+	data, err := os.ReadFile(s.suitesPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading suites file: %w", err)
+	}
+
+	var suites []string
+	if err := json.Unmarshal(data, &suites); err != nil {
+		return nil, fmt.Errorf("decoding suites file: %w", err)
+	}
+	return suites, nil
+}
+
+// registerSuite adds the storage's suite to suites.json. It does nothing
+// for the default suite.
+func (s *Storage) registerSuite() error {
+	if s.suite == "" {
+		return nil
+	}
+	suites, err := s.ReadSuites()
+	if err != nil {
+		return err
+	}
+	if slices.Contains(suites, s.suite) {
+		return nil
+	}
+	suites = append(suites, s.suite)
+	slices.Sort(suites)
+
+	data, err := json.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding suites: %w", err)
+	}
+	if _, err := s.writeFile(s.suitesPath(), data, 0o644); err != nil {
+		return fmt.Errorf("writing suites file: %w", err)
+	}
+	return nil
+}
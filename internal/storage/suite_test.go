@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestWithSuite(t *testing.T) {
+	dir := t.TempDir()
+	entry := func(sha string) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha, Date: "2024-01-01T00:00:00Z"},
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 10, Unit: "ns/op"}},
+		}
+	}
+
+	flat, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	db, err := New(dir, WithSuite("db"))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	api, err := New(dir, WithSuite("api-v2"))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	for name, s := range map[string]*Storage{"flat": flat, "db": db, "api": api} {
+		if err := s.AppendEntry("main", entry(name), 0); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if err := db.AppendEntry("feature", entry("db2"), 0); err != nil {
+		t.Fatal(err)
+	}
+	// A branch named like the index must not overwrite it.
+	if err := db.AppendEntry("branches", entry("db3"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every suite keeps its own timeline and branch list.
+	for name, tt := range map[string]struct {
+		s        *Storage
+		branches []string
+	}{
+		"flat": {flat, []string{"main"}},
+		"db":   {db, []string{"branches", "feature", "main"}},
+		"api":  {api, []string{"main"}},
+	} {
+		data, err := tt.s.ReadBranchData("main")
+		if err != nil || len(data) != 1 || data[0].Commit.SHA != name {
+			t.Errorf("%s: got %+v, %v; want only its own entry", name, data, err)
+		}
+		branches, err := tt.s.ReadBranches()
+		if err != nil || !reflect.DeepEqual(branches, tt.branches) {
+			t.Errorf("%s: branches: got %v, %v; want %v", name, branches, err, tt.branches)
+		}
+	}
+	for _, path := range []string{"branches.json", "data/main.json", "branches/db.json", "data/db/main.json", "data/db/feature.json", "data/api-v2/main.json"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path))); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+
+	suites, err := flat.ReadSuites()
+	if err != nil || !reflect.DeepEqual(suites, []string{"api-v2", "db"}) {
+		t.Errorf("suites: got %v, %v; want [api-v2 db]", suites, err)
+	}
+
+	// The shared manifest covers all suites, whichever writes it.
+	m, err := db.BuildManifest()
+	if err != nil {
+		t.Fatalf("BuildManifest() error: %v", err)
+	}
+	listed := make(map[string]bool)
+	for _, f := range m.Files {
+		listed[f.Path] = true
+	}
+	for _, path := range []string{"branches.json", "suites.json", "data/main.json", "branches/db.json", "data/api-v2/main.json"} {
+		if !listed[path] {
+			t.Errorf("manifest misses %s", path)
+		}
+	}
+}
+
+func TestWithSuite_InvalidName(t *testing.T) {
+	for _, name := range []string{"../x", "a/b", ".hidden", "-x", "with space"} {
+		if _, err := New(t.TempDir(), WithSuite(name)); err == nil {
+			t.Errorf("New(WithSuite(%q)): want an error", name)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// summariesDirName holds the branch summaries; see derivedPath.
+const summariesDirName = "summaries"
+
+// BranchSummary is the content of summaries/<branch>.json: what a branch
+// list needs to show without loading the branch data itself.
+type BranchSummary struct {
+	Entries int `json:"entries"`
+	// LatestSHA and LatestDate identify the newest entry by commit date.
+	LatestSHA  string `json:"latestSha,omitempty"`
+	LatestDate string `json:"latestDate,omitempty"`
+	// Benchmarks lists the distinct benchmark names, qualified by package
+	// (see model.BenchmarkResult.QualifiedName) and without the " - unit"
+	// suffix of secondary metrics, in sorted order.
+	Benchmarks []string `json:"benchmarks"`
+	// Params lists the distinct run parameter combinations, sorted.
+	Params []model.RunParams `json:"params"`
+}
+
+// Summarize computes the summary of data, which must be sorted by commit
+// date as stored.
+func Summarize(data model.BranchData) BranchSummary {
+	sum := BranchSummary{Entries: len(data), Benchmarks: []string{}, Params: []model.RunParams{}}
+	if len(data) > 0 {
+		latest := data[len(data)-1]
+		sum.LatestSHA = latest.Commit.SHA
+		sum.LatestDate = latest.Commit.Date
+	}
+
+	names := make(map[string]struct{})
+	params := make(map[model.RunParams]struct{})
+	for _, e := range data {
+		params[e.Params] = struct{}{}
+		for _, r := range e.Benchmarks {
+			names[strings.TrimSuffix(r.QualifiedName(), " - "+r.Unit)] = struct{}{}
+		}
+	}
+	for name := range names {
+		sum.Benchmarks = append(sum.Benchmarks, name)
+	}
+	slices.Sort(sum.Benchmarks)
+	for p := range params {
+		sum.Params = append(sum.Params, p)
+	}
+	slices.SortFunc(sum.Params, func(a, b model.RunParams) int {
+		return cmp.Or(
+			cmp.Compare(a.GOOS, b.GOOS),
+			cmp.Compare(a.GOARCH, b.GOARCH),
+			cmp.Compare(a.MicroArch, b.MicroArch),
+			cmp.Compare(a.CPU, b.CPU),
+			cmp.Compare(a.GoVersion, b.GoVersion),
+			cmp.Compare(fmt.Sprint(a.CGO), fmt.Sprint(b.CGO)),
+			cmp.Compare(a.DatasetHash, b.DatasetHash),
+		)
+	})
+	return sum
+}
+
+// summaryPath returns the path to summaries/<branch>.json
+// (summaries/<suite>/ for a named suite).
+func (s *Storage) summaryPath(branch string) string {
+	return s.derivedPath(summariesDirName, branch)
+}
+
+// derivedPath returns the path to <dirName>/<branch>.json
+// (<dirName>/<suite>/ for a named suite), the file of data derived from the
+// branch data. Derived files live outside data/ so that none can take the
+// file name of a branch's data.
+func (s *Storage) derivedPath(dirName, branch string) string {
+	dir := filepath.Join(s.baseDir, dirName)
+	if s.suite != "" {
+		dir = filepath.Join(dir, s.suite)
+	}
+	return filepath.Join(dir, sanitizeBranchName(branch)+".json")
+}
+
+// WriteBranchSummary writes summaries/<branch>.json for data, the
+// branch's entries. WriteBranchData calls it, so the summary follows every
+// write of the branch data.
+func (s *Storage) WriteBranchSummary(branch string, data model.BranchData) error {
+	encoded, err := json.MarshalIndent(Summarize(data), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding summary for %q: %w", branch, err)
+	}
+	if _, err := s.writeFile(s.summaryPath(branch), encoded, 0o644); err != nil {
+		return fmt.Errorf("writing summary for %q: %w", branch, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestWriteBranchSummary(t *testing.T) {
+	linux := model.RunParams{CPU: "Intel", GOOS: "linux", GOARCH: "amd64"}
+	darwin := model.RunParams{CPU: "M1", GOOS: "darwin", GOARCH: "arm64"}
+	entry := func(sha, date string, params model.RunParams, results ...model.BenchmarkResult) model.BenchmarkEntry {
+		return model.BenchmarkEntry{Commit: model.Commit{SHA: sha, Date: date}, Params: params, Benchmarks: results}
+	}
+
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	// WriteBranchData keeps the summary in step with the data.
+	if err := s.WriteBranchData("feature/x", model.BranchData{
+		entry("aaa", "2026-01-01T00:00:00Z", linux,
+			model.BenchmarkResult{Name: "BenchmarkB", Value: 1, Unit: "ns/op"},
+			model.BenchmarkResult{Name: "BenchmarkB - B/op", Value: 8, Unit: "B/op"}),
+		entry("bbb", "2026-01-02T00:00:00Z", darwin,
+			model.BenchmarkResult{Name: "BenchmarkA", Value: 1, Unit: "ns/op", Package: "codec"}),
+		entry("bbb", "2026-01-02T00:00:00Z", linux,
+			model.BenchmarkResult{Name: "BenchmarkA", Value: 1, Unit: "ns/op", Package: "codec"}),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(s.summaryPath("feature/x"))
+	if err != nil {
+		t.Fatalf("reading summary: %v", err)
+	}
+	var got BranchSummary
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("decoding summary: %v", err)
+	}
+	want := BranchSummary{
+		Entries:    3,
+		LatestSHA:  "bbb",
+		LatestDate: "2026-01-02T00:00:00Z",
+		Benchmarks: []string{"BenchmarkB", "codec.BenchmarkA"},
+		Params:     []model.RunParams{darwin, linux},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if err := s.WriteBranchData("empty", nil); err != nil {
+		t.Fatal(err)
+	}
+	raw, err = os.ReadFile(s.summaryPath("empty"))
+	if err != nil {
+		t.Fatalf("reading summary: %v", err)
+	}
+	if want := `"benchmarks": []`; !json.Valid(raw) || !strings.Contains(string(raw), want) {
+		t.Errorf("empty branch summary %s lacks %s", raw, want)
+	}
+}
+
+func TestWriteBranchSummary_BranchNamedLikeSummary(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	entry := model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: "aaa", Date: "2026-01-01T00:00:00Z"},
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 1, Unit: "ns/op"}},
+	}
+	if err := s.WriteBranchData("x.summary", model.BranchData{entry}); err != nil {
+		t.Fatal(err)
+	}
+	// The summary of x must not overwrite the data of x.summary.
+	if err := s.WriteBranchData("x", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.ReadBranchData("x.summary")
+	if err != nil {
+		t.Fatalf("ReadBranchData() error: %v", err)
+	}
+	if len(got) != 1 || got[0].Commit.SHA != "aaa" {
+		t.Errorf("data of x.summary: got %+v, want the one stored entry", got)
+	}
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// Suppression is a time window during which regression alerts are muted,
+// e.g. while CI runners are migrated. Benchmarks optionally restricts it to
+// benchmarks matching one of the path.Match globs; empty means all.
+// Suppressions are stored in data/suppressions.json.
+type Suppression struct {
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	Benchmarks []string  `json:"benchmarks,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+// Matches reports whether result r, measured at date, is muted by the
+// window. Globs are matched against both the full and the base name (see
+// model.BenchmarkResult.BaseName). Both ends of the window are inclusive.
+func (sp Suppression) Matches(date time.Time, r model.BenchmarkResult) bool {
+	if date.Before(sp.From) || date.After(sp.To) {
+		return false
+	}
+	if len(sp.Benchmarks) == 0 {
+		return true
+	}
+	for _, pattern := range sp.Benchmarks {
+		if ok, _ := path.Match(pattern, r.Name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, r.BaseName()); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// suppressionsPath returns the path to data/suppressions.json.
+func (s *Storage) suppressionsPath() string {
+	return filepath.Join(s.dataDir(), "suppressions.json")
+}
+
+// ReadSuppressions returns all stored suppression windows. A missing file
+// yields an empty slice.
+func (s *Storage) ReadSuppressions() ([]Suppression, error) {
+	data, err := os.ReadFile(s.suppressionsPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading suppressions: %w", err)
+	}
+	var suppressions []Suppression
+	if err := json.Unmarshal(data, &suppressions); err != nil {
+		return nil, fmt.Errorf("decoding suppressions: %w", err)
+	}
+	return suppressions, nil
+}
+
+// AddSuppression records sp. It fails if the window ends before it starts
+// or a glob is malformed.
+func (s *Storage) AddSuppression(sp Suppression) error {
+	if sp.To.Before(sp.From) {
+		return fmt.Errorf("suppression window ends (%s) before it starts (%s)", sp.To.Format(time.RFC3339), sp.From.Format(time.RFC3339))
+	}
+	for _, pattern := range sp.Benchmarks {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid benchmark pattern %q: %w", pattern, err)
+		}
+	}
+
+	suppressions, err := s.ReadSuppressions()
+	if err != nil {
+		return err
+	}
+	suppressions = append(suppressions, sp)
+
+	data, err := json.MarshalIndent(suppressions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding suppressions: %w", err)
+	}
+	if _, err := s.writeFile(s.suppressionsPath(), data, 0o644); err != nil {
+		return fmt.Errorf("writing suppressions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func TestSuppressions(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)
+	if err := s.AddSuppression(Suppression{From: from, To: to, Benchmarks: []string{"BenchmarkNet*"}, Reason: "runner migration"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddSuppression(Suppression{From: to, To: from}); err == nil {
+		t.Error("expected error for a window ending before it starts")
+	}
+
+	got, err := s.ReadSuppressions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Reason != "runner migration" || !got[0].From.Equal(from) {
+		t.Fatalf("ReadSuppressions() = %+v", got)
+	}
+
+	sp := got[0]
+	net := model.BenchmarkResult{Name: "BenchmarkNetDial - B/op", Unit: "B/op"}
+	tests := []struct {
+		name string
+		date time.Time
+		r    model.BenchmarkResult
+		want bool
+	}{
+		{"inside", from.Add(time.Hour), net, true},
+		{"at start", from, net, true},
+		{"at end", to, net, true},
+		{"before", from.Add(-time.Second), net, false},
+		{"after", to.Add(time.Second), net, false},
+		{"other benchmark", from.Add(time.Hour), model.BenchmarkResult{Name: "BenchmarkParse", Unit: "ns/op"}, false},
+	}
+	for _, tt := range tests {
+		if got := sp.Matches(tt.date, tt.r); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// Errors reported by VerifyReleases. The returned error wraps one of these
+// for every violated invariant, so callers can test with errors.Is.
+var (
+	// ErrReleaseUntagged means an entry in the releases aggregate has no
+	// commit SHA → tag mapping in release_tags.json.
+	ErrReleaseUntagged = errors.New("releases entry has no tag mapping")
+
+	// ErrReleaseNotSemver means release_tags.json maps a commit to a name
+	// that is not a semver tag, i.e. a regular branch leaked into releases.
+	ErrReleaseNotSemver = errors.New("releases entry mapped to a non-semver name")
+
+	// ErrReleaseMissingEntry means an entry stored in a per-tag data file
+	// is absent from the releases aggregate.
+	ErrReleaseMissingEntry = errors.New("tag entry missing from releases")
+)
+
+// VerifyReleases checks the consistency of the releases subsystem without
+// modifying anything on disk:
+//
+//   - every entry in the releases aggregate has a mapping in release_tags.json,
+//   - every mapped name is a semver tag (no regular branch leaked in),
+//   - every entry in a per-tag data file also appears in the aggregate.
+//
+// It returns nil when all invariants hold, otherwise an error joining one
+// wrapped sentinel error per violation.
+func (s *Storage) VerifyReleases() error {
+	releases, err := s.ReadBranchData(ReleasesVirtualBranch)
+	if err != nil {
+		return err
+	}
+	tags, err := s.readReleaseTags()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	inReleases := make(map[model.EntryKeyValue]struct{}, len(releases))
+	for _, e := range releases {
+		inReleases[e.EntryKey()] = struct{}{}
+
+		tag, ok := tags[e.Commit.SHA]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("%w: commit %s", ErrReleaseUntagged, e.Commit.SHA))
+		case !IsSemanticVersionTag(tag):
+			errs = append(errs, fmt.Errorf("%w: commit %s mapped to %q", ErrReleaseNotSemver, e.Commit.SHA, tag))
+		}
+	}
+
+	// Check each distinct tag's own data file against the aggregate.
+	seenTags := make(map[string]struct{})
+	for _, tag := range tags {
+		if _, done := seenTags[tag]; done || !IsSemanticVersionTag(tag) {
+			continue
+		}
+		seenTags[tag] = struct{}{}
+
+		entries, err := s.ReadBranchData(tag)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if _, ok := inReleases[e.EntryKey()]; !ok {
+				errs = append(errs, fmt.Errorf("%w: tag %s commit %s", ErrReleaseMissingEntry, tag, e.Commit.SHA))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
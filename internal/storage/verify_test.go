@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+func releaseEntry(sha, date string, millis int64) model.BenchmarkEntry {
+	return model.BenchmarkEntry{
+		Commit: model.Commit{SHA: sha, Date: date},
+		Date:   millis,
+		Params: model.RunParams{CPU: "TestCPU", GOOS: "linux", GOARCH: "amd64"},
+		Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkFoo", Value: 100, Unit: "ns/op"},
+		},
+	}
+}
+
+// seedReleases stores two tagged releases through the normal append path.
+func seedReleases(t *testing.T) *Storage {
+	t.Helper()
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := s.AppendEntry("v1.0.0", releaseEntry("aaa111", "2024-01-01T00:00:00Z", 1704067200000), 0); err != nil {
+		t.Fatalf("AppendEntry(v1.0.0) error: %v", err)
+	}
+	if err := s.AppendEntry("v2.0.0", releaseEntry("bbb222", "2024-06-01T00:00:00Z", 1717200000000), 0); err != nil {
+		t.Fatalf("AppendEntry(v2.0.0) error: %v", err)
+	}
+	return s
+}
+
+func TestVerifyReleases_Consistent(t *testing.T) {
+	s := seedReleases(t)
+	if err := s.VerifyReleases(); err != nil {
+		t.Fatalf("VerifyReleases() error: %v", err)
+	}
+}
+
+func TestVerifyReleases_EmptyStorage(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := s.VerifyReleases(); err != nil {
+		t.Fatalf("VerifyReleases() error: %v", err)
+	}
+}
+
+func TestVerifyReleases_UntaggedEntry(t *testing.T) {
+	s := seedReleases(t)
+
+	tags, err := s.readReleaseTags()
+	if err != nil {
+		t.Fatalf("readReleaseTags() error: %v", err)
+	}
+	delete(tags, "bbb222")
+	if err := s.writeReleaseTags(tags); err != nil {
+		t.Fatalf("writeReleaseTags() error: %v", err)
+	}
+
+	err = s.VerifyReleases()
+	if !errors.Is(err, ErrReleaseUntagged) {
+		t.Fatalf("expected ErrReleaseUntagged, got %v", err)
+	}
+}
+
+func TestVerifyReleases_BranchLeakedIntoReleases(t *testing.T) {
+	s := seedReleases(t)
+
+	leaked := releaseEntry("ccc333", "2024-07-01T00:00:00Z", 1719792000000)
+	if err := s.mergeEntries(ReleasesVirtualBranch, []model.BenchmarkEntry{leaked}, RetentionPolicy{}); err != nil {
+		t.Fatalf("mergeEntries() error: %v", err)
+	}
+	if err := s.recordReleaseTags("main", []model.BenchmarkEntry{leaked}); err != nil {
+		t.Fatalf("recordReleaseTags() error: %v", err)
+	}
+
+	err := s.VerifyReleases()
+	if !errors.Is(err, ErrReleaseNotSemver) {
+		t.Fatalf("expected ErrReleaseNotSemver, got %v", err)
+	}
+	if errors.Is(err, ErrReleaseUntagged) || errors.Is(err, ErrReleaseMissingEntry) {
+		t.Errorf("unexpected additional violations: %v", err)
+	}
+}
+
+func TestVerifyReleases_TagEntryMissingFromAggregate(t *testing.T) {
+	s := seedReleases(t)
+
+	relData, err := s.ReadBranchData(ReleasesVirtualBranch)
+	if err != nil {
+		t.Fatalf("ReadBranchData(releases) error: %v", err)
+	}
+	// Drop v1.0.0's entry from the aggregate, leaving only the per-tag file.
+	if err := s.WriteBranchData(ReleasesVirtualBranch, relData[1:]); err != nil {
+		t.Fatalf("WriteBranchData(releases) error: %v", err)
+	}
+
+	err = s.VerifyReleases()
+	if !errors.Is(err, ErrReleaseMissingEntry) {
+		t.Fatalf("expected ErrReleaseMissingEntry, got %v", err)
+	}
+	if errors.Is(err, ErrReleaseUntagged) || errors.Is(err, ErrReleaseNotSemver) {
+		t.Errorf("unexpected additional violations: %v", err)
+	}
+}
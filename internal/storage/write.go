@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// WriteIfChanged writes content to path unless the file already holds
+// exactly that content. It reports whether the file was written.
+//
+// Skipping identical writes keeps the file's modification time and avoids
+// needless churn in the git history of the gh-pages branch.
+func WriteIfChanged(path string, content []byte, perm os.FileMode) (changed bool, err error) {
+	existing, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(existing, content) {
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	if err := writeFileAtomic(path, content, perm); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// writeFileAtomic writes data to path+".tmp" and renames it over path, so a
+// process killed mid-write leaves either the old or the new file, never a
+// truncated one. The rename is atomic on POSIX; on Windows it is not, but
+// still never exposes a partial file at path.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
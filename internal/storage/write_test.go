@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteIfChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.json")
+
+	changed, err := WriteIfChanged(path, []byte("v1"), 0o644)
+	if err != nil {
+		t.Fatalf("first write error: %v", err)
+	}
+	if !changed {
+		t.Error("first write should report a change")
+	}
+
+	// Back-date the file so an unexpected rewrite is observable.
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err = WriteIfChanged(path, []byte("v1"), 0o644)
+	if err != nil {
+		t.Fatalf("second write error: %v", err)
+	}
+	if changed {
+		t.Error("identical content should not be rewritten")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(old) {
+		t.Errorf("mtime changed to %v, want %v", info.ModTime(), old)
+	}
+
+	changed, err = WriteIfChanged(path, []byte("v2"), 0o644)
+	if err != nil {
+		t.Fatalf("third write error: %v", err)
+	}
+	if !changed {
+		t.Error("different content should be written")
+	}
+	if got, _ := os.ReadFile(path); string(got) != "v2" {
+		t.Errorf("content = %q, want v2", got)
+	}
+}
+
+func TestWriteBranches_UnchangedSkipsWrite(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	if err := s.WriteBranches([]string{"main"}); err != nil {
+		t.Fatalf("WriteBranches() error: %v", err)
+	}
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(s.branchesPath(), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.WriteBranches([]string{"main"}); err != nil {
+		t.Fatalf("WriteBranches() error: %v", err)
+	}
+	info, err := os.Stat(s.branchesPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(old) {
+		t.Error("unchanged branch list should not be rewritten")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A temp file left behind by a killed write must not get in the way.
+	if err := os.WriteFile(path+".tmp", []byte("truncat"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(path, []byte("new"), 0o644); err != nil {
+		t.Fatalf("writeFileAtomic() error: %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "new" {
+		t.Errorf("content = %q, want new", got)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temp file left behind: %v", err)
+	}
+
+	// Failing to create the temp file is reported.
+	missing := filepath.Join(t.TempDir(), "missing", "data.json")
+	if err := writeFileAtomic(missing, []byte("x"), 0o644); err == nil {
+		t.Error("write into a missing directory: want an error")
+	}
+}
@@ -2,22 +2,32 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
-	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/royalcat/go-continuous-benchmarking/internal/hwinfo"
+	"github.com/royalcat/go-continuous-benchmarking/internal/gitinfo"
 	"github.com/royalcat/go-continuous-benchmarking/internal/model"
 	"github.com/royalcat/go-continuous-benchmarking/internal/parse"
+	"github.com/royalcat/go-continuous-benchmarking/internal/regression"
+	"github.com/royalcat/go-continuous-benchmarking/internal/report"
 	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+	"github.com/royalcat/go-continuous-benchmarking/pkg/gobenchdata"
 )
 
 //go:embed frontend/*
@@ -35,6 +45,55 @@ Commands:
           merge them into the branch data on gh-pages, and deploy
           the frontend. Run this once after all benchmark jobs finish.
 
+  report  Analyse stored benchmark data (e.g. "report trend").
+
+  validate-data
+          Run read-only consistency checks against stored benchmark data.
+
+  delete-benchmark
+          Remove a benchmark (by name or glob) from every entry of a branch.
+
+  query   Print the history of a benchmark as JSON, optionally smoothed.
+
+  serve   Serve the data directory over HTTP with range request support.
+
+  recompute
+          Re-apply derivations (name canonicalization, rounding, sorting)
+          to all stored data and rebuild the releases aggregate.
+
+  print-key
+          Print the deduplication key and data file of an entry.json.
+
+  export  Write stored branch data in another format (e.g. benchfmt
+          for benchstat).
+
+  compare-goversions
+          Show each benchmark of one commit across the Go versions it
+          was stored under.
+
+  compare Compare two commits of a branch benchmark by benchmark,
+          listing added and removed ones.
+
+  compare-branches
+          Compare the latest comparable entries of two branches, e.g.
+          a feature branch against main.
+
+  suppress
+          Mute regression alerts for a time window, optionally only
+          for some benchmarks.
+
+  prune   Remove branches that no longer exist, with their data files.
+
+  pin     Compare benchmarks matching a glob against a fixed commit
+          (e.g. the last known-good release) instead of the previous one.
+
+  import  Rebuild branch data from an external source (e.g. a
+          Prometheus range query).
+
+  import-raw
+          Backfill branch data from archived go test -bench output
+          files, taking each commit SHA from the file name.
+
 Run "gobenchdata <command> -help" for flag details.
 `)
 	os.Exit(2)
@@ -53,6 +112,38 @@ func main() {
 		runParse(os.Args[2:])
 	case "store":
 		runStore(os.Args[2:])
+	case "report":
+		runReport(os.Args[2:])
+	case "validate-data":
+		runValidateData(os.Args[2:])
+	case "delete-benchmark":
+		runDeleteBenchmark(os.Args[2:])
+	case "export":
+		runExport(os.Args[2:])
+	case "compare-goversions":
+		runCompareGoVersions(os.Args[2:])
+	case "compare":
+		runCompare(os.Args[2:])
+	case "compare-branches":
+		runCompareBranches(os.Args[2:])
+	case "suppress":
+		runSuppress(os.Args[2:])
+	case "pin":
+		runPin(os.Args[2:])
+	case "prune":
+		runPrune(os.Args[2:])
+	case "import":
+		runImport(os.Args[2:])
+	case "import-raw":
+		runImportRaw(os.Args[2:])
+	case "print-key":
+		runPrintKey(os.Args[2:])
+	case "recompute":
+		runRecompute(os.Args[2:])
+	case "serve":
+		runServe(os.Args[2:])
+	case "query":
+		runQuery(os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", command)
 		usage()
@@ -74,130 +165,317 @@ func runParse(args []string) {
 		commitAuthor string
 		commitDate   string
 		commitURL    string
+		parents      string
 		cpuModel     string
 		cgoFlag      string
 		goVersion    string
 		goModule     string
 		repoURL      string
+		aggregate    bool
+		discardFirst bool
+		check        bool
+		requireFull  bool
+		envCapture   string
+		tags         = tagsFlag{}
+		checkTiming  bool
+		reportFile   string
+		strictUnits  string
+		binaryPath   string
+		datasetHash  string
+		compressLog  bool
+		slowNs       float64
+		cpuConflict  string
+		strict       bool
+		format       string
+		signKeyFile  string
+		summaryJSON  string
+		quiet        bool
+		pkgFilter    string
+		gitDir       string
+		inlineMem    bool
+		suite        string
+		cpuNorm      bool
+		microArch    string
 	)
 
 	fs.StringVar(&outputFile, "output-file", "", "Path to go test -bench output file (reads stdin if empty)")
+	fs.StringVar(&format, "format", "text", "Input format: 'text' (go test -bench output) or 'jsonl' (one {\"name\",\"value\",\"unit\"} object per line)")
 	fs.StringVar(&resultDir, "result-dir", "benchmark-result", "Directory to write the parsed entry JSON and output log")
 	fs.StringVar(&commitSHA, "commit-sha", "", "Commit SHA (required)")
 	fs.StringVar(&commitMsg, "commit-msg", "", "Commit message")
 	fs.StringVar(&commitAuthor, "commit-author", "", "Commit author")
 	fs.StringVar(&commitDate, "commit-date", "", "Commit date in ISO 8601 (defaults to now)")
 	fs.StringVar(&commitURL, "commit-url", "", "URL to the commit")
+	fs.StringVar(&gitDir, "git-dir", "", "Read the subject, author and date of -commit-sha (HEAD and its SHA if unset) from this git work tree for any -commit-* flag left empty; -commit-url is derived from -repo-url")
+	fs.StringVar(&parents, "commit-parents", "", "Comma-separated ancestors of the commit, nearest first (e.g. from 'git rev-list --first-parent HEAD~1 -n 50'), for report bisect-range")
 	fs.StringVar(&cpuModel, "cpu-model", "", "CPU model name (auto-detected if empty)")
+	fs.BoolVar(&cpuNorm, "cpu-normalize", false, "Store a canonical CPU model without trademark marks, the word 'CPU' and the clock speed (e.g. 'Intel Core i7-8700'), so runs on steppings of one model share a timeline; the detected name is kept as cpuRaw")
+	fs.StringVar(&cpuConflict, "cpu-conflict", "warn", "What to do when packages in the output report different cpu: lines: 'warn' or 'error'")
 	fs.StringVar(&cgoFlag, "cgo", "", "CGO enabled: 'true', 'false', or '' (auto-detect)")
 	fs.StringVar(&goVersion, "go-version", "", "Go version string (auto-detected from runtime if empty)")
+	fs.StringVar(&microArch, "microarch", "", "Microarchitecture level the benchmarks were built for, e.g. 'v3' (read from GOAMD64, GOARM, GOARM64 or the like for GOARCH if empty)")
 	fs.StringVar(&goModule, "go-module", "", "Go module path to strip from package names (auto-detect if empty)")
 	fs.StringVar(&repoURL, "repo-url", "", "Repository URL (used for go-module fallback)")
+	fs.BoolVar(&aggregate, "aggregate", false, "Collapse repeated samples of a benchmark (go test -count=N) into mean and stddev")
+	fs.BoolVar(&inlineMem, "inline-mem-metrics", false, "Attach B/op and allocs/op to the ns/op result as bytesPerOp/allocsPerOp instead of storing them as separate results (the dashboard only charts separate results)")
+	fs.BoolVar(&discardFirst, "discard-first", false, "Drop the first sample of each benchmark before aggregating (implies -aggregate)")
+	fs.StringVar(&envCapture, "env-capture", "", "Comma-separated environment variable names to record with the entry (e.g. INSTANCE_TYPE,REGION)")
+	fs.Var(tags, "tag", "Experiment tag key=value to label this run (repeatable)")
+	fs.BoolVar(&requireFull, "require-complete", false, "Fail if the output ends without a PASS/ok/FAIL line (e.g. a truncated pipe)")
+	fs.Float64Var(&slowNs, "slow-threshold-ns", model.DefaultSlowThresholdNs, "Warn about benchmarks slower than this many ns/op (0 = never)")
+	fs.BoolVar(&compressLog, "compress-log", false, "Stream the raw output to output.log.gz instead of buffering it in memory for output.log")
+	fs.StringVar(&reportFile, "report-file", "", "Also write a table of the parsed results to this file (Markdown if it ends in .md, plain text otherwise)")
+	fs.BoolVar(&checkTiming, "validate-timing", false, "Warn when iterations × ns/op of a package does not fit the duration on its 'ok' line (catches misaligned columns)")
+	fs.StringVar(&pkgFilter, "package-filter", "", "Comma-separated package path globs (path.Match, e.g. 'example.com/m/internal/*'); only results of matching packages are kept (empty = all)")
+	fs.StringVar(&strictUnits, "strict-units", "", "Comma-separated allow-list of metric units (e.g. ns/op,B/op,allocs/op); values with other units are dropped with a warning")
+	fs.StringVar(&datasetHash, "dataset-hash", "", "Identifier of the input data the benchmarks read (e.g. a hash of their fixtures); runs on different datasets are stored and compared separately")
+	fs.StringVar(&binaryPath, "binary-path", "", "Compiled test binary whose size to record as a BinarySize result in bytes (skipped with a warning if missing)")
+	fs.StringVar(&summaryJSON, "summary-json", "", "Also write a JSON summary (result count, artifact name, run parameters, benchmark names) to this file for later CI steps")
+	fs.BoolVar(&quiet, "quiet", false, "Print only warnings and the artifact-name line, not progress and the parsed results")
+	fs.StringVar(&signKeyFile, "sign-key-file", "", "File holding the HMAC key to sign entry.json with, for stores that verify signatures (e.g. a CI secret unavailable to fork PRs); $"+signKeyEnv+" is used if unset")
+	fs.BoolVar(&strict, "strict", false, "Fail instead of warning when -strict-units drops a value")
+	fs.BoolVar(&check, "check", false, "Only validate that the output parses; write no files and skip host detection")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite the results belong to; it prefixes the artifact name so the suites of one job do not collide (store with the same -suite)")
 
 	fs.Parse(args)
 
-	if commitSHA == "" {
-		log.Fatal("Error: -commit-sha is required")
+	// infof prints progress that -quiet suppresses; warnings always print.
+	infof := func(format string, a ...any) {
+		if !quiet {
+			fmt.Printf(format, a...)
+		}
 	}
 
-	if commitDate == "" {
-		commitDate = time.Now().UTC().Format(time.RFC3339)
+	if format != "text" && format != "jsonl" {
+		log.Fatalf("Error: unknown -format %q (want text or jsonl)", format)
 	}
-
-	// --- Host metadata (auto-detect on the runner) ---
-
-	cpu := cpuModel
-	if cpu == "" {
-		cpu = hwinfo.CPUModel()
-		fmt.Printf("Auto-detected CPU model: %s\n", cpu)
-	} else {
-		fmt.Printf("Using provided CPU model: %s\n", cpu)
+	if suite != "" && !storage.ValidSuiteName(suite) {
+		log.Fatalf("Error: invalid -suite %q (want letters, digits, '-' and '_')", suite)
+	}
+	if format == "jsonl" && (check || strictUnits != "" || pkgFilter != "" || inlineMem) {
+		log.Fatal("Error: -check, -strict-units, -package-filter and -inline-mem-metrics are only supported with -format text")
 	}
 
-	cgoEnabled := detectCGO(cgoFlag)
-	fmt.Printf("CGO enabled: %v\n", cgoEnabled)
+	// Check mode only validates the output: no host detection, no files.
+	if check {
+		reader, err := openInput(outputFile)
+		if err != nil {
+			log.Fatalf("Error opening output file: %v", err)
+		}
+		defer reader.Close()
+		if err := checkOutput(reader, os.Stdout); err != nil {
+			log.Fatalf("Check failed: %v", err)
+		}
+		return
+	}
 
-	goVer := goVersion
-	if goVer == "" {
-		goVer = runtime.Version()
-		fmt.Printf("Auto-detected Go version: %s\n", goVer)
-	} else {
-		fmt.Printf("Using provided Go version: %s\n", goVer)
+	if gitDir != "" {
+		// Describe the given commit, which need not be checked out, or HEAD.
+		ref := "HEAD"
+		if commitSHA != "" {
+			ref = commitSHA
+		}
+		head, err := gitinfo.CommitInfo(gitDir, ref)
+		if err != nil {
+			log.Fatalf("Error reading commit %s from -git-dir: %v", ref, err)
+		}
+		// Explicit flags win over what the repository says.
+		if commitSHA == "" {
+			commitSHA = head.SHA
+			infof("Auto-detected commit: %s\n", commitSHA)
+		}
+		if commitMsg == "" {
+			commitMsg = head.Subject
+		}
+		if commitAuthor == "" {
+			commitAuthor = head.Author
+		}
+		if commitDate == "" {
+			commitDate = head.Date
+		}
+		if commitURL == "" && repoURL != "" {
+			commitURL = strings.TrimSuffix(repoURL, "/") + "/commit/" + commitSHA
+		}
 	}
 
-	goos := runtime.GOOS
-	goarch := runtime.GOARCH
-	fmt.Printf("GOOS: %s, GOARCH: %s\n", goos, goarch)
+	if commitSHA == "" {
+		log.Fatal("Error: -commit-sha (or -git-dir) is required")
+	}
+	if cpuConflict != "warn" && cpuConflict != "error" {
+		log.Fatalf("Error: unknown -cpu-conflict %q (want warn or error)", cpuConflict)
+	}
 
+	// Run parameters are detected by gobenchdata.ParseDetailed; the Go module
+	// is only reported.
 	if goModule == "" {
 		goModule = detectGoModule(repoURL)
 		if goModule != "" {
-			fmt.Printf("Auto-detected Go module: %s\n", goModule)
+			infof("Auto-detected Go module: %s\n", goModule)
 		}
 	} else {
-		fmt.Printf("Using provided Go module: %s\n", goModule)
+		infof("Using provided Go module: %s\n", goModule)
 	}
 
 	// --- Read and parse benchmark output ---
 
-	var reader io.Reader
-	if outputFile != "" {
-		f, err := os.Open(outputFile)
-		if err != nil {
-			log.Fatalf("Error opening output file: %v", err)
-		}
-		defer f.Close()
-		reader = f
-	} else {
-		reader = os.Stdin
+	reader, err := openInput(outputFile)
+	if err != nil {
+		log.Fatalf("Error opening output file: %v", err)
 	}
+	defer reader.Close()
 
-	// Tee: we read once and both parse and capture raw output.
-	var rawBuf strings.Builder
+	// Tee: we read once and both parse and capture raw output, either in
+	// memory or streamed straight into the compressed log.
+	var (
+		rawBuf  strings.Builder
+		gzLog   *gzipFile
+		logPath = filepath.Join(resultDir, "output.log")
+	)
 	tee := io.TeeReader(reader, &rawBuf)
+	if compressLog {
+		if err := os.MkdirAll(resultDir, 0o755); err != nil {
+			log.Fatalf("Error creating result directory: %v", err)
+		}
+		logPath += ".gz"
+		if gzLog, err = createGzipFile(logPath); err != nil {
+			log.Fatalf("Error creating output log: %v", err)
+		}
+		tee = io.TeeReader(reader, gzLog)
+	}
+
+	parseCfg := gobenchdata.ParseConfig{
+		Input:  tee,
+		Format: format,
+		Commit: model.Commit{
+			SHA:     commitSHA,
+			Message: commitMsg,
+			Author:  commitAuthor,
+			Date:    commitDate,
+			URL:     commitURL,
+		},
+		CPU:              cpuModel,
+		NormalizeCPU:     cpuNorm,
+		GoVersion:        goVersion,
+		MicroArch:        microArch,
+		CGO:              cgoFlag,
+		DatasetHash:      datasetHash,
+		Tags:             tags,
+		Aggregate:        aggregate,
+		DiscardFirst:     discardFirst,
+		InlineMemMetrics: inlineMem,
+	}
+	for _, sha := range strings.Split(parents, ",") {
+		if sha = strings.TrimSpace(sha); sha != "" {
+			parseCfg.Commit.Parents = append(parseCfg.Commit.Parents, sha)
+		}
+	}
+	for _, u := range strings.Split(strictUnits, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			parseCfg.AllowedUnits = append(parseCfg.AllowedUnits, u)
+		}
+	}
+	for _, p := range strings.Split(pkgFilter, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			log.Fatalf("Error: invalid -package-filter glob %q: %v", p, err)
+		}
+		parseCfg.Packages = append(parseCfg.Packages, p)
+	}
 
-	benchmarks, outputMeta, err := parse.ParseGoBenchOutputWithMeta(tee)
+	entry, details, err := gobenchdata.ParseDetailed(parseCfg)
 	if err != nil {
-		log.Fatalf("Error parsing benchmark output: %v", err)
+		log.Fatalf("Error %v", err)
 	}
+	parseResult, outputMeta := details.Result, details.Meta
 
-	// If the go test output had a cpu: line and we auto-detected, prefer
-	// the output's CPU (it reflects the actual benchmark machine).
-	if cpuModel == "" && outputMeta.CPU != "" {
-		cpu = outputMeta.CPU
-		fmt.Printf("Using CPU from go test output: %s\n", cpu)
+	switch {
+	case cpuModel != "":
+		infof("Using provided CPU model: %s\n", entry.Params.CPU)
+	case outputMeta.CPU != "":
+		infof("Using CPU from go test output: %s\n", entry.Params.CPU)
+	default:
+		infof("Auto-detected CPU model: %s\n", entry.Params.CPU)
+	}
+	if entry.CPURaw != "" {
+		infof("Normalized CPU model from: %s\n", entry.CPURaw)
+	}
+	if len(entry.CPUModels) > 1 {
+		infof("Heterogeneous CPU models: %s\n", strings.Join(entry.CPUModels, " + "))
+	}
+	infof("CGO enabled: %v\n", entry.Params.CGO)
+	if goVersion == "" {
+		infof("Auto-detected Go version: %s\n", entry.Params.GoVersion)
+	} else {
+		infof("Using provided Go version: %s\n", entry.Params.GoVersion)
+	}
+	infof("GOOS: %s, GOARCH: %s\n", entry.Params.GOOS, entry.Params.GOARCH)
+	if entry.Params.MicroArch != "" {
+		infof("Microarchitecture level: %s\n", entry.Params.MicroArch)
 	}
 
-	fmt.Printf("Parsed %d benchmark result(s)\n", len(benchmarks))
-	for _, b := range benchmarks {
-		fmt.Printf("  %s: %.4f %s\n", b.Name, b.Value, b.Unit)
+	if summary := parseResult.Summary(); summary != "" {
+		fmt.Printf("Warning: %s\n", summary)
+	}
+	if parseResult.UnknownUnits > 0 && strict {
+		log.Fatalf("Error: %d value(s) with a unit outside -strict-units %q", parseResult.UnknownUnits, strictUnits)
+	}
+	if !outputMeta.Complete {
+		if requireFull {
+			log.Fatal("Error: benchmark output ended without a PASS/ok/FAIL line; the run may be incomplete")
+		}
+		fmt.Println("Warning: benchmark output ended without a PASS/ok/FAIL line; the run may be incomplete")
+	}
+	if checkTiming {
+		for _, w := range parse.ValidateTiming(entry.Benchmarks, outputMeta) {
+			fmt.Printf("Warning: %s\n", w)
+		}
 	}
 
-	// --- Build BenchmarkEntry ---
+	if w := slowWarning(entry.Benchmarks, slowNs); w != "" {
+		fmt.Println(w)
+	}
 
-	// Parse commit date to use as the entry timestamp instead of the current time.
-	commitTime, err := time.Parse(time.RFC3339, commitDate)
-	if err != nil {
-		log.Fatalf("Error parsing commit date %q: %v", commitDate, err)
+	if binaryPath != "" {
+		size, err := model.BinarySizeResult(binaryPath)
+		if err != nil {
+			fmt.Printf("Warning: not recording binary size: %v\n", err)
+		} else {
+			entry.Benchmarks = append(entry.Benchmarks, size)
+			infof("Binary size of %s: %.0f bytes\n", binaryPath, size.Value)
+		}
 	}
 
-	entry := model.BenchmarkEntry{
-		Commit: model.Commit{
-			SHA:     commitSHA,
-			Message: firstLine(commitMsg),
-			Author:  commitAuthor,
-			Date:    commitDate,
-			URL:     commitURL,
-		},
-		Date: commitTime.UnixMilli(),
-		Params: model.RunParams{
-			CPU:       cpu,
-			GOOS:      goos,
-			GOARCH:    goarch,
-			GoVersion: goVer,
-			CGO:       cgoEnabled,
-		},
-		Benchmarks: benchmarks,
+	// Packages reporting different CPUs cannot share one entry's CPU.
+	if models := outputMeta.CPUConflicts(); models != nil {
+		msg := fmt.Sprintf("benchmark output reports %d different cpu: lines (%s); the entry is attributed to %q",
+			len(models), cpuConflictDetail(outputMeta.PackageCPUs), entry.Params.CPU)
+		if cpuConflict == "error" {
+			log.Fatalf("Error: %s", msg)
+		}
+		fmt.Printf("Warning: %s\n", msg)
+	}
+
+	infof("Parsed %d benchmark result(s)\n", len(entry.Benchmarks))
+	for _, b := range entry.Benchmarks {
+		infof("  %s: %.4f %s\n", b.Name, b.Value, b.Unit)
+	}
+
+	// --- Complete the BenchmarkEntry ---
+
+	if envCapture != "" {
+		entry.Environment = captureEnv(strings.Split(envCapture, ","))
+	}
+
+	signKey, err := readKey(signKeyFile, signKeyEnv)
+	if err != nil {
+		log.Fatalf("Error reading -sign-key-file: %v", err)
+	}
+	if len(signKey) > 0 {
+		if err := model.SignEntry(&entry, signKey); err != nil {
+			log.Fatalf("Error signing entry: %v", err)
+		}
 	}
 
 	// --- Write results to result-dir ---
@@ -215,24 +493,424 @@ func runParse(args []string) {
 	if err := os.WriteFile(entryPath, entryJSON, 0o644); err != nil {
 		log.Fatalf("Error writing entry JSON: %v", err)
 	}
-	fmt.Printf("Wrote parsed entry to %s\n", entryPath)
+	infof("Wrote parsed entry to %s\n", entryPath)
 
-	// Write output.log (raw benchmark output for debugging)
-	logPath := filepath.Join(resultDir, "output.log")
-	if err := os.WriteFile(logPath, []byte(rawBuf.String()), 0o644); err != nil {
+	// Write output.log (raw benchmark output for debugging). The compressed
+	// log also gets any trailing output the parser did not consume.
+	if gzLog != nil {
+		if _, err := io.Copy(io.Discard, tee); err != nil {
+			log.Fatalf("Error reading benchmark output: %v", err)
+		}
+		if err := gzLog.Close(); err != nil {
+			log.Fatalf("Error writing output log: %v", err)
+		}
+	} else if err := os.WriteFile(logPath, []byte(rawBuf.String()), 0o644); err != nil {
 		log.Fatalf("Error writing output log: %v", err)
 	}
-	fmt.Printf("Wrote raw output to %s\n", logPath)
+	infof("Wrote raw output to %s\n", logPath)
+
+	if reportFile != "" {
+		table := report.TextTable(entry.Benchmarks)
+		if strings.EqualFold(filepath.Ext(reportFile), ".md") {
+			table = report.MarkdownTable(entry.Benchmarks)
+		}
+		if err := os.WriteFile(reportFile, []byte(table), 0o644); err != nil {
+			log.Fatalf("Error writing report file: %v", err)
+		}
+		infof("Wrote results table to %s\n", reportFile)
+	}
 
 	// Generate a unique artifact name from run parameters so that matrix
 	// jobs never collide when uploading artifacts.
-	artifactName := artifactNameFromParams(entry.Params)
+	artifactName := artifactNameFromParams(entry.Params, suite)
+	// Always printed: the action scrapes this line for its artifact-name
+	// output, even with -quiet.
 	fmt.Printf("artifact-name: %s\n", artifactName)
+
+	if summaryJSON != "" {
+		data, err := json.MarshalIndent(newParseSummary(entry, artifactName, parseResult.Skipped), "", "  ")
+		if err != nil {
+			log.Fatalf("Error marshaling summary: %v", err)
+		}
+		if err := os.WriteFile(summaryJSON, append(data, '\n'), 0o644); err != nil {
+			log.Fatalf("Error writing summary JSON: %v", err)
+		}
+		infof("Wrote summary to %s\n", summaryJSON)
+	}
+}
+
+// parseSummary is the -summary-json output of parse.
+type parseSummary struct {
+	Benchmarks   int      `json:"benchmarks"`
+	Skipped      int      `json:"skipped"`
+	ArtifactName string   `json:"artifactName"`
+	CPU          string   `json:"cpu"`
+	GOOS         string   `json:"goos"`
+	GOARCH       string   `json:"goarch"`
+	MicroArch    string   `json:"microArch,omitempty"`
+	GoVersion    string   `json:"goVersion"`
+	Names        []string `json:"names"`
+}
+
+// newParseSummary summarises a parsed entry. Names lists each benchmark
+// name once, in output order.
+func newParseSummary(entry model.BenchmarkEntry, artifactName string, skipped int) parseSummary {
+	names := []string{}
+	seen := make(map[string]struct{})
+	for _, b := range entry.Benchmarks {
+		if _, ok := seen[b.Name]; !ok {
+			seen[b.Name] = struct{}{}
+			names = append(names, b.Name)
+		}
+	}
+	return parseSummary{
+		Benchmarks:   len(entry.Benchmarks),
+		Skipped:      skipped,
+		ArtifactName: artifactName,
+		CPU:          entry.Params.CPU,
+		GOOS:         entry.Params.GOOS,
+		GOARCH:       entry.Params.GOARCH,
+		MicroArch:    entry.Params.MicroArch,
+		GoVersion:    entry.Params.GoVersion,
+		Names:        names,
+	}
+}
+
+// checkOutput parses benchmark output from r and writes a short validation
+// report to w. It returns an error if the output cannot be parsed or holds
+// no benchmark results.
+func checkOutput(r io.Reader, w io.Writer) error {
+	benchmarks, meta, parseResult, err := parse.ParseGoBenchOutputDetailed(r)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{})
+	pkgs := make(map[string]struct{})
+	for _, b := range benchmarks {
+		names[b.Name] = struct{}{}
+		pkgs[b.Package] = struct{}{}
+	}
+
+	fmt.Fprintf(w, "OK: %d result(s) across %d benchmark series in %d package(s)\n", len(benchmarks), len(names), len(pkgs))
+	if meta.CPU == "" {
+		fmt.Fprintln(w, "Warning: no cpu: line found; the CPU model will be auto-detected on the runner")
+	}
+	if meta.CPUConflicts() != nil {
+		fmt.Fprintf(w, "Warning: packages report different cpu: lines: %s\n", cpuConflictDetail(meta.PackageCPUs))
+	}
+	if _, ok := pkgs[""]; ok {
+		fmt.Fprintln(w, "Warning: some results have no pkg: line and will carry no package")
+	}
+	if summary := parseResult.Summary(); summary != "" {
+		fmt.Fprintf(w, "Warning: %s\n", summary)
+	}
+	if !meta.Complete {
+		fmt.Fprintln(w, "Warning: output ended without a PASS/ok/FAIL line; the run may be incomplete")
+	}
+	return nil
+}
+
+// registerUnitDirections registers the comma-separated unit=higher|lower
+// pairs of -unit-direction with model.RegisterUnitDirection.
+func registerUnitDirections(s string) error {
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		unit, dir, ok := strings.Cut(pair, "=")
+		if !ok || unit == "" {
+			return fmt.Errorf("invalid -unit-direction %q (want unit=higher or unit=lower)", pair)
+		}
+		switch dir {
+		case "higher":
+			model.RegisterUnitDirection(unit, model.HigherIsBetter)
+		case "lower":
+			model.RegisterUnitDirection(unit, model.LowerIsBetter)
+		default:
+			return fmt.Errorf("invalid direction %q for unit %q (want higher or lower)", dir, unit)
+		}
+	}
+	return nil
+}
+
+// Environment variables holding the HMAC keys of parse and store, used
+// when -sign-key-file or -verify-key-file is not given. Keys are never taken
+// from the command line, where they would show up in process listings and
+// CI logs.
+const (
+	signKeyEnv   = "GOBENCHDATA_SIGN_KEY"
+	verifyKeyEnv = "GOBENCHDATA_VERIFY_KEY"
+)
+
+// readKey returns the key stored in file, without trailing whitespace, or
+// the value of the environment variable env if file is empty. An empty key
+// disables signing or verification.
+func readKey(file, env string) ([]byte, error) {
+	if file == "" {
+		return []byte(os.Getenv(env)), nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	key := bytes.TrimRight(data, " \t\r\n")
+	if len(key) == 0 {
+		return nil, fmt.Errorf("%s is empty", file)
+	}
+	return key, nil
+}
+
+// checkEntrySignature verifies e, decoded from raw, against key. An
+// unsigned entry is accepted unless requireSigned is set. A verified
+// signature is cleared: it covers the entry as parsed and would not match
+// once store derives from it.
+func checkEntrySignature(e *model.BenchmarkEntry, raw, key []byte, requireSigned bool) error {
+	err := model.VerifyEntryJSON(raw, key)
+	if errors.Is(err, model.ErrUnsigned) && !requireSigned {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	e.Signature = ""
+	return nil
+}
+
+// cpuConflictDetail lists the cpu: line of each package as "pkg=cpu",
+// sorted by package.
+func cpuConflictDetail(byPkg map[string]string) string {
+	pkgs := make([]string, 0, len(byPkg))
+	for pkg := range byPkg {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	parts := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		parts[i] = pkg + "=" + byPkg[pkg]
+	}
+	return strings.Join(parts, ", ")
+}
+
+// captureEnv snapshots the named environment variables. Unset variables are
+// left out; variables set to an empty value are kept.
+func captureEnv(names []string) map[string]string {
+	env := make(map[string]string)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			env[name] = v
+		}
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return env
+}
+
+// tagsFlag collects repeated -tag key=value flags into a map.
+type tagsFlag map[string]string
+
+func (t tagsFlag) String() string {
+	return model.CanonicalTags(t)
+}
+
+func (t tagsFlag) Set(s string) error {
+	k, v, err := model.ParseTag(s)
+	if err != nil {
+		return err
+	}
+	t[k] = v
+	return nil
+}
+
+// dedupKeyConfig builds the deduplication key from the -dedup-keys list
+// (DefaultKeyConfig if empty) minus the -dedup-ignore list. Ignoring the
+// commit SHA is refused: it would merge every commit into one entry.
+func dedupKeyConfig(keys, ignore string) (model.KeyConfig, error) {
+	cfg := model.DefaultKeyConfig
+	if keys != "" {
+		var err error
+		if cfg, err = model.ParseKeyConfig(keys); err != nil {
+			return cfg, fmt.Errorf("parsing -dedup-keys: %w", err)
+		}
+	}
+	if ignore == "" {
+		return cfg, nil
+	}
+	without, err := cfg.Without(ignore)
+	if err != nil {
+		return cfg, fmt.Errorf("parsing -dedup-ignore: %w", err)
+	}
+	if cfg.SHA && !without.SHA {
+		return cfg, errors.New("-dedup-ignore cannot drop sha: every commit would replace the previous one")
+	}
+	return without, nil
+}
+
+// ageFlag is a duration flag that also accepts whole days, e.g. "90d".
+type ageFlag time.Duration
+
+func (a *ageFlag) String() string {
+	return time.Duration(*a).String()
+}
+
+func (a *ageFlag) Set(s string) error {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid number of days %q", s)
+		}
+		*a = ageFlag(time.Duration(n) * 24 * time.Hour)
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if d < 0 {
+		return fmt.Errorf("negative age %q", s)
+	}
+	*a = ageFlag(d)
+	return nil
+}
+
+// aliasFlag collects repeated -branch-alias branch=name flags into a map.
+type aliasFlag map[string]string
+
+func (a aliasFlag) String() string {
+	return model.CanonicalTags(a)
+}
+
+func (a aliasFlag) Set(s string) error {
+	branch, name, ok := strings.Cut(s, "=")
+	branch, name = strings.TrimSpace(branch), strings.TrimSpace(name)
+	if !ok || branch == "" || name == "" {
+		return fmt.Errorf("invalid branch alias %q: expected branch=name", s)
+	}
+	a[branch] = name
+	return nil
+}
+
+// readFile adds the branch=name lines of path. Blank lines and lines
+// starting with '#' are ignored.
+func (a aliasFlag) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := a.Set(line); err != nil {
+			return fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// slowWarning lists the benchmarks of results slower than thresholdNs
+// ns/op (see model.SlowBenchmarks), or returns "" if there are none.
+func slowWarning(results []model.BenchmarkResult, thresholdNs float64) string {
+	slow := model.SlowBenchmarks(results, thresholdNs)
+	if len(slow) == 0 {
+		return ""
+	}
+	names := make([]string, len(slow))
+	for i, r := range slow {
+		names[i] = fmt.Sprintf("%s (%.2fs/op)", r.Name, r.Value/1e9)
+	}
+	return fmt.Sprintf("Warning: %d benchmark(s) slower than %.2fs/op, consider moving them out of the regular suite: %s",
+		len(slow), thresholdNs/1e9, strings.Join(names, ", "))
+}
+
+// mixedCPUs describes, per GOOS/GOARCH, the distinct CPU models among
+// entries when there is more than one, e.g.
+// `linux/amd64: "AMD EPYC 7763", "Intel Xeon"`. Results of different CPUs
+// land in the same branch timeline, so a mix usually means the matrix ran
+// on heterogeneous runners. Platforms are sorted, as are their models.
+func mixedCPUs(entries []model.BenchmarkEntry) []string {
+	byPlatform := make(map[string]map[string]struct{})
+	for _, e := range entries {
+		platform := e.Params.GOOS + "/" + e.Params.GOARCH
+		if byPlatform[platform] == nil {
+			byPlatform[platform] = make(map[string]struct{})
+		}
+		byPlatform[platform][e.Params.CPU] = struct{}{}
+	}
+
+	var out []string
+	for platform, cpus := range byPlatform {
+		if len(cpus) < 2 {
+			continue
+		}
+		models := make([]string, 0, len(cpus))
+		for cpu := range cpus {
+			models = append(models, strconv.Quote(cpu))
+		}
+		sort.Strings(models)
+		out = append(out, platform+": "+strings.Join(models, ", "))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// gzipFile is a file written through a gzip stream.
+type gzipFile struct {
+	*gzip.Writer
+	f *os.File
+}
+
+// createGzipFile creates path for writing gzip-compressed data.
+func createGzipFile(path string) (*gzipFile, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &gzipFile{Writer: gzip.NewWriter(f), f: f}, nil
+}
+
+// Close flushes the gzip stream and closes the file.
+func (g *gzipFile) Close() error {
+	return errors.Join(g.Writer.Close(), g.f.Close())
+}
+
+// openInput opens path for reading, or returns stdin when path is empty.
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// expandURLTemplate fills the placeholders of tmpl for entry stored on
+// branch in suite: {sha}, {short_sha} (7 characters), {branch} and
+// {artifact} (the artifact name parse printed for the entry's run
+// parameters).
+func expandURLTemplate(tmpl string, entry model.BenchmarkEntry, branch, suite string) string {
+	sha := entry.Commit.SHA
+	short := sha
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	return strings.NewReplacer(
+		"{sha}", sha,
+		"{short_sha}", short,
+		"{branch}", url.PathEscape(branch),
+		"{artifact}", artifactNameFromParams(entry.Params, suite),
+	).Replace(tmpl)
 }
 
 // artifactNameFromParams builds a unique, filesystem-safe artifact name
-// from the run parameters.  Example: "bench-linux-amd64-go1.24.0-cgo1"
-func artifactNameFromParams(p model.RunParams) string {
+// from the run parameters and the suite, if any.  Example:
+// "bench-linux-amd64-go1.24.0-cgo1", or "bench-db-linux-amd64-v3-go1.24.0-cgo1"
+// for suite "db" built with GOAMD64=v3.
+func artifactNameFromParams(p model.RunParams, suite string) string {
 	cgoVal := "0"
 	if p.CGO {
 		cgoVal = "1"
@@ -240,12 +918,19 @@ func artifactNameFromParams(p model.RunParams) string {
 
 	parts := []string{"bench"}
 
+	if suite != "" {
+		parts = append(parts, suite)
+	}
+
 	if p.GOOS != "" {
 		parts = append(parts, p.GOOS)
 	}
 	if p.GOARCH != "" {
 		parts = append(parts, p.GOARCH)
 	}
+	if p.MicroArch != "" {
+		parts = append(parts, p.MicroArch)
+	}
 	if p.GoVersion != "" {
 		parts = append(parts, p.GoVersion)
 	}
@@ -263,26 +948,158 @@ func runStore(args []string) {
 	fs := flag.NewFlagSet("store", flag.ExitOnError)
 
 	var (
-		entriesGlob string
-		branch      string
-		dataDir     string
-		maxItems    int
-		repoURL     string
-		goModule    string
+		entriesGlob   string
+		branch        string
+		dataDir       string
+		maxItems      int
+		maxAge        ageFlag
+		repoURL       string
+		goModule      string
+		sortBenches   bool
+		policyName    string
+		sigma         float64
+		window        int
+		threshold     float64
+		concurrency   int
+		baseRef       string
+		baseBranch    string
+		stableOnly    bool
+		force         bool
+		interval      time.Duration
+		tolerance     float64
+		baseDataDir   string
+		dedupEnv      bool
+		canonNames    bool
+		patchFile     string
+		useBrotli     bool
+		useGzip       bool
+		writeGrouped  bool
+		timeout       time.Duration
+		round         bool
+		precision     string
+		nowFlag       string
+		workloadSHAs  string
+		dedupKeys     string
+		dedupIgnore   string
+		profileTmpl   string
+		encoding      string
+		anchorEvery   int
+		aliasFile     string
+		throughput    bool
+		waitFor       string
+		waitTimeout   time.Duration
+		dropStubs     bool
+		slowNs        float64
+		procsKeep     string
+		nameNorm      string
+		transformCmd  string
+		transformTO   time.Duration
+		stubMaxNs     float64
+		verifyKeyFile string
+		requireSign   bool
+		alertPct      float64
+		strictCPU     bool
+		unitDirs      string
+		suite         string
+		summaryMD     string
+		aliases       = aliasFlag{}
 	)
 
-	fs.StringVar(&entriesGlob, "entries", "", "Glob or comma-separated paths to entry.json files (required)")
+	fs.StringVar(&entriesGlob, "entries", "", "Glob or comma-separated paths to entry.json files (one entry or a JSON array of them) or .zip/.tar.gz bundles of them; '-' reads a JSON array from stdin (required)")
 	fs.StringVar(&branch, "branch", "main", "Git branch name")
 	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory to store benchmark data and frontend files")
+	fs.StringVar(&summaryMD, "summary-markdown", "", "Append a Markdown table of the stored benchmarks, grouped by package, with the change from the previous commit to this file (e.g. \"$GITHUB_STEP_SUMMARY\")")
+	fs.StringVar(&suite, "suite", "", "Store into this benchmark suite: data/<suite>/ with its branch list in branches/<suite>.json, listed in suites.json for the dashboard (empty = the default suite's flat layout)")
 	fs.IntVar(&maxItems, "max-items", 0, "Maximum number of benchmark entries per branch (0 = unlimited)")
+	fs.Var(&maxAge, "max-age", "Drop entries whose commit date is older than this, e.g. 90d or 720h (applied before -max-items; entries with unparseable dates are kept)")
 	fs.StringVar(&repoURL, "repo-url", "", "Repository URL for the frontend header")
 	fs.StringVar(&goModule, "go-module", "", "Go module path for the frontend")
+	fs.Var(aliases, "branch-alias", "Display name for a branch in the frontend as branch=name, e.g. 'team/proj/main=main' (repeatable)")
+	fs.StringVar(&aliasFile, "branch-alias-file", "", "File of branch=name lines, like -branch-alias; '#' starts a comment")
+	fs.StringVar(&nowFlag, "now", "", "Current time in RFC 3339 used for written timestamps, for reproducible output (defaults to the system clock)")
+	fs.BoolVar(&force, "force", false, "Write into -data-dir even if it is a non-empty directory not created by this tool")
+	fs.BoolVar(&stableOnly, "releases-stable-only", false, "Exclude pre-release tags (e.g. v1.0.0-rc.1) from the aggregated releases data")
+	fs.StringVar(&patchFile, "patch-file", "", "Write a JSON Patch (RFC 6902) describing the change to the branch data file to this path")
+	fs.BoolVar(&useBrotli, "brotli", false, "Also write brotli-compressed data/<branch>.json.br files for the serve subcommand")
+	fs.BoolVar(&useGzip, "gzip", false, "Store branch data gzip-compressed as data/<branch>.json.gz, migrating existing .json files on write")
+	fs.BoolVar(&writeGrouped, "write-grouped", false, "Also write grouped/<branch>.json mapping each benchmark name to its series, one per run configuration, of [{date, sha, value, unit}] points; the dashboard loads it instead of the entries, without commit messages")
+	fs.StringVar(&encoding, "storage-encoding", "json", "Encoding of branch data files: 'json', 'ndjson' (data/<branch>.ndjson, one entry per line; new commits are appended without rewriting the file) or 'delta' (experimental: percent changes from the previous point with periodic absolute anchors)")
+	fs.IntVar(&anchorEvery, "delta-anchor-every", 50, "With -storage-encoding=delta, store an absolute value every this many points of a series")
+	fs.BoolVar(&dedupEnv, "dedup-env", false, "Keep entries that differ only in their captured environment as separate runs")
+	fs.StringVar(&dedupKeys, "dedup-keys", "", "Comma-separated dimensions identifying the same run: sha, cpu, goos, goarch, microarch, goversion, cgo, dataset, tags, env (default: all but env)")
+	fs.StringVar(&dedupIgnore, "dedup-ignore", "", "Comma-separated dimensions to leave out of the key identifying the same run, e.g. cpu on cloud runners whose CPU model varies (applied after -dedup-keys; the values are still stored)")
+	fs.StringVar(&transformCmd, "transform-cmd", "", "Shell command each entry is piped through before storing (entry JSON on stdin, transformed entry JSON on stdout)")
+	fs.DurationVar(&transformTO, "transform-timeout", 30*time.Second, "Maximum run time of -transform-cmd per entry")
+	fs.StringVar(&nameNorm, "name-normalize", model.NameNormalizeNone, "Match new benchmark names to stored ones ignoring surrounding/repeated whitespace ('trim') or also case ('fold'); the stored name is kept ('none' disables)")
+	fs.BoolVar(&canonNames, "canonicalize-names", false, "Sort key=value segments of sub-benchmark names so parameter order does not split history")
+	fs.BoolVar(&round, "round", false, "Round values to a per-unit number of decimal places (ns/op=2, B/op=0, allocs/op=0, MB/s=1)")
+	fs.StringVar(&precision, "precision", "", "Comma-separated unit=places overrides for -round, e.g. 'ns/op=3,items/op=0' (implies -round)")
+	fs.BoolVar(&throughput, "throughput-from-size", false, "Derive a bytes/sec metric (size / ns/op) for ns/op results whose name has a size=N segment, e.g. BenchmarkEncode/size=1024")
+	fs.StringVar(&procsKeep, "procs-keep", "", "Comma-separated GOMAXPROCS values to keep, e.g. '1,8'; results for other -cpu values are dropped (empty = keep all)")
+	fs.Float64Var(&slowNs, "slow-threshold-ns", model.DefaultSlowThresholdNs, "Warn about benchmarks slower than this many ns/op (0 = never)")
+	fs.BoolVar(&dropStubs, "drop-single-iteration", false, "Drop benchmarks that ran a single iteration faster than -single-iteration-max-ns (b.N-insensitive setup stubs)")
+	fs.Float64Var(&stubMaxNs, "single-iteration-max-ns", model.DefaultStubMaxNs, "ns/op below which a single-iteration benchmark counts as a stub for -drop-single-iteration")
+	fs.BoolVar(&sortBenches, "sort-benchmarks", false, "Sort each entry's benchmarks by package, name, unit and procs before writing")
+	fs.StringVar(&policyName, "policy", "", "Regression policy to check new entries against stored history: "+strings.Join(regression.PolicyNames(), ", ")+"; regressions fail the run after storing (empty = disabled)")
+	fs.IntVar(&concurrency, "store-concurrency", 2, "Maximum number of data files written in parallel: a semver tag's file and the releases aggregate (1 = one after the other)")
+	fs.Float64Var(&threshold, "threshold", 10, "Regression policy threshold (percent for 'percent' and the short-history fallback of 'sigma', sigmas for 'stddev', value delta for 'absolute')")
+	fs.Float64Var(&sigma, "sigma", regression.DefaultSigma, "Standard deviations above the recent mean that count as a regression for -policy=sigma")
+	fs.IntVar(&window, "history-window", regression.DefaultWindow, "Number of most recent comparable points -policy=sigma derives the tolerance from")
+	fs.StringVar(&baseRef, "base-ref", "", "Git ref whose merge-base with the stored commit is used as the regression baseline (e.g. origin/main)")
+	fs.StringVar(&baseBranch, "base-branch", "", "Branch whose stored data holds the regression baseline (defaults to -branch)")
+	fs.StringVar(&workloadSHAs, "workload-change-sha", "", "Comma-separated commit SHAs whose benchmark workload changed on purpose; their regressions are suppressed and marked as step changes")
+	fs.StringVar(&profileTmpl, "profile-url-template", "", "URL template for each entry's pprof profile; placeholders: {sha}, {short_sha}, {branch}, {artifact}")
+	fs.StringVar(&baseDataDir, "base-data-dir", "", "Directory of a separate store holding the regression baseline (defaults to -data-dir)")
+	fs.DurationVar(&interval, "store-interval", 0, "Skip entries dated within this interval of the previous comparable entry unless values changed (0 = always store)")
+	fs.Float64Var(&tolerance, "store-tolerance", 1, "Percent change of any value that counts as changed for -store-interval")
+
+	fs.StringVar(&waitFor, "wait-for", "", "Before storing, wait until <glob>:<count> entry files exist, e.g. 'results/*/entry.json:6' (guards against artifacts still uploading)")
+	fs.DurationVar(&waitTimeout, "wait-timeout", 5*time.Minute, "Maximum time to wait for -wait-for")
+	fs.DurationVar(&timeout, "timeout", 0, "Abort writing to storage once the whole store run exceeds this duration, e.g. on a hung filesystem (0 = no limit)")
+	fs.StringVar(&verifyKeyFile, "verify-key-file", "", "File holding the HMAC key parse signed entries with; entries with a wrong signature are rejected ($"+verifyKeyEnv+" is used if unset, no verification if both are empty)")
+	fs.BoolVar(&requireSign, "require-signed", false, "With a verification key, also reject unsigned entries")
+	fs.StringVar(&unitDirs, "unit-direction", "", "Comma-separated unit=higher|lower directions of custom units for -policy and -alert-threshold, e.g. 'hits/op=higher' (ns/op, B/op, allocs/op and */s are built in)")
+	fs.BoolVar(&strictCPU, "strict-cpu", false, "Fail instead of warning when the loaded entries of one GOOS/GOARCH report different CPU models")
+	fs.Float64Var(&alertPct, "alert-threshold", 0, "Exit non-zero after storing if a benchmark got worse than the regression baseline by more than this percent (slower for ns/op, lower for MB/s); a gate of the percent policy with this threshold, checked next to -policy and honouring -base-ref, pins, annotations and suppressions alike (0 = disabled)")
 
 	fs.Parse(args)
 
 	if entriesGlob == "" {
 		log.Fatal("Error: -entries is required")
 	}
+	verifyKey, err := readKey(verifyKeyFile, verifyKeyEnv)
+	if err != nil {
+		log.Fatalf("Error reading -verify-key-file: %v", err)
+	}
+	if requireSign && len(verifyKey) == 0 {
+		log.Fatal("Error: -require-signed needs -verify-key-file or $" + verifyKeyEnv)
+	}
+	if patchFile == "-" {
+		log.Fatal("Error: -patch-file needs a file path; store's progress output goes to stdout")
+	}
+	if err := registerUnitDirections(unitDirs); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if waitFor != "" {
+		i := strings.LastIndex(waitFor, ":")
+		want, err := strconv.Atoi(waitFor[i+1:])
+		if i < 0 || err != nil || want < 1 {
+			log.Fatalf("Error: invalid -wait-for %q: expected <glob>:<count>", waitFor)
+		}
+		found, err := waitForEntries(waitFor[:i], want, waitTimeout)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		fmt.Printf("All %d expected entry file(s) present (%d found)\n", want, len(found))
+	}
 
 	// Detect Go module if not provided.
 	if goModule == "" {
@@ -305,27 +1122,245 @@ func runStore(args []string) {
 		fmt.Printf("  %s\n", f)
 	}
 
+	var unitPrecision map[string]int
+	if round || precision != "" {
+		var err error
+		if unitPrecision, err = parsePrecision(precision); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	var keepProcs []int
+	for _, p := range strings.Split(procsKeep, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 {
+			log.Fatalf("Error: invalid -procs-keep value %q", p)
+		}
+		keepProcs = append(keepProcs, n)
+	}
+
+	switch nameNorm {
+	case model.NameNormalizeNone, model.NameNormalizeTrim, model.NameNormalizeFold:
+	default:
+		log.Fatalf("Error: unknown -name-normalize %q (want none, trim or fold)", nameNorm)
+	}
+
+	derive := storage.RecomputeOptions{
+		CanonicalizeNames:  canonNames,
+		SortBenchmarks:     sortBenches,
+		Precision:          unitPrecision,
+		ThroughputFromSize: throughput,
+	}
+
 	// Load all entries.
 	var entries []model.BenchmarkEntry
 	for _, path := range entryFiles {
-		entry, err := loadEntry(path)
+		loaded, err := loadEntries(path)
 		if err != nil {
 			log.Fatalf("Error loading entry from %s: %v", path, err)
 		}
-		fmt.Printf("Loaded entry from %s: CPU=%s GOOS=%s GOARCH=%s GoVersion=%s CGO=%v benchmarks=%d\n",
-			path, entry.Params.CPU, entry.Params.GOOS, entry.Params.GOARCH, entry.Params.GoVersion, entry.Params.CGO, len(entry.Benchmarks))
-		entries = append(entries, entry)
+		for _, le := range loaded {
+			entry := le.BenchmarkEntry
+			if len(verifyKey) > 0 {
+				if entry.Signature == "" && !requireSign {
+					fmt.Printf("Warning: entry from %s is not signed\n", path)
+				}
+				if err := checkEntrySignature(&entry, le.raw, verifyKey, requireSign); err != nil {
+					log.Fatalf("Error verifying entry from %s: %v", path, err)
+				}
+			}
+			if transformCmd != "" {
+				if entry, err = transformEntry(transformCmd, entry, transformTO); err != nil {
+					log.Fatalf("Error transforming entry from %s: %v", path, err)
+				}
+			}
+			fmt.Printf("Loaded entry from %s: CPU=%s GOOS=%s GOARCH=%s GoVersion=%s CGO=%v benchmarks=%d\n",
+				path, entry.Params.CPU, entry.Params.GOOS, entry.Params.GOARCH, entry.Params.GoVersion, entry.Params.CGO, len(entry.Benchmarks))
+			entry.Benchmarks = model.FilterProcs(entry.Benchmarks, keepProcs)
+			if dropStubs {
+				var dropped []string
+				entry.Benchmarks, dropped = model.DropSingleIteration(entry.Benchmarks, stubMaxNs)
+				if len(dropped) > 0 {
+					fmt.Printf("Warning: dropped %d single-iteration benchmark(s): %s\n", len(dropped), strings.Join(dropped, ", "))
+				}
+			}
+			if w := slowWarning(entry.Benchmarks, slowNs); w != "" {
+				fmt.Println(w)
+			}
+			derive.Apply(&entry)
+			if profileTmpl != "" {
+				entry.ProfileURL = expandURLTemplate(profileTmpl, entry, branch, suite)
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	// Different CPUs of one platform would share a timeline.
+	for _, mix := range mixedCPUs(entries) {
+		if strictCPU {
+			log.Fatalf("Error: entries report different CPU models for %s", mix)
+		}
+		fmt.Printf("Warning: entries report different CPU models for %s; their results are not comparable\n", mix)
+	}
+
+	// Storage options.
+	var storeOpts []storage.Option
+	if nowFlag != "" {
+		now, err := time.Parse(time.RFC3339, nowFlag)
+		if err != nil {
+			log.Fatalf("Error parsing -now %q: %v", nowFlag, err)
+		}
+		storeOpts = append(storeOpts, storage.WithClock(func() time.Time { return now }))
+	}
+	if force {
+		storeOpts = append(storeOpts, storage.WithForce())
+	}
+	if stableOnly {
+		storeOpts = append(storeOpts, storage.WithStableReleasesOnly())
+	}
+	if dedupEnv {
+		storeOpts = append(storeOpts, storage.WithEnvDedup())
+	}
+	if dedupKeys != "" || dedupIgnore != "" {
+		cfg, err := dedupKeyConfig(dedupKeys, dedupIgnore)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		storeOpts = append(storeOpts, storage.WithKeyConfig(cfg))
+	}
+	if suite != "" {
+		storeOpts = append(storeOpts, storage.WithSuite(suite))
+	}
+	if useBrotli && useGzip {
+		log.Fatal("Error: -brotli and -gzip are mutually exclusive")
+	}
+	if useBrotli {
+		storeOpts = append(storeOpts, storage.WithBrotli())
+	}
+	if useGzip {
+		storeOpts = append(storeOpts, storage.WithGzip())
+	}
+	switch encoding {
+	case "json":
+	case "ndjson":
+		if useGzip {
+			log.Fatal("Error: -gzip cannot be combined with -storage-encoding=ndjson")
+		}
+		storeOpts = append(storeOpts, storage.WithNDJSON())
+	case storage.EncodingDelta:
+		storeOpts = append(storeOpts, storage.WithDeltaEncoding(anchorEvery))
+	default:
+		log.Fatalf("Error: unknown -storage-encoding %q (want json, ndjson or delta)", encoding)
+	}
+	// The library opens the storage; keep hold of it for the steps below
+	// that only the command performs.
+	var store *storage.Storage
+	storeOpts = append(storeOpts, func(s *storage.Storage) { store = s })
+
+	if aliasFile != "" {
+		if err := aliases.readFile(aliasFile); err != nil {
+			log.Fatalf("Error reading -branch-alias-file: %v", err)
+		}
+	}
+
+	// Check new entries against the stored history before merging them in.
+	// -alert-threshold is a percent policy gate next to -policy; both share
+	// the baseline, annotations, suppressions and pins, and their
+	// regressions fail the run once stored.
+	type gate struct {
+		name   string
+		policy regression.Policy
+	}
+	var gates []gate
+	if policyName != "" {
+		policy, err := policyFromFlags(policyName, threshold, sigma, window)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		gates = append(gates, gate{policyName, policy})
+	}
+	if alertPct > 0 {
+		gates = append(gates, gate{"alert-threshold", regression.PercentPolicy{Threshold: alertPct}})
+	}
+	regressions := 0
+	var check func(stored, entries []model.BenchmarkEntry) error
+	if len(gates) > 0 {
+		if baseBranch == "" {
+			baseBranch = branch
+		}
+		check = func(_, entries []model.BenchmarkEntry) error {
+			baseStore := store
+			if baseDataDir != "" {
+				var err error
+				if baseStore, err = storage.New(baseDataDir, storage.WithSuite(suite)); err != nil {
+					return fmt.Errorf("initializing baseline storage: %w", err)
+				}
+			}
+			existing, err := loadBaseline(baseStore, baseBranch, baseRef, entries[0].Commit.SHA)
+			if err != nil {
+				return fmt.Errorf("reading baseline data: %w", err)
+			}
+			checks, err := loadRegressionChecks(store)
+			if err != nil {
+				return err
+			}
+			for _, u := range unknownDirections(entries) {
+				fmt.Printf("Warning: unit %q has no known direction; treating lower as better (see -unit-direction)\n", u)
+			}
+			for _, g := range gates {
+				regressions += reportRegressions(g.name, g.policy, existing, entries, checks)
+			}
+			return nil
+		}
+	}
+
+	var workloadChanges []string
+	for _, sha := range strings.Split(workloadSHAs, ",") {
+		if sha = strings.TrimSpace(sha); sha != "" {
+			workloadChanges = append(workloadChanges, sha)
+		}
 	}
 
-	// Initialize storage.
-	store, err := storage.New(dataDir)
+	// Append all entries in a single batch and update the metadata and
+	// manifest around them.
+	details, err := gobenchdata.StoreDetailed(ctx, gobenchdata.StoreConfig{
+		DataDir:         dataDir,
+		Branch:          branch,
+		Entries:         entries,
+		MaxItems:        maxItems,
+		MaxAge:          time.Duration(maxAge),
+		Concurrency:     concurrency,
+		Options:         storeOpts,
+		RepoURL:         repoURL,
+		GoModule:        goModule,
+		BranchAliases:   aliases,
+		WorkloadChanges: workloadChanges,
+		NameNormalize:   nameNorm,
+		Check:           check,
+		StoreInterval:   interval,
+		StoreTolerance:  tolerance,
+		WriteGrouped:    writeGrouped,
+	})
 	if err != nil {
-		log.Fatalf("Error initializing storage: %v", err)
+		log.Fatalf("Error %v", err)
 	}
+	for _, sha := range workloadChanges {
+		fmt.Printf("Annotated %s as a known workload change\n", sha)
+	}
+	for _, e := range details.Skipped {
+		prev := model.BranchData(details.Previous).PreviousComparable(e)
+		fmt.Printf("Skipping entry %s (%s/%s): unchanged within %s of %s\n",
+			e.Commit.SHA, e.Params.GOOS, e.Params.GOARCH, interval, prev.Commit.SHA)
+	}
+	entries = details.Stored
 
-	// Append all entries in a single batch.
-	if err := store.AppendEntries(branch, entries, maxItems); err != nil {
-		log.Fatalf("Error appending entries: %v", err)
+	if patchFile != "" {
+		if err := writeBranchPatch(ctx, store, branch, details.Previous, patchFile); err != nil {
+			log.Fatalf("Error writing patch: %v", err)
+		}
 	}
 
 	commitSHA := ""
@@ -339,25 +1374,277 @@ func runStore(args []string) {
 
 	fmt.Printf("Stored %d entry/entries for branch %q (commit %s)\n", len(entries), branch, shortSHA)
 
-	// Write repo-level metadata for the frontend.
-	if repoURL != "" || goModule != "" {
-		if err := store.WriteMetadata(repoURL, goModule); err != nil {
-			log.Fatalf("Error writing metadata: %v", err)
+	if summaryMD != "" {
+		if err := appendJobSummary(store, branch, entries, summaryMD); err != nil {
+			log.Fatalf("Error writing Markdown summary: %v", err)
 		}
 	}
 
 	// Deploy frontend static files.
-	if err := deployFrontend(dataDir); err != nil {
+	written, err := deployFrontend(ctx, store, dataDir)
+	if err != nil {
 		log.Fatalf("Error deploying frontend: %v", err)
 	}
 
-	fmt.Println("Frontend files deployed successfully")
+	if written > 0 {
+		fmt.Printf("Frontend files deployed successfully (%d updated)\n", written)
+	} else {
+		fmt.Println("Frontend files already up to date")
+	}
+
+	if regressions > 0 {
+		log.Fatalf("Error: %d benchmark regression(s) detected", regressions)
+	}
 }
 
 // ---------------------------------------------------------------------------
 // Helpers
 // ---------------------------------------------------------------------------
 
+// policyFromFlags returns the named regression policy with the -threshold,
+// -sigma and -history-window flags applied.
+func policyFromFlags(name string, threshold, sigma float64, window int) (regression.Policy, error) {
+	policy, err := regression.ByName(name, threshold)
+	if err != nil {
+		return nil, err
+	}
+	if sp, ok := policy.(regression.SigmaPolicy); ok {
+		sp.K, sp.Window = sigma, window
+		policy = sp
+	}
+	return policy, nil
+}
+
+// regressionChecks holds the stored state every regression check of store
+// and export honours: known workload changes (commits annotated with
+// storage.AnnotationWorkloadChange), suppression windows and pinned
+// baselines.
+type regressionChecks struct {
+	stepChanges map[string]struct{}
+	windows     []storage.Suppression
+	pins        map[string]string
+}
+
+// loadRegressionChecks reads the regressionChecks of store.
+func loadRegressionChecks(store *storage.Storage) (regressionChecks, error) {
+	var c regressionChecks
+	annotations, err := store.ReadAnnotations()
+	if err != nil {
+		return c, fmt.Errorf("reading annotations: %w", err)
+	}
+	c.stepChanges = storage.AnnotatedSHAs(annotations, storage.AnnotationWorkloadChange)
+	if c.windows, err = store.ReadSuppressions(); err != nil {
+		return c, fmt.Errorf("reading suppressions: %w", err)
+	}
+	if c.pins, err = store.ReadPinnedBaselines(); err != nil {
+		return c, fmt.Errorf("reading pinned baselines: %w", err)
+	}
+	return c, nil
+}
+
+// check checks entry against existing with policy. Benchmarks matching a
+// pin are compared against the pinned commit instead of the previous one.
+// Regressions of known workload changes or inside a suppression window are
+// marked suppressed.
+func (c regressionChecks) check(policy regression.Policy, existing model.BranchData, entry model.BenchmarkEntry) []regression.Result {
+	results := regression.CheckEntryPinned(policy, existing, entry, c.pins)
+	regression.Suppress(results, c.stepChanges)
+	regression.SuppressMatching(results, func(r regression.Result) (string, bool) {
+		return suppressedBy(c.windows, r)
+	})
+	return results
+}
+
+// reportRegressions checks each entry against existing with policy and
+// checks, and prints every benchmark that regressed. Suppressed
+// regressions are reported as such and not counted. It returns the number
+// of regressions found.
+func reportRegressions(policyName string, policy regression.Policy, existing model.BranchData, entries []model.BenchmarkEntry, checks regressionChecks) int {
+	count := 0
+	for _, entry := range entries {
+		results := checks.check(policy, existing, entry)
+		for _, r := range results {
+			if r.Suppressed {
+				fmt.Printf("Suppressed (%s) in %s: %.4f -> %.4f %s: %s (%s)\n",
+					policyName, r.Series.Name, r.Previous.Value, r.Current.Value, r.Series.Unit, r.Message, r.SuppressReason)
+				continue
+			}
+			if !r.Regressed {
+				continue
+			}
+			count++
+			pinned := ""
+			if r.Pinned {
+				pinned = " vs pinned " + r.Previous.SHA
+			}
+			fmt.Printf("Regression (%s) in %s [%s/%s %s procs=%d]: %.4f -> %.4f %s%s: %s\n",
+				policyName, r.Series.Name, entry.Params.GOOS, entry.Params.GOARCH, entry.Params.GoVersion, r.Series.Procs,
+				r.Previous.Value, r.Current.Value, r.Series.Unit, pinned, r.Message)
+		}
+	}
+	if count == 0 {
+		fmt.Printf("No regressions detected (%s policy)\n", policyName)
+	}
+	return count
+}
+
+// unknownDirections returns the units of entries' results that have no
+// known direction, sorted, so the regression checks can warn about them.
+func unknownDirections(entries []model.BenchmarkEntry) []string {
+	seen := make(map[string]struct{})
+	for _, e := range entries {
+		for _, r := range e.Benchmarks {
+			if model.UnitDirection(r.Unit) == model.DirectionUnknown {
+				seen[r.Unit] = struct{}{}
+			}
+		}
+	}
+	units := make([]string, 0, len(seen))
+	for u := range seen {
+		units = append(units, u)
+	}
+	sort.Strings(units)
+	return units
+}
+
+// suppressedBy returns the reason of the first window muting r, judged by
+// the date of r's current point.
+func suppressedBy(windows []storage.Suppression, r regression.Result) (string, bool) {
+	date := time.UnixMilli(r.Current.Date)
+	result := model.BenchmarkResult{Name: r.Series.Name, Unit: r.Series.Unit}
+	for _, w := range windows {
+		if w.Matches(date, result) {
+			reason := "suppression window"
+			if w.Reason != "" {
+				reason += ": " + w.Reason
+			}
+			return reason, true
+		}
+	}
+	return "", false
+}
+
+// maxBaseAncestors bounds how far back mergeBaseHistory walks the first-parent
+// history of the merge-base looking for a benchmarked commit.
+const maxBaseAncestors = 1000
+
+// parsePrecision merges comma-separated unit=places overrides into a copy of
+// model.DefaultPrecision.
+func parsePrecision(s string) (map[string]int, error) {
+	precision := make(map[string]int, len(model.DefaultPrecision))
+	for unit, places := range model.DefaultPrecision {
+		precision[unit] = places
+	}
+	if s == "" {
+		return precision, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		unit, places, ok := strings.Cut(strings.TrimSpace(part), "=")
+		n, err := strconv.Atoi(places)
+		if !ok || unit == "" || err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid precision %q: expected unit=places", part)
+		}
+		precision[unit] = n
+	}
+	return precision, nil
+}
+
+// writeBranchPatch writes the JSON Patch from before to the current data of
+// branch to path, bounded by ctx.
+func writeBranchPatch(ctx context.Context, store *storage.Storage, branch string, before model.BranchData, path string) error {
+	after, err := store.ReadBranchData(branch)
+	if err != nil {
+		return err
+	}
+	ops := store.DiffBranch(before, after)
+	if ops == nil {
+		ops = []storage.PatchOp{}
+	}
+	data, err := json.MarshalIndent(ops, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding patch: %w", err)
+	}
+	data = append(data, '\n')
+	_, err = store.WriteFileContext(ctx, path, data)
+	return err
+}
+
+// appendJobSummary appends a report.JobSummary of every entry stored on
+// branch to path. The file is appended to rather than replaced, as
+// $GITHUB_STEP_SUMMARY is shared by all steps of a job.
+func appendJobSummary(store *storage.Storage, branch string, entries []model.BenchmarkEntry, path string) error {
+	var sb strings.Builder
+	for _, entry := range entries {
+		prev, err := store.PreviousEntry(branch, entry)
+		if err != nil {
+			return err
+		}
+		sb.WriteString(report.JobSummary(branch, entry, prev))
+		sb.WriteByte('\n')
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(sb.String()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadBaseline reads the regression baseline for sha from baseStore, which
+// may differ from the store new entries are written to. With a non-empty
+// baseRef the history is cut at the merge-base (see mergeBaseHistory).
+func loadBaseline(baseStore *storage.Storage, baseBranch, baseRef, sha string) (model.BranchData, error) {
+	if baseRef != "" {
+		return mergeBaseHistory(baseStore, baseBranch, baseRef, sha)
+	}
+	return baseStore.ReadBranchData(baseBranch)
+}
+
+// mergeBaseHistory resolves the merge-base of baseRef and sha, finds the
+// nearest benchmarked ancestor of it in branch and returns branch's data up
+// to and including that commit, so that it becomes the regression baseline.
+// It returns nil data if no ancestor was benchmarked.
+func mergeBaseHistory(store *storage.Storage, branch, baseRef, sha string) (model.BranchData, error) {
+	mergeBase, err := gitinfo.MergeBase(".", baseRef, sha)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := gitinfo.Ancestors(".", mergeBase, maxBaseAncestors)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := store.NearestAncestor(branch, ancestors)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		fmt.Printf("Warning: no benchmarked ancestor of merge-base %s found in branch %q\n", mergeBase, branch)
+		return nil, nil
+	}
+	if base.Commit.SHA != mergeBase {
+		fmt.Printf("Merge-base %s was not benchmarked, using nearest ancestor %s\n", mergeBase, base.Commit.SHA)
+	} else {
+		fmt.Printf("Using merge-base %s as regression baseline\n", mergeBase)
+	}
+
+	data, err := store.ReadBranchData(branch)
+	if err != nil {
+		return nil, err
+	}
+	end := 0
+	for i, e := range data {
+		if e.Commit.SHA == base.Commit.SHA {
+			end = i + 1
+		}
+	}
+	return data[:end], nil
+}
+
 // loadEntry reads a BenchmarkEntry from a JSON file.
 func loadEntry(path string) (model.BenchmarkEntry, error) {
 	data, err := os.ReadFile(path)
@@ -371,6 +1658,28 @@ func loadEntry(path string) (model.BenchmarkEntry, error) {
 	return entry, nil
 }
 
+// waitPollInterval is how often waitForEntries re-checks the glob.
+var waitPollInterval = time.Second
+
+// waitForEntries polls glob until at least want files match it and returns
+// the matches. It fails once timeout has passed without enough matches.
+func waitForEntries(glob string, want int, timeout time.Duration) ([]string, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			return nil, fmt.Errorf("matching %q: %w", glob, err)
+		}
+		if len(matches) >= want {
+			return matches, nil
+		}
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("timed out after %v waiting for %d entry file(s) matching %q: found %d", timeout, want, glob, len(matches))
+		}
+		time.Sleep(min(waitPollInterval, time.Until(deadline)))
+	}
+}
+
 // resolveFiles expands a raw string (comma-separated, newline-separated,
 // with optional glob patterns) into a list of file paths.
 func resolveFiles(raw string) []string {
@@ -406,27 +1715,27 @@ func resolveFiles(raw string) []string {
 }
 
 // deployFrontend copies the embedded frontend files into the data directory.
-func deployFrontend(dataDir string) error {
+// Files whose content is already up to date are left untouched. The writes
+// go through store and are bounded by ctx. It returns the number of files
+// written.
+func deployFrontend(ctx context.Context, store *storage.Storage, dataDir string) (int, error) {
 	names := []string{"index.html", "app.js"}
+	written := 0
 	for _, name := range names {
 		content, err := frontendFS.ReadFile("frontend/" + name)
 		if err != nil {
-			return fmt.Errorf("reading embedded file %s: %w", name, err)
+			return written, fmt.Errorf("reading embedded file %s: %w", name, err)
 		}
 		dest := filepath.Join(dataDir, name)
-		if err := os.WriteFile(dest, content, 0o644); err != nil {
-			return fmt.Errorf("writing %s: %w", dest, err)
+		changed, err := store.WriteFileContext(ctx, dest, content)
+		if err != nil {
+			return written, fmt.Errorf("writing %s: %w", dest, err)
+		}
+		if changed {
+			written++
 		}
 	}
-	return nil
-}
-
-// firstLine returns the first line of s.
-func firstLine(s string) string {
-	if i := strings.IndexByte(s, '\n'); i >= 0 {
-		return s[:i]
-	}
-	return s
+	return written, nil
 }
 
 // detectGoModule tries to find the Go module path from go.mod or the repo URL.
@@ -465,23 +1774,3 @@ func parseGoMod(path string) string {
 	}
 	return ""
 }
-
-// detectCGO determines CGO enabled status.
-// Explicit flag value > CGO_ENABLED env var > default true.
-func detectCGO(flagVal string) bool {
-	flagVal = strings.TrimSpace(strings.ToLower(flagVal))
-	switch flagVal {
-	case "true", "1":
-		return true
-	case "false", "0":
-		return false
-	default:
-		env := os.Getenv("CGO_ENABLED")
-		switch strings.TrimSpace(env) {
-		case "0":
-			return false
-		default:
-			return true
-		}
-	}
-}
@@ -0,0 +1,1028 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/compare"
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/parse"
+	"github.com/royalcat/go-continuous-benchmarking/internal/regression"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+func TestDeployFrontend_SecondDeployWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.New(dir)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	written, err := deployFrontend(context.Background(), store, dir)
+	if err != nil {
+		t.Fatalf("first deployFrontend() error: %v", err)
+	}
+	if written != 2 {
+		t.Errorf("first deploy wrote %d files, want 2", written)
+	}
+
+	written, err = deployFrontend(context.Background(), store, dir)
+	if err != nil {
+		t.Fatalf("second deployFrontend() error: %v", err)
+	}
+	if written != 0 {
+		t.Errorf("second deploy wrote %d files, want 0", written)
+	}
+}
+
+func TestCheckOutput_Valid(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	input := "pkg: github.com/user/repo\ncpu: Test CPU\nBenchmarkFoo-8  1000  1234 ns/op  16 B/op\nPASS\n"
+	var out strings.Builder
+	if err := checkOutput(strings.NewReader(input), &out); err != nil {
+		t.Fatalf("checkOutput() error: %v", err)
+	}
+	if !strings.Contains(out.String(), "OK: 2 result(s)") {
+		t.Errorf("unexpected report: %q", out.String())
+	}
+	assertDirEmpty(t, ".")
+}
+
+func TestCheckOutput_Malformed(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	input := "pkg: github.com/user/repo\nBenchmarkFoo-8  garbage\nFAIL\n"
+	var out strings.Builder
+	if err := checkOutput(strings.NewReader(input), &out); err == nil {
+		t.Fatal("expected an error for output without benchmark results")
+	}
+	assertDirEmpty(t, ".")
+}
+
+func assertDirEmpty(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no files to be created, found %d", len(entries))
+	}
+}
+
+func TestLoadBaseline_SeparateStore(t *testing.T) {
+	baseStore, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := model.RunParams{GOOS: "linux", GOARCH: "amd64"}
+	history := []model.BenchmarkEntry{{
+		Commit:     model.Commit{SHA: "aaa"},
+		Date:       1000,
+		Params:     params,
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 100, Unit: "ns/op"}},
+	}}
+	if err := baseStore.AppendEntries("main", history, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	existing, err := loadBaseline(baseStore, "main", "", "bbb")
+	if err != nil {
+		t.Fatalf("loadBaseline() error: %v", err)
+	}
+	if len(existing) != 1 || existing[0].Commit.SHA != "aaa" {
+		t.Fatalf("expected baseline history from the base store, got %+v", existing)
+	}
+
+	entry := model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: "bbb"},
+		Date:       2000,
+		Params:     params,
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 150, Unit: "ns/op"}},
+	}
+	if n := reportRegressions("percent", regression.PercentPolicy{Threshold: 10}, existing, []model.BenchmarkEntry{entry}, regressionChecks{}); n != 1 {
+		t.Errorf("regressions: got %d, want 1", n)
+	}
+
+	// The target store is untouched by reading the baseline.
+	data, err := target.ReadBranchData("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 0 {
+		t.Errorf("target store should start empty, got %d entries", len(data))
+	}
+}
+
+func TestCaptureEnv(t *testing.T) {
+	t.Setenv("GOBENCH_TEST_INSTANCE_TYPE", "c5.large")
+	t.Setenv("GOBENCH_TEST_REGION", "eu-west-1")
+	t.Setenv("GOBENCH_TEST_EMPTY", "")
+
+	got := captureEnv([]string{"GOBENCH_TEST_INSTANCE_TYPE", " GOBENCH_TEST_REGION", "GOBENCH_TEST_EMPTY", "GOBENCH_TEST_UNSET", ""})
+	want := map[string]string{
+		"GOBENCH_TEST_INSTANCE_TYPE": "c5.large",
+		"GOBENCH_TEST_REGION":        "eu-west-1",
+		"GOBENCH_TEST_EMPTY":         "",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: got %q, want %q", k, got[k], v)
+		}
+	}
+
+	if captureEnv([]string{"GOBENCH_TEST_UNSET"}) != nil {
+		t.Error("expected nil when no variable is set")
+	}
+}
+
+func TestAliasFlag_ReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aliases.txt")
+	content := "# display names\nteam/proj/main = main\n\nrelease/2.x=Release 2\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := aliasFlag{"dev": "develop"}
+	if err := a.readFile(path); err != nil {
+		t.Fatalf("readFile() error: %v", err)
+	}
+	want := map[string]string{"dev": "develop", "team/proj/main": "main", "release/2.x": "Release 2"}
+	if len(a) != len(want) {
+		t.Fatalf("got %v, want %v", a, want)
+	}
+	for k, v := range want {
+		if a[k] != v {
+			t.Errorf("%s: got %q, want %q", k, a[k], v)
+		}
+	}
+
+	if err := a.Set("no-separator"); err == nil {
+		t.Error("expected error for alias without '='")
+	}
+}
+
+func TestDedupKeyConfig(t *testing.T) {
+	cfg, err := dedupKeyConfig("", "cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := model.DefaultKeyConfig
+	want.CPU = false
+	if cfg != want {
+		t.Errorf("ignore cpu: got %+v, want %+v", cfg, want)
+	}
+
+	cfg, err = dedupKeyConfig("sha,cpu,goversion", "goversion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (model.KeyConfig{SHA: true, CPU: true}); cfg != want {
+		t.Errorf("keys minus goversion: got %+v, want %+v", cfg, want)
+	}
+
+	if _, err := dedupKeyConfig("", "sha"); err == nil {
+		t.Error("ignoring sha: want an error")
+	}
+	if _, err := dedupKeyConfig("", "hostname"); err == nil {
+		t.Error("unknown dimension: want an error")
+	}
+}
+
+func TestAgeFlag(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"90d", 90 * 24 * time.Hour, true},
+		{"0d", 0, true},
+		{"720h", 720 * time.Hour, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"-1d", 0, false},
+		{"-5h", 0, false},
+		{"1.5d", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		var a ageFlag
+		err := a.Set(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("Set(%q) error = %v, want ok=%v", tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && time.Duration(a) != tt.want {
+			t.Errorf("Set(%q) = %v, want %v", tt.in, time.Duration(a), tt.want)
+		}
+	}
+}
+
+func TestPrintKey(t *testing.T) {
+	entry, err := loadEntry("testdata/entry.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	printKey(&out, entry, "feature/x", model.DefaultKeyConfig)
+
+	want := `key:  sha=abc123 cpu="Intel Xeon" goos=linux goarch=amd64 go=go1.22.0 cgo=true tags="gc=off"` + "\n" +
+		"file: data/feature_x.json\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestDataHandler_RangeRequest(t *testing.T) {
+	dir := t.TempDir()
+	content := `[{"commit":"a"},{"commit":"b"}]`
+	if err := os.WriteFile(filepath.Join(dir, "releases.json"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	handler, err := dataHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/releases.json", nil)
+	req.Header.Set("Range", "bytes=-16")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("status: got %d, want %d", rec.Code, http.StatusPartialContent)
+	}
+	if got := rec.Body.String(); got != content[len(content)-16:] {
+		t.Errorf("body: got %q, want %q", got, content[len(content)-16:])
+	}
+	if got := rec.Header().Get("Accept-Ranges"); got != "bytes" {
+		t.Errorf("Accept-Ranges: got %q", got)
+	}
+	wantRange := fmt.Sprintf("bytes %d-%d/%d", len(content)-16, len(content)-1, len(content))
+	if got := rec.Header().Get("Content-Range"); got != wantRange {
+		t.Errorf("Content-Range: got %q, want %q", got, wantRange)
+	}
+}
+
+func TestDataHandler_FullAndMissing(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	handler, err := dataHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/", http.StatusOK},
+		{"/missing.json", http.StatusNotFound},
+		{"/../etc/passwd", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.code {
+			t.Errorf("%s: got %d, want %d", tt.path, rec.Code, tt.code)
+		}
+	}
+}
+
+func TestDataHandler_EncodingNegotiation(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"main.json":    "identity",
+		"main.json.br": "brotli",
+		"main.json.gz": "gzip",
+		"other.json":   "identity",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler, err := dataHandler(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path, accept string
+		wantBody     string
+		wantEncoding string
+	}{
+		{"/main.json", "gzip, deflate, br", "brotli", "br"},
+		{"/main.json", "gzip", "gzip", "gzip"},
+		{"/main.json", "br;q=0, gzip", "gzip", "gzip"},
+		{"/main.json", "*", "brotli", "br"},
+		{"/main.json", "", "identity", ""},
+		{"/other.json", "br, gzip", "identity", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept-Encoding", tt.accept)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := rec.Body.String(); got != tt.wantBody {
+			t.Errorf("%s (Accept-Encoding %q): body %q, want %q", tt.path, tt.accept, got, tt.wantBody)
+		}
+		if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+			t.Errorf("%s (Accept-Encoding %q): Content-Encoding %q, want %q", tt.path, tt.accept, got, tt.wantEncoding)
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("%s: Content-Type %q", tt.path, got)
+		}
+	}
+}
+
+func TestParsePrecision(t *testing.T) {
+	got, err := parsePrecision("ns/op=3, items/op=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["ns/op"] != 3 || got["items/op"] != 0 || got["MB/s"] != 1 {
+		t.Errorf("unexpected precision map: %v", got)
+	}
+	if model.DefaultPrecision["ns/op"] != 2 {
+		t.Error("parsePrecision must not modify the defaults")
+	}
+
+	for _, bad := range []string{"ns/op", "ns/op=x", "=2", "ns/op=-1"} {
+		if _, err := parsePrecision(bad); err == nil {
+			t.Errorf("parsePrecision(%q): expected error", bad)
+		}
+	}
+}
+
+func TestReportRegressions_WorkloadChangeSuppressed(t *testing.T) {
+	params := model.RunParams{GOOS: "linux", GOARCH: "amd64"}
+	existing := model.BranchData{{
+		Commit:     model.Commit{SHA: "aaa"},
+		Date:       1000,
+		Params:     params,
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 100, Unit: "ns/op"}},
+	}}
+	step := model.BenchmarkEntry{
+		Commit:     model.Commit{SHA: "bbb"},
+		Date:       2000,
+		Params:     params,
+		Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 100000, Unit: "ns/op"}},
+	}
+	policy := regression.PercentPolicy{Threshold: 10}
+
+	annotated := map[string]struct{}{"bbb": {}}
+	if n := reportRegressions("percent", policy, existing, []model.BenchmarkEntry{step}, regressionChecks{stepChanges: annotated}); n != 0 {
+		t.Errorf("annotated commit: got %d regressions, want 0", n)
+	}
+
+	unrelated := map[string]struct{}{"ccc": {}}
+	if n := reportRegressions("percent", policy, existing, []model.BenchmarkEntry{step}, regressionChecks{stepChanges: unrelated}); n != 1 {
+		t.Errorf("unannotated commit: got %d regressions, want 1", n)
+	}
+}
+
+func TestReportRegressions_SuppressionWindow(t *testing.T) {
+	day := func(d int) int64 { return time.Date(2024, 5, d, 12, 0, 0, 0, time.UTC).UnixMilli() }
+	params := model.RunParams{GOOS: "linux", GOARCH: "amd64"}
+	entry := func(sha string, date int64, value float64) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha},
+			Date:       date,
+			Params:     params,
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: value, Unit: "ns/op"}},
+		}
+	}
+	existing := model.BranchData{entry("aaa", day(1), 100)}
+	windows := []storage.Suppression{{
+		From:   time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2024, 5, 4, 0, 0, 0, 0, time.UTC),
+		Reason: "runner migration",
+	}}
+	policy := regression.PercentPolicy{Threshold: 10}
+
+	inside := entry("bbb", day(3), 200)
+	if n := reportRegressions("percent", policy, existing, []model.BenchmarkEntry{inside}, regressionChecks{windows: windows}); n != 0 {
+		t.Errorf("commit inside the window: got %d regressions, want 0", n)
+	}
+	outside := entry("ccc", day(5), 200)
+	if n := reportRegressions("percent", policy, existing, []model.BenchmarkEntry{outside}, regressionChecks{windows: windows}); n != 1 {
+		t.Errorf("commit outside the window: got %d regressions, want 1", n)
+	}
+}
+
+// TestStoreGate_HonoursStoredSuppressions checks the path store's failing
+// gate takes, for -policy and -alert-threshold alike: suppressions recorded
+// with the suppress subcommand mute a regression in their window.
+func TestStoreGate_HonoursStoredSuppressions(t *testing.T) {
+	store, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddSuppression(storage.Suppression{
+		From:       time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2024, 5, 4, 0, 0, 0, 0, time.UTC),
+		Benchmarks: []string{"BenchmarkFoo"},
+		Reason:     "runner migration",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	checks, err := loadRegressionChecks(store)
+	if err != nil {
+		t.Fatalf("loadRegressionChecks() error: %v", err)
+	}
+
+	entry := func(sha string, d int, foo, bar float64) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit: model.Commit{SHA: sha},
+			Date:   time.Date(2024, 5, d, 12, 0, 0, 0, time.UTC).UnixMilli(),
+			Benchmarks: []model.BenchmarkResult{
+				{Name: "BenchmarkBar", Value: bar, Unit: "ns/op"},
+				{Name: "BenchmarkFoo", Value: foo, Unit: "ns/op"},
+			},
+		}
+	}
+	existing := model.BranchData{entry("aaa", 1, 100, 100)}
+	inside := []model.BenchmarkEntry{entry("bbb", 3, 200, 200)}
+	for name, policy := range map[string]regression.Policy{
+		"percent":         regression.PercentPolicy{Threshold: 10},
+		"alert-threshold": regression.PercentPolicy{Threshold: 50},
+	} {
+		// BenchmarkBar is outside the suppression's globs and still counts.
+		if n := reportRegressions(name, policy, existing, inside, checks); n != 1 {
+			t.Errorf("%s: got %d regressions, want 1 (BenchmarkBar only)", name, n)
+		}
+	}
+}
+
+func TestCheckNewestCommit_HonoursPins(t *testing.T) {
+	store, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := func(sha string, date int64, value float64) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha},
+			Date:       date,
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: value, Unit: "ns/op"}},
+		}
+	}
+	data := model.BranchData{entry("aaa", 1, 100), entry("bbb", 2, 150), entry("ccc", 3, 155)}
+	policy := regression.PercentPolicy{Threshold: 10}
+
+	results, err := checkNewestCommit(store, policy, data)
+	if err != nil || len(results) != 1 || results[0].Regressed {
+		t.Fatalf("rolling baseline: got %+v, %v; want no regression against bbb", results, err)
+	}
+	if err := store.WriteBranchData("main", data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.PinBaseline("main", "BenchmarkFoo", "aa"); err != nil {
+		t.Fatal(err)
+	}
+	results, err = checkNewestCommit(store, policy, data)
+	if err != nil || len(results) != 1 || !results[0].Regressed || !results[0].Pinned {
+		t.Errorf("pinned baseline: got %+v, %v; want a regression against aaa", results, err)
+	}
+}
+
+func TestUnknownDirections(t *testing.T) {
+	entries := []model.BenchmarkEntry{{Benchmarks: []model.BenchmarkResult{
+		{Name: "BenchmarkA", Unit: "ns/op"},
+		{Name: "BenchmarkA", Unit: "hits/op"},
+		{Name: "BenchmarkB", Unit: "hits/op"},
+		{Name: "BenchmarkB", Unit: "items/s"},
+		{Name: "BenchmarkC", Unit: "frames"},
+	}}}
+	if got, want := unknownDirections(entries), []string{"frames", "hits/op"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestExpandURLTemplate(t *testing.T) {
+	entry := model.BenchmarkEntry{
+		Commit: model.Commit{SHA: "0123456789abcdef"},
+		Params: model.RunParams{GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.24.0", CGO: true},
+	}
+	got := expandURLTemplate("https://example.com/{branch}/{short_sha}/{sha}/{artifact}/cpu.pprof", entry, "feature/x", "")
+	want := "https://example.com/feature%2Fx/0123456/0123456789abcdef/bench-linux-amd64-go1.24.0-cgo1/cpu.pprof"
+	if got != want {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+
+	if got := expandURLTemplate("https://example.com/static", entry, "main", ""); got != "https://example.com/static" {
+		t.Errorf("template without placeholders changed: %q", got)
+	}
+}
+
+func TestQueryHistory_Smooth(t *testing.T) {
+	params := model.RunParams{GOOS: "linux", GOARCH: "amd64"}
+	var data model.BranchData
+	for i, v := range []float64{10, 20, 30, 40} {
+		data = append(data, model.BenchmarkEntry{
+			Commit: model.Commit{SHA: fmt.Sprintf("c%d", i)},
+			Date:   int64(i),
+			Params: params,
+			Benchmarks: []model.BenchmarkResult{
+				{Name: "BenchmarkFoo", Value: v, Unit: "ns/op"},
+				{Name: "BenchmarkFoo - B/op", Value: 64, Unit: "B/op"},
+				{Name: "BenchmarkBar", Value: 1, Unit: "ns/op"},
+			},
+		})
+	}
+
+	all := queryHistory(data, "BenchmarkFoo", "", 0)
+	if len(all) != 2 {
+		t.Fatalf("expected ns/op and B/op series, got %d", len(all))
+	}
+	if all[0].Points[0].Smoothed != nil {
+		t.Error("points must not carry a smoothed value without -smooth")
+	}
+
+	got := queryHistory(data, "BenchmarkFoo", "ns/op", 2)
+	if len(got) != 1 || len(got[0].Points) != 4 {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+	want := []float64{10, 15, 25, 35}
+	for i, p := range got[0].Points {
+		if p.Smoothed == nil || *p.Smoothed != want[i] {
+			t.Errorf("point %d: smoothed %v, want %v", i, p.Smoothed, want[i])
+		}
+		if p.Value != []float64{10, 20, 30, 40}[i] {
+			t.Errorf("point %d: raw value changed to %v", i, p.Value)
+		}
+	}
+}
+
+func TestCreateGzipFile_StreamsTeedOutput(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("goos: linux\ngoarch: amd64\npkg: example.com/m\n")
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&input, "BenchmarkFoo%d-8   \t 1000000\t %d ns/op\n", i, 100+i)
+	}
+	input.WriteString("PASS\nok  \texample.com/m\t12.345s\n")
+
+	path := filepath.Join(t.TempDir(), "output.log.gz")
+	gz, err := createGzipFile(path)
+	if err != nil {
+		t.Fatalf("createGzipFile() error: %v", err)
+	}
+	tee := io.TeeReader(strings.NewReader(input.String()), gz)
+	results, _, _, err := parse.ParseGoBenchOutputDetailed(tee)
+	if err != nil {
+		t.Fatalf("parsing through the tee: %v", err)
+	}
+	if len(results) != 5000 {
+		t.Errorf("got %d results through the tee, want 5000", len(results))
+	}
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("log is not gzip: %v", err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != input.String() {
+		t.Errorf("gunzipped log differs from the input: got %d bytes, want %d", len(got), input.Len())
+	}
+}
+
+func TestSlowWarning(t *testing.T) {
+	fast := []model.BenchmarkResult{{Name: "BenchmarkFast", Value: 1500, Unit: "ns/op"}}
+	if w := slowWarning(fast, model.DefaultSlowThresholdNs); w != "" {
+		t.Errorf("fast benchmark: got warning %q, want none", w)
+	}
+
+	slow := append(fast, model.BenchmarkResult{Name: "BenchmarkQuadratic", Value: 95_000_000_000, Unit: "ns/op"})
+	w := slowWarning(slow, model.DefaultSlowThresholdNs)
+	if !strings.Contains(w, "BenchmarkQuadratic (95.00s/op)") || strings.Contains(w, "BenchmarkFast") {
+		t.Errorf("got warning %q, want only BenchmarkQuadratic at 95.00s/op", w)
+	}
+}
+
+func TestCheckEntrySignature(t *testing.T) {
+	key := []byte("secret")
+	newEntry := func(signed bool, edit func(*model.BenchmarkEntry)) loadedEntry {
+		e := model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: "abc123"},
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 10, Unit: "ns/op"}},
+		}
+		if signed {
+			if err := model.SignEntry(&e, key); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if edit != nil {
+			edit(&e)
+		}
+		raw, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		le, err := newLoadedEntry(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return le
+	}
+
+	valid := newEntry(true, nil)
+	if err := checkEntrySignature(&valid.BenchmarkEntry, valid.raw, key, true); err != nil {
+		t.Errorf("valid signature: got %v, want nil", err)
+	}
+	if valid.Signature != "" {
+		t.Error("a verified signature should be cleared before storing")
+	}
+
+	forged := newEntry(true, func(e *model.BenchmarkEntry) { e.Benchmarks[0].Value = 1 })
+	if err := checkEntrySignature(&forged.BenchmarkEntry, forged.raw, key, false); !errors.Is(err, model.ErrBadSignature) {
+		t.Errorf("tampered value: got %v, want ErrBadSignature", err)
+	}
+
+	unsigned := newEntry(false, nil)
+	if err := checkEntrySignature(&unsigned.BenchmarkEntry, unsigned.raw, key, false); err != nil {
+		t.Errorf("unsigned without -require-signed: got %v, want nil", err)
+	}
+	if err := checkEntrySignature(&unsigned.BenchmarkEntry, unsigned.raw, key, true); !errors.Is(err, model.ErrUnsigned) {
+		t.Errorf("unsigned with -require-signed: got %v, want ErrUnsigned", err)
+	}
+}
+
+func TestReadKey(t *testing.T) {
+	t.Setenv(signKeyEnv, "from-env")
+	if key, err := readKey("", signKeyEnv); err != nil || string(key) != "from-env" {
+		t.Errorf("no file: got %q, %v; want the environment value", key, err)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "key")
+	if err := os.WriteFile(file, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if key, err := readKey(file, signKeyEnv); err != nil || string(key) != "from-file" {
+		t.Errorf("file: got %q, %v; want the file's key without the newline", key, err)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readKey(empty, signKeyEnv); err == nil {
+		t.Error("empty file: want an error")
+	}
+	if _, err := readKey(filepath.Join(dir, "missing"), signKeyEnv); err == nil {
+		t.Error("missing file: want an error")
+	}
+}
+
+func TestNewParseSummary(t *testing.T) {
+	entry := model.BenchmarkEntry{
+		Params: model.RunParams{CPU: "Apple M2", GOOS: "darwin", GOARCH: "arm64", GoVersion: "go1.24.0"},
+		Benchmarks: []model.BenchmarkResult{
+			{Name: "BenchmarkA", Value: 1, Unit: "ns/op"},
+			{Name: "BenchmarkA - B/op", Value: 8, Unit: "B/op"},
+			{Name: "BenchmarkA", Value: 2, Unit: "ns/op"},
+			{Name: "BenchmarkB", Value: 3, Unit: "ns/op"},
+		},
+	}
+	got := newParseSummary(entry, "bench-darwin-arm64", 2)
+	want := parseSummary{
+		Benchmarks:   4,
+		Skipped:      2,
+		ArtifactName: "bench-darwin-arm64",
+		CPU:          "Apple M2",
+		GOOS:         "darwin",
+		GOARCH:       "arm64",
+		GoVersion:    "go1.24.0",
+		Names:        []string{"BenchmarkA", "BenchmarkA - B/op", "BenchmarkB"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// An entry without results still encodes names as a list, not null.
+	data, err := json.Marshal(newParseSummary(model.BenchmarkEntry{}, "x", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"names":[]`) {
+		t.Errorf("got %s, want an empty names list", data)
+	}
+}
+
+func TestRegisterUnitDirections(t *testing.T) {
+	if err := registerUnitDirections("cache-hits/op=higher, p99-ns=lower"); err != nil {
+		t.Fatalf("registerUnitDirections() error: %v", err)
+	}
+	if got := model.UnitDirection("cache-hits/op"); got != model.HigherIsBetter {
+		t.Errorf("cache-hits/op: got %v, want higher", got)
+	}
+	if got := model.UnitDirection("p99-ns"); got != model.LowerIsBetter {
+		t.Errorf("p99-ns: got %v, want lower", got)
+	}
+
+	for _, bad := range []string{"MB/s", "=higher", "MB/s=faster"} {
+		if err := registerUnitDirections(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestMixedCPUs(t *testing.T) {
+	entry := func(cpu, goos, goarch string) model.BenchmarkEntry {
+		return model.BenchmarkEntry{Params: model.RunParams{CPU: cpu, GOOS: goos, GOARCH: goarch}}
+	}
+
+	tests := []struct {
+		name    string
+		entries []model.BenchmarkEntry
+		want    []string
+	}{
+		{"single CPU", []model.BenchmarkEntry{
+			entry("Intel Xeon", "linux", "amd64"),
+			entry("Intel Xeon", "linux", "amd64"),
+		}, nil},
+		{"different platforms", []model.BenchmarkEntry{
+			entry("Intel Xeon", "linux", "amd64"),
+			entry("Apple M2", "darwin", "arm64"),
+		}, nil},
+		{"mixed", []model.BenchmarkEntry{
+			entry("Intel Xeon", "linux", "amd64"),
+			entry("Apple M2", "darwin", "arm64"),
+			entry("AMD EPYC 7763", "linux", "amd64"),
+			entry("Intel Xeon", "linux", "amd64"),
+		}, []string{`linux/amd64: "AMD EPYC 7763", "Intel Xeon"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mixedCPUs(tt.entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareRows(t *testing.T) {
+	series := []compare.Series{
+		{Name: "BenchmarkA", Unit: "ns/op", Metric: compare.Metric{Base: 100, Head: 120, HasBase: true, HasHead: true}},
+		{Name: "BenchmarkA - MB/s", Unit: "MB/s", Metric: compare.Metric{Base: 100, Head: 120, HasBase: true, HasHead: true}},
+		{Name: "BenchmarkNew", Unit: "ns/op", Metric: compare.Metric{Head: 5, HasHead: true}},
+		{Name: "BenchmarkOld", Unit: "ns/op", Metric: compare.Metric{Base: 5, HasBase: true}},
+	}
+
+	linux := model.EntryKeyValue{Params: model.RunParams{GOOS: "linux"}}
+	rows := compareRows(linux, series, 10)
+	var got []string
+	for _, r := range rows {
+		got = append(got, fmt.Sprintf("%s %v", r.Status, r.Regressed))
+	}
+	want := []string{"shared true", "shared false", "added false", "removed false"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if rows[0].Delta == nil || *rows[0].Delta != 20 {
+		t.Errorf("delta of BenchmarkA: got %v, want 20", rows[0].Delta)
+	}
+	if rows[2].Delta != nil {
+		t.Errorf("delta of added benchmark: got %v, want none", *rows[2].Delta)
+	}
+
+	if rows[0].Params != linux.Params {
+		t.Errorf("params: got %+v, want the configuration's", rows[0].Params)
+	}
+
+	if rows := compareRows(linux, series, 0); rows[0].Regressed {
+		t.Error("threshold 0 should never mark a regression")
+	}
+}
+
+func TestArtifactNameFromParams(t *testing.T) {
+	p := model.RunParams{GOOS: "linux", GOARCH: "amd64", GoVersion: "go1.24.0", CGO: true}
+	if got := artifactNameFromParams(p, ""); got != "bench-linux-amd64-go1.24.0-cgo1" {
+		t.Errorf("got %q, want bench-linux-amd64-go1.24.0-cgo1", got)
+	}
+	if got := artifactNameFromParams(p, "db"); got != "bench-db-linux-amd64-go1.24.0-cgo1" {
+		t.Errorf("with suite: got %q, want bench-db-linux-amd64-go1.24.0-cgo1", got)
+	}
+	p.MicroArch = "v3"
+	if got := artifactNameFromParams(p, ""); got != "bench-linux-amd64-v3-go1.24.0-cgo1" {
+		t.Errorf("with microarch: got %q, want bench-linux-amd64-v3-go1.24.0-cgo1", got)
+	}
+}
+
+func TestWaitForEntries(t *testing.T) {
+	defer func(d time.Duration) { waitPollInterval = d }(waitPollInterval)
+	waitPollInterval = 10 * time.Millisecond
+
+	dir := t.TempDir()
+	glob := filepath.Join(dir, "*", "entry.json")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, job := range []string{"linux", "darwin"} {
+			time.Sleep(30 * time.Millisecond)
+			os.MkdirAll(filepath.Join(dir, job), 0o755)
+			os.WriteFile(filepath.Join(dir, job, "entry.json"), []byte("{}"), 0o644)
+		}
+	}()
+
+	got, err := waitForEntries(glob, 2, 5*time.Second)
+	<-done
+	if err != nil {
+		t.Fatalf("waitForEntries() error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("got %d files, want 2", len(got))
+	}
+
+	if _, err := waitForEntries(glob, 3, 50*time.Millisecond); err == nil {
+		t.Error("expected timeout error when the files never appear")
+	}
+}
+
+func TestTransformEntry(t *testing.T) {
+	entry, err := loadEntry("testdata/entry.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	same, err := transformEntry("cat", entry, 5*time.Second)
+	if err != nil {
+		t.Fatalf("cat transform error: %v", err)
+	}
+	if same.Commit.SHA != entry.Commit.SHA || len(same.Benchmarks) != len(entry.Benchmarks) {
+		t.Errorf("cat transform changed the entry: got %+v", same)
+	}
+
+	// A transform that injects a field.
+	added, err := transformEntry(`sed 's/^{/{"profileUrl":"https:\/\/example.com\/p",/'`, entry, 5*time.Second)
+	if err != nil {
+		t.Fatalf("sed transform error: %v", err)
+	}
+	if added.ProfileURL != "https://example.com/p" {
+		t.Errorf("got ProfileURL %q, want https://example.com/p", added.ProfileURL)
+	}
+
+	for name, cmd := range map[string]string{
+		"failing":    "echo boom >&2; exit 3",
+		"not json":   "echo not json",
+		"empty":      "echo '{}'",
+		"timing out": "sleep 5",
+	} {
+		if _, err := transformEntry(cmd, entry, 100*time.Millisecond); err == nil {
+			t.Errorf("%s command: expected error", name)
+		}
+	}
+}
+
+func TestGoVersionTable(t *testing.T) {
+	entry := func(goos, goVersion string, value float64) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Params:     model.RunParams{GOOS: goos, GOARCH: "amd64", GoVersion: goVersion},
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: value, Unit: "ns/op"}},
+		}
+	}
+	versions, rows := goVersionTable(map[string][]model.BenchmarkEntry{
+		"go1.23.0": {entry("linux", "go1.23.0", 90), entry("darwin", "go1.23.0", 80)},
+		"go1.22.0": {entry("linux", "go1.22.0", 100)},
+	})
+	if !reflect.DeepEqual(versions, []string{"go1.22.0", "go1.23.0"}) {
+		t.Errorf("versions: got %v", versions)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want one per platform: %+v", len(rows), rows)
+	}
+	want := map[string]map[string]float64{
+		"linux":  {"go1.22.0": 100, "go1.23.0": 90},
+		"darwin": {"go1.23.0": 80},
+	}
+	for _, row := range rows {
+		if !reflect.DeepEqual(row.values, want[row.config.Params.GOOS]) {
+			t.Errorf("%s: got %v, want %v", row.config.Params.GOOS, row.values, want[row.config.Params.GOOS])
+		}
+	}
+}
+
+func TestCommitGoVersions_AbbreviatedSHA(t *testing.T) {
+	store, err := storage.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := func(sha, goVersion string) model.BenchmarkEntry {
+		return model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: sha},
+			Date:       1000,
+			Params:     model.RunParams{GOOS: "linux", GOARCH: "amd64", GoVersion: goVersion},
+			Benchmarks: []model.BenchmarkResult{{Name: "BenchmarkFoo", Value: 100, Unit: "ns/op"}},
+		}
+	}
+	if err := store.AppendEntries("main", []model.BenchmarkEntry{
+		entry("abc1234567", "go1.22.0"),
+		entry("abc1234567", "go1.23.0"),
+		entry("abd9876543", "go1.23.0"),
+	}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := commitGoVersions(store, "main", "abc1", nil)
+	if err != nil {
+		t.Fatalf("commitGoVersions() error: %v", err)
+	}
+	if len(got) != 2 || len(got["go1.23.0"]) != 1 || got["go1.23.0"][0].Commit.SHA != "abc1234567" {
+		t.Errorf("got %+v, want the two Go versions of abc1234567", got)
+	}
+
+	if _, err := commitGoVersions(store, "main", "ab", nil); !errors.Is(err, storage.ErrAmbiguousSHA) {
+		t.Errorf("prefix of two commits: got error %v, want ErrAmbiguousSHA", err)
+	}
+}
+
+func TestGoVersionLess(t *testing.T) {
+	versions := []string{"devel go1.25-abc", "go1.22.1", "go1.9", "go1.22", "go1.23.0"}
+	sort.Slice(versions, func(i, j int) bool { return goVersionLess(versions[i], versions[j]) })
+	want := []string{"go1.9", "go1.22", "go1.22.1", "go1.23.0", "devel go1.25-abc"}
+	for i := range want {
+		if versions[i] != want[i] {
+			t.Fatalf("got %v, want %v", versions, want)
+		}
+	}
+}
+
+func TestWriteTrendReport(t *testing.T) {
+	const day = int64(24 * time.Hour / time.Millisecond)
+	var data model.BranchData
+	for i := range 4 {
+		benchmarks := []model.BenchmarkResult{
+			{Name: "BenchmarkDrift", Value: 100 + float64(i)*10, Unit: "ns/op"},
+			{Name: "BenchmarkFlat", Value: 50, Unit: "ns/op"},
+		}
+		if i == 3 {
+			benchmarks = append(benchmarks, model.BenchmarkResult{Name: "BenchmarkNew", Value: 1, Unit: "ns/op"})
+		}
+		data = append(data, model.BenchmarkEntry{
+			Commit:     model.Commit{SHA: fmt.Sprintf("sha%d", i)},
+			Date:       int64(i) * day,
+			Benchmarks: benchmarks,
+		})
+	}
+
+	var out strings.Builder
+	if got := writeTrendReport(&out, "main", data, 1, 5); got != 1 {
+		t.Errorf("got %d flagged, want 1 (BenchmarkDrift)", got)
+	}
+	report := out.String()
+	for _, want := range []string{
+		`Trend over 4 entries of branch "main"`,
+		"BenchmarkDrift",
+		"WORSE",
+		"1 benchmark(s) trending worse than 5.00%, 1 skipped with fewer than 2 points",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "BenchmarkNew") {
+		t.Errorf("a single point should be skipped, not fitted:\n%s", report)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// pin subcommand
+// ---------------------------------------------------------------------------
+
+func runPin(args []string) {
+	fs := flag.NewFlagSet("pin", flag.ExitOnError)
+
+	var (
+		branch    string
+		dataDir   string
+		suite     string
+		benchmark string
+		sha       string
+		unpin     bool
+	)
+
+	fs.StringVar(&branch, "branch", "main", "Git branch name whose stored commits -sha is looked up in")
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.StringVar(&benchmark, "benchmark", "", "Benchmark name glob to pin, e.g. 'BenchmarkParse*' (required)")
+	fs.StringVar(&sha, "sha", "", "Commit SHA, or a unique prefix of it, of a stored commit of -branch that matching benchmarks are compared against by the regression check (required unless -unpin)")
+	fs.BoolVar(&unpin, "unpin", false, "Remove the pin of -benchmark so it uses the rolling baseline again")
+
+	fs.Parse(args)
+
+	if benchmark == "" {
+		log.Fatal("Error: -benchmark is required")
+	}
+	if sha == "" && !unpin {
+		log.Fatal("Error: -sha is required")
+	}
+	if unpin {
+		sha = ""
+	}
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+	if sha, err = store.PinBaseline(branch, benchmark, sha); err != nil {
+		log.Fatalf("Error pinning baseline: %v", err)
+	}
+	if unpin {
+		fmt.Printf("Unpinned %s\n", benchmark)
+		return
+	}
+	fmt.Printf("Pinned %s to %s\n", benchmark, sha)
+}
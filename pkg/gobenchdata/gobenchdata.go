@@ -0,0 +1,145 @@
+// Package gobenchdata is the library behind the gobenchdata command: it
+// turns go test -bench output into a benchmark entry (Parse) and merges
+// entries into a dashboard data directory (Store), for programs that embed
+// the workflow instead of running the binary.
+//
+// The types below are the ones the command reads and writes as JSON, so an
+// Entry built here is the same value parse writes to entry.json and store
+// keeps in data/<branch>.json. Their fields and JSON names are documented
+// here; nothing outside this package needs to be imported to use them.
+package gobenchdata
+
+import (
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/parse"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+type (
+	// Entry is one benchmark run: a commit's results on one host. Its
+	// fields are:
+	//
+	//   - Commit ("commit"): the benchmarked commit.
+	//   - Date ("date"): the commit date in Unix milliseconds; entries of a
+	//     branch are kept sorted by it.
+	//   - Params ("params"): the host and toolchain of the run.
+	//   - Tags ("tags"): free-form experiment labels, e.g. gc=off, that
+	//     keep runs of one commit on one host apart. They are unrelated to
+	//     git tags.
+	//   - Environment ("environment"): host environment variables captured
+	//     by parse; descriptive only.
+	//   - CPUModels ("cpuModels"): every CPU model of a heterogeneous host;
+	//     Params.CPU stays the single model runs are told apart by.
+	//   - CPURaw ("cpuRaw"): the CPU model as detected, when
+	//     ParseConfig.NormalizeCPU changed it.
+	//   - ProfileURL ("profileUrl"): an optional link to a pprof profile.
+	//   - Benchmarks ("benchmarks"): the results.
+	//   - Signature ("signature"): the HMAC parse -sign-key-file adds;
+	//     store checks and clears it.
+	//
+	// A stored entry replaces an earlier one with the same commit, Params
+	// and Tags (see KeyConfig).
+	Entry = model.BenchmarkEntry
+	// Result is a single benchmark measurement: Value ("value") in Unit
+	// ("unit") for the benchmark Name ("name") of Package ("package"), run
+	// with GOMAXPROCS Procs ("procs"). The first metric of an output line,
+	// usually ns/op, keeps the benchmark's name; each further metric of the
+	// line is a result of its own named "<name> - <unit>". Extra ("extra")
+	// holds the iteration count as "N times"; StdDev ("stdDev") and Samples
+	// ("samples") are set when repeated runs were aggregated. Custom
+	// ("custom") marks metrics reported with b.ReportMetric. With
+	// ParseConfig.InlineMemMetrics, BytesPerOp ("bytesPerOp") and
+	// AllocsPerOp ("allocsPerOp") carry the memory metrics instead of
+	// results of their own.
+	Result = model.BenchmarkResult
+	// Commit is the git commit an entry was measured at: its SHA ("sha"),
+	// the first line of its Message ("message"), Author ("author"), Date
+	// ("date", RFC 3339) and URL ("url"). Parents ("parents") optionally
+	// lists its first-parent ancestors, nearest first, so that the commits
+	// between two benchmarked ones can be listed without the repository.
+	Commit = model.Commit
+	// RunParams identifies the host and toolchain an entry ran on: CPU
+	// ("cpu") model, GOOS ("goos"), GOARCH ("goarch"), MicroArch
+	// ("microArch", the GOAMD64/GOARM-style level), GoVersion
+	// ("goVersion"), CGO ("cgo") and DatasetHash ("datasetHash"), an
+	// optional hash of the input data-driven benchmarks read. Results are
+	// only compared with results of equal RunParams.
+	RunParams = model.RunParams
+	// KeyConfig selects the dimensions of the key deciding which stored
+	// entry a new one replaces. Each field turns one dimension on: SHA,
+	// CPU, GOOS, GOARCH, MicroArch, GoVersion, CGO, Dataset, Tags and Env
+	// (the captured Environment). The default key is every dimension but
+	// Env.
+	KeyConfig = model.KeyConfig
+
+	// ParseResult counts the lines the parser skipped: Skipped ("skipped")
+	// in total, UnknownUnits ("unknownUnits") of them for a unit outside
+	// ParseConfig.AllowedUnits, and Samples ("samples") lists the first
+	// few of them.
+	ParseResult = parse.ParseResult
+	// SkippedLine is one of ParseResult.Samples: the Line ("line") number
+	// and Text ("text") of a skipped line, and the Reason ("reason") it
+	// was skipped.
+	SkippedLine = parse.SkippedLine
+	// OutputMetadata holds what the go test headers said about the run:
+	// the first cpu:, goos: and goarch: lines as CPU, GOOS and GOARCH, the
+	// cpu: line of each package's header in PackageCPUs, and in Complete
+	// whether the output ended with a PASS, ok or FAIL line rather than
+	// being cut off.
+	OutputMetadata = parse.OutputMetadata
+
+	// StorageOption configures the data directory Store writes to. The
+	// With functions of this package return them.
+	StorageOption = storage.Option
+)
+
+// WithForce lets Store write into a non-empty directory that was not
+// created by this tool.
+func WithForce() StorageOption { return storage.WithForce() }
+
+// WithStableReleasesOnly keeps pre-release tags (e.g. "v1.0.0-rc.1") out of
+// the aggregated releases data. Their entries are still written to the
+// tag's own data file.
+func WithStableReleasesOnly() StorageOption { return storage.WithStableReleasesOnly() }
+
+// WithEnvDedup keeps entries that differ only in their captured
+// Environment as separate runs instead of replacing one with the other.
+func WithEnvDedup() StorageOption { return storage.WithEnvDedup() }
+
+// WithKeyConfig sets the dimensions of the key that decides which stored
+// entry a new one replaces. Combined with WithEnvDedup, the environment is
+// added to cfg regardless of the option order.
+func WithKeyConfig(cfg KeyConfig) StorageOption { return storage.WithKeyConfig(cfg) }
+
+// WithBrotli also writes a brotli-compressed copy of each data file
+// (data/<branch>.json.br) for servers that negotiate Content-Encoding.
+func WithBrotli() StorageOption { return storage.WithBrotli() }
+
+// WithGzip stores branch data gzip-compressed as data/<branch>.json.gz. A
+// plain data/<branch>.json left by an earlier run is replaced on the next
+// write.
+func WithGzip() StorageOption { return storage.WithGzip() }
+
+// WithDeltaEncoding stores values as percent changes from the previous
+// point of the same series, with an absolute value every anchorEvery points
+// (50 if not positive). It shrinks long, near-flat histories at the cost of
+// about 1e-8 relative error per point. Experimental.
+func WithDeltaEncoding(anchorEvery int) StorageOption { return storage.WithDeltaEncoding(anchorEvery) }
+
+// WithNDJSON stores branch data as data/<branch>.ndjson, one entry per
+// line, so that new commits are appended without rewriting the file. It
+// takes precedence over WithGzip and WithDeltaEncoding.
+func WithNDJSON() StorageOption { return storage.WithNDJSON() }
+
+// WithSuite stores into the named benchmark suite, a separate timeline
+// under data/<name>/ with its own branch list, listed in suites.json for
+// the dashboard. Names are letters, digits, '-' and '_'; the empty name is
+// the default suite.
+func WithSuite(name string) StorageOption { return storage.WithSuite(name) }
+
+// WithClock makes Store take every timestamp it writes (e.g. the last
+// update in metadata.json) from now instead of the system clock, for
+// reproducible output.
+func WithClock(now func() time.Time) StorageOption { return storage.WithClock(now) }
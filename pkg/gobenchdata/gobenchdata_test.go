@@ -0,0 +1,248 @@
+package gobenchdata
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/parse"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+const benchOutput = `goos: linux
+goarch: amd64
+pkg: example.com/m
+cpu: Test CPU @ 3.00GHz
+BenchmarkFoo-8   1000   100 ns/op   16 B/op   1 allocs/op
+PASS
+ok  	example.com/m	1.000s
+`
+
+func TestParse(t *testing.T) {
+	entry, details, err := ParseDetailed(ParseConfig{
+		Input:  strings.NewReader(benchOutput),
+		Commit: Commit{SHA: "abc123", Message: "subject\n\nbody", Date: "2026-01-02T03:04:05Z"},
+		CGO:    "false",
+		Tags:   map[string]string{"gc": "off"},
+	})
+	if err != nil {
+		t.Fatalf("ParseDetailed() error: %v", err)
+	}
+	if !details.Meta.Complete {
+		t.Error("output with PASS should be complete")
+	}
+	if entry.Commit.Message != "subject" {
+		t.Errorf("message: got %q, want the first line", entry.Commit.Message)
+	}
+	if entry.Date != 1767323045000 {
+		t.Errorf("date: got %d, want the commit date in ms", entry.Date)
+	}
+	if entry.Params.CPU != "Test CPU @ 3.00GHz" || entry.Params.CGO {
+		t.Errorf("params: got %+v, want the output's CPU and CGO off", entry.Params)
+	}
+	if len(entry.Benchmarks) != 3 || entry.Tags["gc"] != "off" {
+		t.Errorf("got %d results and tags %v, want 3 and gc=off", len(entry.Benchmarks), entry.Tags)
+	}
+
+	entry, err = Parse(ParseConfig{
+		Input:            strings.NewReader(benchOutput),
+		Commit:           Commit{SHA: "abc123"},
+		CPU:              "Pinned CPU",
+		InlineMemMetrics: true,
+	})
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if entry.Params.CPU != "Pinned CPU" || len(entry.Benchmarks) != 1 || entry.Commit.Date == "" {
+		t.Errorf("got %+v, want the given CPU, one inline result and a default date", entry)
+	}
+
+	entry, err = Parse(ParseConfig{Input: strings.NewReader(benchOutput), Commit: Commit{SHA: "abc123"}, MicroArch: "v3"})
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if entry.Params.MicroArch != "v3" {
+		t.Errorf("microarch: got %q, want v3", entry.Params.MicroArch)
+	}
+
+	entry, err = Parse(ParseConfig{
+		Input:        strings.NewReader(strings.Replace(benchOutput, "Test CPU", "Intel(R) Xeon(R) CPU", 1)),
+		Commit:       Commit{SHA: "abc123"},
+		NormalizeCPU: true,
+	})
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if entry.Params.CPU != "Intel Xeon" || entry.CPURaw != "Intel(R) Xeon(R) CPU @ 3.00GHz" {
+		t.Errorf("normalized: got CPU %q and raw %q, want Intel Xeon and the cpu: line", entry.Params.CPU, entry.CPURaw)
+	}
+
+	if _, err := Parse(ParseConfig{Input: strings.NewReader("no results\n"), Commit: Commit{SHA: "abc"}}); !errors.Is(err, parse.ErrNoResults) {
+		t.Errorf("empty output: got error %v, want ErrNoResults", err)
+	}
+	if _, err := Parse(ParseConfig{Input: strings.NewReader(benchOutput)}); err == nil {
+		t.Error("missing SHA: want an error")
+	}
+	if _, err := Parse(ParseConfig{Input: strings.NewReader(benchOutput), Commit: Commit{SHA: "abc"}, Format: "jsonl", Packages: []string{"x"}}); err == nil {
+		t.Error("package filter on JSON lines: want an error")
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	entry, err := Parse(ParseConfig{
+		Input:  strings.NewReader(benchOutput),
+		Commit: Commit{SHA: "abc123", Date: "2026-01-02T03:04:05Z"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Store(StoreConfig{
+		DataDir:      dir,
+		Branch:       "v1.0.0",
+		Entries:      []Entry{entry},
+		RepoURL:      "https://github.com/owner/repo",
+		WriteGrouped: true,
+		Options:      []StorageOption{WithGzip()},
+	})
+	if err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+	for _, name := range []string{
+		"branches.json", "metadata.json", "manifest.json",
+		"data/v1.0.0.json.gz", "data/releases.json.gz",
+		"grouped/v1.0.0.json", "grouped/releases.json",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	if err := Store(StoreConfig{DataDir: dir}); err == nil {
+		t.Error("missing branch: want an error")
+	}
+}
+
+func TestGateStoreInterval(t *testing.T) {
+	params := RunParams{GOOS: "linux", GOARCH: "amd64"}
+	hour := time.Hour.Milliseconds()
+	existing := []Entry{{
+		Commit:     Commit{SHA: "aaa"},
+		Date:       10 * hour,
+		Params:     params,
+		Benchmarks: []Result{{Name: "BenchmarkFoo", Value: 100, Unit: "ns/op"}},
+	}}
+
+	tests := []struct {
+		name  string
+		date  int64
+		value float64
+		kept  bool
+	}{
+		{"too soon, values unchanged", 10*hour + 1, 100.5, false},
+		{"too soon but values changed", 10*hour + 1, 120, true},
+		{"interval elapsed", 13 * hour, 100, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := Entry{
+				Commit:     Commit{SHA: "bbb"},
+				Date:       tt.date,
+				Params:     params,
+				Benchmarks: []Result{{Name: "BenchmarkFoo", Value: tt.value, Unit: "ns/op"}},
+			}
+			kept, skipped := gateStoreInterval(existing, []Entry{entry}, 2*time.Hour, 1)
+			if (len(kept) == 1) != tt.kept || len(kept)+len(skipped) != 1 {
+				t.Errorf("kept %d and skipped %d, want kept = %v", len(kept), len(skipped), tt.kept)
+			}
+		})
+	}
+}
+
+func TestStoreDetailed(t *testing.T) {
+	dir := t.TempDir()
+	entry := func(sha string, date int64, name string, value float64) Entry {
+		return Entry{
+			Commit:     Commit{SHA: sha, Date: "2026-01-02T03:04:05Z"},
+			Date:       date,
+			Params:     RunParams{GOOS: "linux", GOARCH: "amd64"},
+			Benchmarks: []Result{{Name: name, Value: value, Unit: "ns/op"}},
+		}
+	}
+	hour := time.Hour.Milliseconds()
+	if err := Store(StoreConfig{DataDir: dir, Branch: "main", Entries: []Entry{entry("aaa", hour, "BenchmarkFoo", 100)}}); err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+
+	errCheck := errors.New("check failed")
+	_, err := StoreDetailed(context.Background(), StoreConfig{
+		DataDir: dir,
+		Branch:  "main",
+		Entries: []Entry{entry("bbb", 2*hour, "BenchmarkFoo", 200)},
+		Check:   func(stored, entries []Entry) error { return errCheck },
+	})
+	if !errors.Is(err, errCheck) {
+		t.Fatalf("failing Check: got error %v, want %v", err, errCheck)
+	}
+
+	var checked []Entry
+	details, err := StoreDetailed(context.Background(), StoreConfig{
+		DataDir: dir,
+		Branch:  "main",
+		Entries: []Entry{
+			entry("bbb", 2*hour, "benchmarkfoo", 200),
+			entry("ccc", 2*hour+1, "BenchmarkFoo", 100),
+		},
+		WorkloadChanges: []string{"bbb"},
+		NameNormalize:   "fold",
+		Check: func(stored, entries []Entry) error {
+			if len(stored) != 1 {
+				t.Errorf("Check got %d stored entries, want the one of aaa", len(stored))
+			}
+			checked = entries
+			return nil
+		},
+		StoreInterval:  2 * time.Hour,
+		StoreTolerance: 1,
+	})
+	if err != nil {
+		t.Fatalf("StoreDetailed() error: %v", err)
+	}
+	if len(checked) != 2 || checked[0].Benchmarks[0].Name != "BenchmarkFoo" {
+		t.Errorf("Check got %+v, want both entries with the stored name", checked)
+	}
+	if len(details.Previous) != 1 || len(details.Stored) != 1 || details.Stored[0].Commit.SHA != "bbb" {
+		t.Errorf("got previous %+v and stored %+v, want aaa and bbb", details.Previous, details.Stored)
+	}
+	if len(details.Skipped) != 1 || details.Skipped[0].Commit.SHA != "ccc" {
+		t.Errorf("skipped: got %+v, want ccc, unchanged from aaa within the interval", details.Skipped)
+	}
+
+	store, err := storage.New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := store.ReadBranchData("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 {
+		t.Errorf("stored %d entries, want aaa and bbb", len(data))
+	}
+	annotations, err := store.ReadAnnotations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(annotations) != 1 || annotations[0].SHA != "bbb" {
+		t.Errorf("annotations: got %+v, want the workload change of bbb", annotations)
+	}
+
+	if _, err := StoreDetailed(context.Background(), StoreConfig{DataDir: dir, Branch: "main", NameNormalize: "lower"}); err == nil {
+		t.Error("unknown name normalization: want an error")
+	}
+}
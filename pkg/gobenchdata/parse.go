@@ -0,0 +1,222 @@
+package gobenchdata
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/hwinfo"
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/parse"
+)
+
+// ParseConfig configures Parse. Zero values select what the parse
+// subcommand does without the corresponding flag.
+type ParseConfig struct {
+	// Input is the benchmark output to parse. Required.
+	Input io.Reader
+	// Format is "text" (default) for go test -bench output or "jsonl" for
+	// one {"name","value","unit"} object per line.
+	Format string
+
+	// Commit is the benchmarked commit. SHA is required, Message is cut to
+	// its first line and an empty Date (RFC 3339) means now.
+	Commit Commit
+
+	// CPU and GoVersion override the detected values. Without CPU, the
+	// cpu: line of the output is used, else the host's CPU model.
+	CPU       string
+	GoVersion string
+	// NormalizeCPU strips trademark marks, the word "CPU" and the clock
+	// from the CPU model, detected or given, so that "Intel(R) Core(TM)
+	// i7-8700 CPU @ 3.20GHz" becomes "Intel Core i7-8700"; the entry's
+	// CPURaw keeps the original if that changed it.
+	NormalizeCPU bool
+	// MicroArch is the microarchitecture level the benchmarks were built
+	// for (e.g. "v3" for amd64); empty reads it from GOAMD64, GOARM and the
+	// like in the environment.
+	MicroArch string
+	// CGO is "true", "false", or "" to follow CGO_ENABLED (default on).
+	CGO         string
+	DatasetHash string
+	Tags        map[string]string
+
+	// Aggregate collapses repeated samples of a benchmark (go test
+	// -count=N) into their mean, recording their standard deviation and
+	// count in the result. DiscardFirst also drops the first sample of
+	// each, a warm-up run, and implies Aggregate.
+	Aggregate    bool
+	DiscardFirst bool
+	// AllowedUnits keeps only metrics of these units (the others count in
+	// ParseResult.UnknownUnits), Packages only the results of packages
+	// whose import path matches one of these path.Match globs, and
+	// InlineMemMetrics records B/op and allocs/op on the ns/op result
+	// instead of as results of their own. They apply to the text format
+	// only; empty means no restriction.
+	AllowedUnits     []string
+	Packages         []string
+	InlineMemMetrics bool
+}
+
+// ParseDetails describes how the input of ParseDetailed parsed, for callers
+// that warn about skipped lines or incomplete output.
+type ParseDetails struct {
+	Result ParseResult
+	// Meta is zero apart from Complete, which is always true, for JSON
+	// lines.
+	Meta OutputMetadata
+}
+
+// Parse parses cfg.Input into an entry for cfg.Commit, filling in the run
+// parameters of the current host.
+func Parse(cfg ParseConfig) (Entry, error) {
+	entry, _, err := ParseDetailed(cfg)
+	return entry, err
+}
+
+// ParseDetailed is Parse that also returns what the parser skipped and the
+// metadata of the output.
+func ParseDetailed(cfg ParseConfig) (Entry, ParseDetails, error) {
+	var details ParseDetails
+	if cfg.Input == nil {
+		return Entry{}, details, errors.New("no input")
+	}
+	if cfg.Commit.SHA == "" {
+		return Entry{}, details, errors.New("commit SHA is required")
+	}
+	switch cfg.Format {
+	case "", "text":
+	case "jsonl":
+		if len(cfg.AllowedUnits) > 0 || len(cfg.Packages) > 0 || cfg.InlineMemMetrics {
+			return Entry{}, details, errors.New("allowed units, package filters and inline memory metrics are only supported for text input")
+		}
+	default:
+		return Entry{}, details, fmt.Errorf("unknown format %q (want text or jsonl)", cfg.Format)
+	}
+
+	commit := cfg.Commit
+	commit.Message = firstLine(commit.Message)
+	if commit.Date == "" {
+		commit.Date = time.Now().UTC().Format(time.RFC3339)
+	}
+	commitTime, err := time.Parse(time.RFC3339, commit.Date)
+	if err != nil {
+		return Entry{}, details, fmt.Errorf("parsing commit date %q: %w", commit.Date, err)
+	}
+
+	// Host metadata (auto-detected on the runner).
+	cpu := cfg.CPU
+	var cpuModels []string
+	if cpu == "" {
+		cpu = hwinfo.CPUModel()
+		if models := hwinfo.CPUModels(); len(models) > 1 {
+			cpuModels = models
+		}
+	}
+	goVersion := cfg.GoVersion
+	if goVersion == "" {
+		goVersion = runtime.Version()
+	}
+	microArch := cfg.MicroArch
+	if microArch == "" {
+		microArch = hwinfo.MicroArch(runtime.GOARCH)
+	}
+
+	var results []model.BenchmarkResult
+	if cfg.Format == "jsonl" {
+		results, details.Result, err = parse.ParseJSONLines(cfg.Input)
+		if err == nil && (cfg.Aggregate || cfg.DiscardFirst) {
+			results = parse.AggregateSamples(results, cfg.DiscardFirst)
+		}
+		// JSON lines have no go test headers or terminal line to check.
+		details.Meta.Complete = true
+	} else {
+		opts := parse.ParseOptions{
+			AllowedUnits:     cfg.AllowedUnits,
+			Aggregate:        cfg.Aggregate,
+			DiscardFirst:     cfg.DiscardFirst,
+			Packages:         cfg.Packages,
+			InlineMemMetrics: cfg.InlineMemMetrics,
+		}
+		results, details.Meta, details.Result, err = parse.ParseGoBenchOutputWithOptions(cfg.Input, opts)
+	}
+	if err != nil {
+		return Entry{}, details, fmt.Errorf("parsing benchmark output: %w", err)
+	}
+
+	// The go test output's cpu: line reflects the actual benchmark machine,
+	// so it wins over the auto-detected model.
+	if cfg.CPU == "" && details.Meta.CPU != "" {
+		cpu = details.Meta.CPU
+	}
+
+	var cpuRaw string
+	if cfg.NormalizeCPU {
+		if norm := hwinfo.NormalizeCPUModel(cpu); norm != cpu {
+			cpuRaw, cpu = cpu, norm
+		}
+		cpuModels = normalizeCPUModels(cpuModels)
+	}
+
+	entry := Entry{
+		Commit: commit,
+		Date:   commitTime.UnixMilli(),
+		Params: RunParams{
+			CPU:         cpu,
+			GOOS:        runtime.GOOS,
+			GOARCH:      runtime.GOARCH,
+			MicroArch:   microArch,
+			GoVersion:   goVersion,
+			CGO:         detectCGO(cfg.CGO),
+			DatasetHash: cfg.DatasetHash,
+		},
+		CPUModels:  cpuModels,
+		CPURaw:     cpuRaw,
+		Benchmarks: results,
+	}
+	if len(cfg.Tags) > 0 {
+		entry.Tags = cfg.Tags
+	}
+	return entry, details, nil
+}
+
+// normalizeCPUModels normalizes each of models, dropping the names that
+// became duplicates. It returns nil if fewer than two names remain, as for
+// a homogeneous host.
+func normalizeCPUModels(models []string) []string {
+	var out []string
+	for _, m := range models {
+		if m = hwinfo.NormalizeCPUModel(m); !slices.Contains(out, m) {
+			out = append(out, m)
+		}
+	}
+	if len(out) < 2 {
+		return nil
+	}
+	return out
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// detectCGO determines CGO enabled status.
+// Explicit value > CGO_ENABLED env var > default true.
+func detectCGO(val string) bool {
+	switch strings.TrimSpace(strings.ToLower(val)) {
+	case "true", "1":
+		return true
+	case "false", "0":
+		return false
+	}
+	return strings.TrimSpace(os.Getenv("CGO_ENABLED")) != "0"
+}
@@ -0,0 +1,222 @@
+package gobenchdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// StoreConfig configures Store.
+type StoreConfig struct {
+	// DataDir is the dashboard data directory. Required.
+	DataDir string
+	// Branch is the branch (or semver tag) the entries belong to.
+	// Required.
+	Branch string
+	// Entries are merged into the branch data, replacing stored entries
+	// with the same key.
+	Entries []Entry
+
+	// MaxItems and MaxAge trim the branch after merging: entries whose
+	// commit date is older than MaxAge are dropped first, then the oldest
+	// beyond MaxItems. Zero means no limit; entries with an unparseable
+	// date are never dropped for their age.
+	MaxItems int
+	MaxAge   time.Duration
+	// Concurrency bounds the data files written in parallel, i.e. a
+	// semver tag's file and the releases aggregate (values below 1 are
+	// treated as 1).
+	Concurrency int
+
+	// Options configure the data directory, e.g. WithGzip().
+	Options []StorageOption
+
+	// RepoURL, GoModule and BranchAliases are written to metadata.json for
+	// the frontend if any of them is set.
+	RepoURL       string
+	GoModule      string
+	BranchAliases map[string]string
+
+	// WorkloadChanges are commits whose benchmark workload changed on
+	// purpose. They are recorded before anything else, so that Check and
+	// every later regression check treat their changes as expected steps.
+	WorkloadChanges []string
+	// NameNormalize maps new benchmark names onto the names stored for the
+	// branch that differ only in surrounding or repeated whitespace
+	// ("trim") or also in case ("fold"). Empty or "none" keeps the names.
+	NameNormalize string
+	// Check, if set, is called with the stored entries of Branch and the
+	// entries about to be merged, after NameNormalize. An error stops Store
+	// before the entries are merged.
+	Check func(stored, entries []Entry) error
+	// StoreInterval leaves out entries dated within this interval of the
+	// previous comparable stored entry unless a value moved by more than
+	// StoreTolerance percent. Zero stores every entry.
+	StoreInterval  time.Duration
+	StoreTolerance float64
+
+	// WriteGrouped also writes grouped/<branch>.json, the branch's series
+	// keyed by benchmark name, which the dashboard loads instead of the
+	// entries when it exists.
+	WriteGrouped bool
+}
+
+// StoreDetails describes what StoreDetailed merged, for callers that
+// report on the run.
+type StoreDetails struct {
+	// Previous is the data of the branch before the merge.
+	Previous []Entry
+	// Stored are the entries merged into the branch and Skipped those
+	// StoreInterval left out.
+	Stored  []Entry
+	Skipped []Entry
+}
+
+// Store merges cfg.Entries into the branch data in cfg.DataDir and updates
+// the files around it: branches.json, metadata.json and manifest.json. The
+// frontend files are not part of the data and are left to the caller.
+func Store(cfg StoreConfig) error {
+	return StoreContext(context.Background(), cfg)
+}
+
+// StoreContext is Store bounded by ctx: it returns once ctx is done, even if
+// a filesystem call underneath hangs.
+func StoreContext(ctx context.Context, cfg StoreConfig) error {
+	_, err := StoreDetailed(ctx, cfg)
+	return err
+}
+
+// StoreDetailed is StoreContext that also returns which entries were
+// stored and the branch data they were merged into.
+func StoreDetailed(ctx context.Context, cfg StoreConfig) (StoreDetails, error) {
+	var details StoreDetails
+	if cfg.DataDir == "" {
+		return details, errors.New("data directory is required")
+	}
+	if cfg.Branch == "" {
+		return details, errors.New("branch is required")
+	}
+	switch cfg.NameNormalize {
+	case "", model.NameNormalizeNone, model.NameNormalizeTrim, model.NameNormalizeFold:
+	default:
+		return details, fmt.Errorf("unknown name normalization %q (want none, trim or fold)", cfg.NameNormalize)
+	}
+
+	store, err := storage.New(cfg.DataDir, cfg.Options...)
+	if err != nil {
+		return details, fmt.Errorf("initializing storage: %w", err)
+	}
+
+	for _, sha := range cfg.WorkloadChanges {
+		if sha = strings.TrimSpace(sha); sha == "" {
+			continue
+		}
+		a := storage.Annotation{SHA: sha, Kind: storage.AnnotationWorkloadChange}
+		if err := store.AddAnnotationContext(ctx, a); err != nil {
+			return details, fmt.Errorf("recording annotation: %w", err)
+		}
+	}
+
+	stored, err := store.ReadBranchData(cfg.Branch)
+	if err != nil {
+		return details, fmt.Errorf("reading branch data: %w", err)
+	}
+	details.Previous = stored
+
+	entries := cfg.Entries
+	if cfg.NameNormalize != "" && cfg.NameNormalize != model.NameNormalizeNone {
+		known := stored
+		for i := range entries {
+			model.ResolveNames(known, entries[i].Benchmarks, cfg.NameNormalize)
+			known = append(known, entries[i])
+		}
+	}
+	if cfg.Check != nil {
+		if err := cfg.Check(stored, entries); err != nil {
+			return details, err
+		}
+	}
+	if cfg.StoreInterval > 0 {
+		entries, details.Skipped = gateStoreInterval(stored, entries, cfg.StoreInterval, cfg.StoreTolerance)
+	}
+	details.Stored = entries
+
+	batches := map[string][]Entry{cfg.Branch: entries}
+	policy := storage.RetentionPolicy{MaxAge: cfg.MaxAge, MaxItems: cfg.MaxItems}
+	if err := store.AppendBranchesWithPolicyContext(ctx, batches, policy, cfg.Concurrency); err != nil {
+		return details, fmt.Errorf("appending entries: %w", err)
+	}
+
+	if cfg.WriteGrouped {
+		grouped := []string{cfg.Branch}
+		if storage.IsSemanticVersionTag(cfg.Branch) {
+			grouped = append(grouped, storage.ReleasesVirtualBranch)
+		}
+		for _, b := range grouped {
+			if err := store.WriteGrouped(b); err != nil {
+				return details, fmt.Errorf("writing grouped data: %w", err)
+			}
+		}
+	}
+
+	if cfg.RepoURL != "" || cfg.GoModule != "" || len(cfg.BranchAliases) > 0 {
+		if err := store.WriteMetadataContext(ctx, cfg.RepoURL, cfg.GoModule, cfg.BranchAliases); err != nil {
+			return details, fmt.Errorf("writing metadata: %w", err)
+		}
+	}
+
+	// Record what was written so downstream cache tooling can diff runs.
+	if err := store.WriteManifestContext(ctx); err != nil {
+		return details, fmt.Errorf("writing manifest: %w", err)
+	}
+	return details, nil
+}
+
+// gateStoreInterval splits entries into those to store and those dated less
+// than interval after the previous comparable entry in existing whose values
+// are all within tolerance percent of it.
+func gateStoreInterval(existing model.BranchData, entries []Entry, interval time.Duration, tolerance float64) (kept, skipped []Entry) {
+	for _, e := range entries {
+		prev := existing.PreviousComparable(e)
+		if prev != nil && time.Duration(e.Date-prev.Date)*time.Millisecond < interval && !valuesChanged(*prev, e, tolerance) {
+			skipped = append(skipped, e)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept, skipped
+}
+
+// valuesChanged reports whether cur holds a series prev lacks (or vice
+// versa) or any shared series moved by more than tolerance percent.
+func valuesChanged(prev, cur Entry, tolerance float64) bool {
+	if len(prev.Benchmarks) != len(cur.Benchmarks) {
+		return true
+	}
+	prevValues := make(map[model.SeriesKey]float64, len(prev.Benchmarks))
+	for _, r := range prev.Benchmarks {
+		prevValues[r.SeriesKey()] = r.Value
+	}
+	for _, r := range cur.Benchmarks {
+		old, ok := prevValues[r.SeriesKey()]
+		if !ok {
+			return true
+		}
+		if old == 0 {
+			if r.Value != 0 {
+				return true
+			}
+			continue
+		}
+		if math.Abs(r.Value-old)/math.Abs(old)*100 > tolerance {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// print-key subcommand
+// ---------------------------------------------------------------------------
+
+func runPrintKey(args []string) {
+	fs := flag.NewFlagSet("print-key", flag.ExitOnError)
+
+	var (
+		file      string
+		branch    string
+		dedupEnv  bool
+		dedupKeys string
+		ignore    string
+	)
+
+	fs.StringVar(&file, "file", "", "Path to an entry.json file (required)")
+	fs.StringVar(&branch, "branch", "main", "Git branch name the entry would be stored under")
+	fs.BoolVar(&dedupEnv, "dedup-env", false, "Include the captured environment in the key, as store -dedup-env does")
+	fs.StringVar(&dedupKeys, "dedup-keys", "", "Comma-separated key dimensions, as store -dedup-keys")
+	fs.StringVar(&ignore, "dedup-ignore", "", "Comma-separated key dimensions to leave out, as store -dedup-ignore")
+
+	fs.Parse(args)
+
+	if file == "" {
+		log.Fatal("Error: -file is required")
+	}
+
+	entry, err := loadEntry(file)
+	if err != nil {
+		log.Fatalf("Error loading entry: %v", err)
+	}
+
+	cfg, err := dedupKeyConfig(dedupKeys, ignore)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	cfg.Env = cfg.Env || dedupEnv
+
+	printKey(os.Stdout, entry, branch, cfg)
+}
+
+// printKey writes the deduplication key of entry under cfg and the data file
+// it would be stored in for branch.
+func printKey(w io.Writer, entry model.BenchmarkEntry, branch string, cfg model.KeyConfig) {
+	key := entry.KeyWith(cfg)
+	fmt.Fprintf(w, "key:  %s\n", key)
+	fmt.Fprintf(w, "file: %s\n", path.Join("data", storage.BranchFileName(branch)))
+}
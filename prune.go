@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// prune subcommand
+// ---------------------------------------------------------------------------
+
+func runPrune(args []string) {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+
+	var (
+		dataDir string
+		suite   string
+		keep    string
+		dryRun  bool
+	)
+
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.StringVar(&keep, "keep", "", "Comma-separated branches that still exist (reads one per line from stdin if empty, e.g. from 'git branch -r --format=%(refname:lstrip=3)')")
+	fs.BoolVar(&dryRun, "dry-run", false, "Only list the branches that would be removed")
+
+	fs.Parse(args)
+
+	var names []string
+	if keep != "" {
+		names = strings.Split(keep, ",")
+	} else {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			names = append(names, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			log.Fatalf("Error reading branches from stdin: %v", err)
+		}
+	}
+	var keepList []string
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n != "" {
+			keepList = append(keepList, n)
+		}
+	}
+	// An empty list is almost certainly a broken pipeline, not a request
+	// to drop every branch.
+	if len(keepList) == 0 {
+		log.Fatal("Error: no branches to keep; refusing to prune every branch")
+	}
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	removed, err := store.PruneBranches(keepList, dryRun)
+	if err != nil {
+		log.Fatalf("Error pruning branches: %v", err)
+	}
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+	for _, b := range removed {
+		fmt.Printf("%s branch %q\n", verb, b)
+	}
+	if len(removed) == 0 {
+		fmt.Println("No stale branches")
+		return
+	}
+	if dryRun {
+		return
+	}
+
+	if err := store.WriteManifest(); err != nil {
+		log.Fatalf("Error writing manifest: %v", err)
+	}
+}
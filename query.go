@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/stats"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// query subcommand
+// ---------------------------------------------------------------------------
+
+// querySeries is one series in the JSON output of the query subcommand.
+type querySeries struct {
+	Params model.RunParams      `json:"params"`
+	Tags   string               `json:"tags,omitempty"`
+	Series model.SeriesKey      `json:"series"`
+	Points []model.HistoryPoint `json:"points"`
+}
+
+func runQuery(args []string) {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+
+	var (
+		branch  string
+		dataDir string
+		suite   string
+		name    string
+		unit    string
+		smooth  int
+	)
+
+	fs.StringVar(&branch, "branch", "main", "Git branch name")
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.StringVar(&name, "name", "", "Benchmark name to query (required)")
+	fs.StringVar(&unit, "unit", "", "Only return series with this unit (e.g. ns/op)")
+	fs.IntVar(&smooth, "smooth", 0, "Add a moving average over this many points to every point (0 = off)")
+
+	fs.Parse(args)
+
+	if name == "" {
+		log.Fatal("Error: -name is required")
+	}
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+	data, err := store.ReadBranchData(branch)
+	if err != nil {
+		log.Fatalf("Error reading branch data: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(queryHistory(data, name, unit, smooth)); err != nil {
+		log.Fatalf("Error encoding result: %v", err)
+	}
+}
+
+// queryHistory returns the history of every series of data whose name (or
+// base name, to include secondary metrics) equals name, optionally limited
+// to unit. With smooth > 1 each point also carries the moving average over
+// smooth points.
+func queryHistory(data model.BranchData, name, unit string, smooth int) []querySeries {
+	result := []querySeries{}
+	for _, id := range data.SeriesIDs() {
+		r := model.BenchmarkResult{Name: id.Key.Name, Unit: id.Key.Unit}
+		if r.Name != name && r.BaseName() != name {
+			continue
+		}
+		if unit != "" && id.Key.Unit != unit {
+			continue
+		}
+
+		points := data.History(id.Config, id.Key)
+		if smooth > 1 {
+			values := make([]float64, len(points))
+			for i, p := range points {
+				values[i] = p.Value
+			}
+			for i, v := range stats.MovingAverage(values, smooth) {
+				points[i].Smoothed = &v
+			}
+		}
+		result = append(result, querySeries{Params: id.Config.Params, Tags: id.Config.Tags, Series: id.Key, Points: points})
+	}
+	return result
+}
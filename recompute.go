@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// recompute subcommand
+// ---------------------------------------------------------------------------
+
+func runRecompute(args []string) {
+	fs := flag.NewFlagSet("recompute", flag.ExitOnError)
+
+	var (
+		dataDir     string
+		suite       string
+		canonNames  bool
+		sortBenches bool
+		round       bool
+		precision   string
+		stableOnly  bool
+		dedupEnv    bool
+		dedupKeys   string
+		useBrotli   bool
+		throughput  bool
+	)
+
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.BoolVar(&canonNames, "canonicalize-names", false, "Sort key=value segments of sub-benchmark names")
+	fs.BoolVar(&sortBenches, "sort-benchmarks", false, "Sort each entry's benchmarks by package, name, unit and procs")
+	fs.BoolVar(&round, "round", false, "Round values to a per-unit number of decimal places")
+	fs.BoolVar(&throughput, "throughput-from-size", false, "Derive a bytes/sec metric for ns/op results with a size=N name segment")
+	fs.StringVar(&precision, "precision", "", "Comma-separated unit=places overrides for -round (implies -round)")
+	fs.BoolVar(&stableOnly, "releases-stable-only", false, "Exclude pre-release tags when rebuilding the releases aggregate")
+	fs.BoolVar(&dedupEnv, "dedup-env", false, "Keep entries that differ only in their captured environment as separate runs")
+	fs.StringVar(&dedupKeys, "dedup-keys", "", "Comma-separated dimensions identifying the same run (see store -dedup-keys)")
+	fs.BoolVar(&useBrotli, "brotli", false, "Also write brotli-compressed data/<branch>.json.br files")
+
+	fs.Parse(args)
+
+	var opts storage.RecomputeOptions
+	opts.CanonicalizeNames = canonNames
+	opts.SortBenchmarks = sortBenches
+	opts.ThroughputFromSize = throughput
+	if round || precision != "" {
+		p, err := parsePrecision(precision)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		opts.Precision = p
+	}
+
+	storeOpts := []storage.Option{storage.WithSuite(suite)}
+	if stableOnly {
+		storeOpts = append(storeOpts, storage.WithStableReleasesOnly())
+	}
+	if dedupEnv {
+		storeOpts = append(storeOpts, storage.WithEnvDedup())
+	}
+	if dedupKeys != "" {
+		cfg, err := model.ParseKeyConfig(dedupKeys)
+		if err != nil {
+			log.Fatalf("Error parsing -dedup-keys: %v", err)
+		}
+		storeOpts = append(storeOpts, storage.WithKeyConfig(cfg))
+	}
+	if useBrotli {
+		storeOpts = append(storeOpts, storage.WithBrotli())
+	}
+	store, err := storage.New(dataDir, storeOpts...)
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	if err := store.RecomputeAll(opts); err != nil {
+		log.Fatalf("Error recomputing data: %v", err)
+	}
+	if err := store.WriteManifest(); err != nil {
+		log.Fatalf("Error writing manifest: %v", err)
+	}
+	fmt.Println("Recomputed all branch data")
+}
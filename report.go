@@ -0,0 +1,287 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+	"github.com/royalcat/go-continuous-benchmarking/internal/regression"
+	"github.com/royalcat/go-continuous-benchmarking/internal/stats"
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// report subcommand
+// ---------------------------------------------------------------------------
+
+func reportUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: gobenchdata report <report> [flags]
+
+Reports:
+  trend   Fit a linear trend through the last N entries of a branch and
+          flag benchmarks that drift worse over the window.
+  flaky   List benchmarks whose values are noisy run to run over the
+          last N entries, after removing any linear trend.
+  bisect-range
+          For each regression of a benchmark between two benchmarked
+          commits, list the unbenchmarked commits in between (from the
+          ancestors recorded with parse -commit-parents).
+
+Run "gobenchdata report <report> -help" for flag details.
+`)
+	os.Exit(2)
+}
+
+func runReport(args []string) {
+	if len(args) < 1 {
+		reportUsage()
+	}
+
+	switch args[0] {
+	case "trend":
+		runReportTrend(args[1:])
+	case "flaky":
+		runReportFlaky(args[1:])
+	case "bisect-range":
+		runReportBisectRange(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown report: %s\n\n", args[0])
+		reportUsage()
+	}
+}
+
+func runReportTrend(args []string) {
+	fs := flag.NewFlagSet("report trend", flag.ExitOnError)
+
+	var (
+		branch    string
+		dataDir   string
+		suite     string
+		n         int
+		minPoints int
+		threshold float64
+	)
+
+	fs.StringVar(&branch, "branch", "main", "Git branch name")
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.IntVar(&n, "n", 50, "Number of most recent entries to fit (0 = all)")
+	fs.IntVar(&minPoints, "min-points", 3, "Skip benchmarks with fewer points than this in the window (at least 2)")
+	fs.Float64Var(&threshold, "threshold", 5, "Flag benchmarks whose fitted value grew by more than this percentage over the window")
+
+	fs.Parse(args)
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	data, err := store.ReadBranchData(branch)
+	if err != nil {
+		log.Fatalf("Error reading branch data: %v", err)
+	}
+	if n > 0 && len(data) > n {
+		data = data[len(data)-n:]
+	}
+
+	writeTrendReport(os.Stdout, branch, data, minPoints, threshold)
+}
+
+// writeTrendReport writes the trend table of data to w: the linear trend of
+// every series with at least minPoints points (and never fewer than the two
+// a line needs), marking those whose fitted value grew by more than
+// threshold percent over the window. It returns the number marked.
+func writeTrendReport(w io.Writer, branch string, data model.BranchData, minPoints int, threshold float64) int {
+	minPoints = max(minPoints, 2)
+	fmt.Fprintf(w, "Trend over %d entries of branch %q\n\n", len(data), branch)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BENCHMARK\tPARAMS\tPOINTS\tSLOPE/DAY\tR²\tCHANGE\t")
+
+	flagged, skipped := 0, 0
+	for _, id := range data.SeriesIDs() {
+		points := data.History(id.Config, id.Key)
+		if len(points) < minPoints {
+			skipped++
+			continue
+		}
+
+		slope, intercept, r2 := stats.LinearTrend(points)
+		span := (time.Duration(points[len(points)-1].Date-points[0].Date) * time.Millisecond).Hours() / 24
+		change := 0.0
+		if intercept != 0 {
+			change = slope * span / intercept * 100
+		}
+
+		mark := ""
+		if change > threshold {
+			mark = "WORSE"
+			flagged++
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%+.4f %s\t%.3f\t%+.2f%%\t%s\n",
+			id.Key.Name, paramsLabel(id.Config, id.Key.Procs), len(points), slope, id.Key.Unit, r2, change, mark)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d benchmark(s) trending worse than %.2f%%, %d skipped with fewer than %d points\n",
+		flagged, threshold, skipped, minPoints)
+	return flagged
+}
+
+func runReportFlaky(args []string) {
+	fs := flag.NewFlagSet("report flaky", flag.ExitOnError)
+
+	var (
+		branch    string
+		dataDir   string
+		suite     string
+		n         int
+		minPoints int
+		threshold float64
+	)
+
+	fs.StringVar(&branch, "branch", "main", "Git branch name")
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.IntVar(&n, "n", 30, "Number of most recent entries to consider (0 = all)")
+	fs.IntVar(&minPoints, "min-points", 5, "Skip benchmarks with fewer points than this in the window (at least 3)")
+	fs.Float64Var(&threshold, "threshold", 10, "Flag benchmarks whose detrended coefficient of variation exceeds this percentage")
+
+	fs.Parse(args)
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	data, err := store.ReadBranchData(branch)
+	if err != nil {
+		log.Fatalf("Error reading branch data: %v", err)
+	}
+	if n > 0 && len(data) > n {
+		data = data[len(data)-n:]
+	}
+
+	minPoints = max(minPoints, 3)
+	fmt.Printf("Run-to-run noise over %d entries of branch %q\n\n", len(data), branch)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BENCHMARK\tPARAMS\tPOINTS\tCV\tDETRENDED CV\t")
+
+	flagged, skipped := 0, 0
+	for _, id := range data.SeriesIDs() {
+		points := data.History(id.Config, id.Key)
+		if len(points) < minPoints {
+			skipped++
+			continue
+		}
+
+		values := make([]float64, len(points))
+		for i, p := range points {
+			values[i] = p.Value
+		}
+		cv := stats.CoefficientOfVariation(values) * 100
+		detrended := stats.DetrendedCV(points) * 100
+
+		mark := ""
+		if detrended > threshold {
+			mark = "FLAKY"
+			flagged++
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.2f%%\t%.2f%%\t%s\n",
+			id.Key.Name, paramsLabel(id.Config, id.Key.Procs), len(points), cv, detrended, mark)
+	}
+	tw.Flush()
+
+	fmt.Printf("\n%d flaky benchmark(s) above %.2f%% detrended CV, %d skipped with fewer than %d points\n",
+		flagged, threshold, skipped, minPoints)
+}
+
+func runReportBisectRange(args []string) {
+	fs := flag.NewFlagSet("report bisect-range", flag.ExitOnError)
+
+	var (
+		branch    string
+		dataDir   string
+		suite     string
+		benchmark string
+		threshold float64
+	)
+
+	fs.StringVar(&branch, "branch", "main", "Git branch name")
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.StringVar(&benchmark, "benchmark", "", "Benchmark name (required)")
+	fs.Float64Var(&threshold, "threshold", 10, "Percentage change for the worse between consecutive benchmarked commits that counts as a regression (a decrease for higher-is-better units like MB/s)")
+
+	fs.Parse(args)
+
+	if benchmark == "" {
+		log.Fatal("Error: -benchmark is required")
+	}
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	data, err := store.ReadBranchData(branch)
+	if err != nil {
+		log.Fatalf("Error reading branch data: %v", err)
+	}
+
+	policy := regression.PercentPolicy{Threshold: threshold}
+	found := 0
+	for _, id := range data.SeriesIDs() {
+		if id.Key.Name != benchmark {
+			continue
+		}
+		points := data.History(id.Config, id.Key)
+		for i := 1; i < len(points); i++ {
+			prev, cur := points[i-1], points[i]
+			regressed, msg := policy.Check(prev, cur, nil, model.UnitDirection(cur.Unit))
+			if !regressed {
+				continue
+			}
+			found++
+
+			fmt.Printf("%s (%s): %s..%s %s\n", benchmark, paramsLabel(id.Config, id.Key.Procs), prev.SHA, cur.SHA, msg)
+			commits, err := store.CommitsBetween(branch, prev.SHA, cur.SHA)
+			switch {
+			case err != nil:
+				fmt.Printf("  unknown: %v\n", err)
+			case len(commits) == 0:
+				fmt.Println("  no commits in between: the regression is in " + cur.SHA)
+			default:
+				for _, sha := range commits {
+					fmt.Printf("  %s\n", sha)
+				}
+			}
+		}
+	}
+
+	if found == 0 {
+		fmt.Printf("No regressions of %s above %.2f%% on branch %q\n", benchmark, threshold, branch)
+	}
+}
+
+// paramsLabel renders a run configuration compactly for report output.
+func paramsLabel(config model.EntryKeyValue, procs int) string {
+	p := config.Params
+	cgo := "cgo0"
+	if p.CGO {
+		cgo = "cgo1"
+	}
+	label := fmt.Sprintf("%s/%s %s %s procs=%d", p.GOOS, p.GOARCH, p.GoVersion, cgo, procs)
+	if config.Tags != "" {
+		label += " " + config.Tags
+	}
+	return label
+}
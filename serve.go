@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// serve subcommand
+// ---------------------------------------------------------------------------
+
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+
+	var (
+		dataDir string
+		addr    string
+	)
+
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data and frontend files")
+	fs.StringVar(&addr, "addr", "localhost:8080", "Address to listen on")
+
+	fs.Parse(args)
+
+	handler, err := dataHandler(dataDir)
+	if err != nil {
+		log.Fatalf("Error opening data directory: %v", err)
+	}
+
+	fmt.Printf("Serving %s on http://%s/\n", dataDir, addr)
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		log.Fatalf("Error serving: %v", err)
+	}
+}
+
+// precompressed lists the precompressed variants dataHandler looks for next
+// to a requested file, in order of preference.
+var precompressed = []struct {
+	encoding, ext string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// dataHandler serves the files under dir. Responses go through
+// http.ServeContent, so clients can fetch a byte range (e.g. the tail of a
+// large releases.json) with a Range header and get a 206 Partial Content
+// reply. Paths cannot escape dir.
+//
+// If the client accepts it and a precompressed "<file>.br" or "<file>.gz"
+// exists, that variant is served with the matching Content-Encoding,
+// preferring brotli over gzip over identity.
+func dataHandler(dir string) (http.Handler, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if name == "" {
+			name = "index.html"
+		}
+
+		w.Header().Set("Vary", "Accept-Encoding")
+		accept := r.Header.Get("Accept-Encoding")
+		for _, v := range precompressed {
+			if !acceptsEncoding(accept, v.encoding) {
+				continue
+			}
+			f, err := root.Open(name + v.ext)
+			if err != nil {
+				continue
+			}
+			defer f.Close()
+			info, err := f.Stat()
+			if err != nil || info.IsDir() {
+				continue
+			}
+			w.Header().Set("Content-Encoding", v.encoding)
+			serveFile(w, r, name, info, f)
+			return
+		}
+
+		f, err := root.Open(name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				http.NotFound(w, r)
+				return
+			}
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		serveFile(w, r, name, info, f)
+	}), nil
+}
+
+// serveFile serves content with range support. The Content-Type is derived
+// from name rather than from the (possibly compressed) file itself.
+func serveFile(w http.ResponseWriter, r *http.Request, name string, info fs.FileInfo, content io.ReadSeeker) {
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.Header().Set("Accept-Ranges", "bytes")
+	http.ServeContent(w, r, path.Base(name), info.ModTime(), content)
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header value allows
+// encoding, either by name or via "*", and not with q=0.
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.TrimSpace(coding)
+		if !strings.EqualFold(coding, encoding) && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
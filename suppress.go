@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// suppress subcommand
+// ---------------------------------------------------------------------------
+
+func runSuppress(args []string) {
+	fs := flag.NewFlagSet("suppress", flag.ExitOnError)
+
+	var (
+		dataDir    string
+		suite      string
+		from       string
+		to         string
+		benchmarks string
+		reason     string
+	)
+
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.StringVar(&from, "from", "", "Start of the window in RFC 3339 (required)")
+	fs.StringVar(&to, "to", "", "End of the window in RFC 3339 (required)")
+	fs.StringVar(&benchmarks, "benchmarks", "", "Comma-separated benchmark name globs to mute (empty = all benchmarks)")
+	fs.StringVar(&reason, "reason", "", "Why alerts are muted, shown next to suppressed regressions")
+
+	fs.Parse(args)
+
+	if from == "" || to == "" {
+		log.Fatal("Error: -from and -to are required")
+	}
+	sp := storage.Suppression{Reason: reason}
+	var err error
+	if sp.From, err = time.Parse(time.RFC3339, from); err != nil {
+		log.Fatalf("Error parsing -from %q: %v", from, err)
+	}
+	if sp.To, err = time.Parse(time.RFC3339, to); err != nil {
+		log.Fatalf("Error parsing -to %q: %v", to, err)
+	}
+	for _, b := range strings.Split(benchmarks, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			sp.Benchmarks = append(sp.Benchmarks, b)
+		}
+	}
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+	if err := store.AddSuppression(sp); err != nil {
+		log.Fatalf("Error adding suppression: %v", err)
+	}
+	fmt.Printf("Suppressing regression alerts from %s to %s\n", sp.From.Format(time.RFC3339), sp.To.Format(time.RFC3339))
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/model"
+)
+
+// transformEntry pipes entry as JSON through the shell command cmd and
+// returns the entry the command printed on stdout. The command is killed
+// after timeout. Its output must decode to a BenchmarkEntry that still has
+// a commit SHA and at least one benchmark, so a broken script cannot
+// silently store an empty entry.
+func transformEntry(cmd string, entry model.BenchmarkEntry, timeout time.Duration) (model.BenchmarkEntry, error) {
+	input, err := json.Marshal(entry)
+	if err != nil {
+		return model.BenchmarkEntry{}, fmt.Errorf("encoding entry: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, "sh", "-c", cmd)
+	// Do not wait for children of the shell that keep stdout open.
+	c.WaitDelay = time.Second
+	c.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return model.BenchmarkEntry{}, fmt.Errorf("transform command timed out after %v", timeout)
+		}
+		return model.BenchmarkEntry{}, fmt.Errorf("transform command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	out, err := decodeEntry(&stdout)
+	if err != nil {
+		return model.BenchmarkEntry{}, fmt.Errorf("decoding transform output: %w", err)
+	}
+	if out.Commit.SHA == "" || len(out.Benchmarks) == 0 {
+		return model.BenchmarkEntry{}, fmt.Errorf("transform output has no commit SHA or no benchmarks")
+	}
+	return out, nil
+}
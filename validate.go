@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/royalcat/go-continuous-benchmarking/internal/storage"
+)
+
+// ---------------------------------------------------------------------------
+// validate-data subcommand
+// ---------------------------------------------------------------------------
+
+func runValidateData(args []string) {
+	fs := flag.NewFlagSet("validate-data", flag.ExitOnError)
+
+	var (
+		dataDir     string
+		suite       string
+		fixOrdering bool
+	)
+
+	fs.StringVar(&dataDir, "data-dir", "benchmarks", "Directory containing benchmark data")
+	fs.StringVar(&suite, "suite", "", "Benchmark suite to use (empty = the default suite)")
+	fs.BoolVar(&fixOrdering, "fix-ordering", false, "Re-sort and rewrite branch data files whose entries are not in commit date order")
+
+	fs.Parse(args)
+
+	store, err := storage.New(dataDir, storage.WithSuite(suite))
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	failed := false
+
+	if err := store.VerifyReleases(); err != nil {
+		fmt.Fprintf(os.Stderr, "Releases check failed:\n%v\n", err)
+		failed = true
+	} else {
+		fmt.Println("Releases check passed")
+	}
+
+	if !checkOrdering(store, fixOrdering) {
+		failed = true
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// checkOrdering runs storage.CheckOrdering on every registered branch and
+// prints the violations. With fix, out-of-order branches are re-sorted and
+// no longer count as failures. It reports whether the check passed.
+func checkOrdering(store *storage.Storage, fix bool) bool {
+	branches, err := store.ReadBranches()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ordering check failed: %v\n", err)
+		return false
+	}
+
+	passed := true
+	for _, branch := range branches {
+		violations, err := store.CheckOrdering(branch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ordering check failed for %q: %v\n", branch, err)
+			passed = false
+			continue
+		}
+		if len(violations) == 0 {
+			continue
+		}
+		for _, v := range violations {
+			fmt.Fprintf(os.Stderr, "Ordering check: branch %q: %s\n", branch, v)
+		}
+		if !fix {
+			passed = false
+			continue
+		}
+		if _, err := store.FixOrdering(branch); err != nil {
+			fmt.Fprintf(os.Stderr, "Fixing ordering of %q failed: %v\n", branch, err)
+			passed = false
+			continue
+		}
+		fmt.Printf("Re-sorted branch %q (%d ordering violation(s))\n", branch, len(violations))
+	}
+	if passed {
+		fmt.Println("Ordering check passed")
+	}
+	return passed
+}